@@ -0,0 +1,114 @@
+// Package cloze parses and renders cloze deletions written as {{c1::answer}}
+// or {{c1::answer::hint}}, the syntax used by Anki
+package cloze
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"catv/internal/markdown"
+)
+
+// Blank replaces a hidden deletion that has no hint
+const Blank = "[...]"
+
+// deletion matches {{cN::text}} and {{cN::text::hint}}
+var deletion = regexp.MustCompile(`\{\{c(\d+)::(.*?)(?:::(.*?))?\}\}`)
+
+// Has reports whether text contains at least one cloze deletion
+func Has(text string) bool {
+	return deletion.MatchString(text)
+}
+
+// Indices returns the distinct deletion numbers in text in ascending order
+// Each number becomes its own flashcard
+func Indices(text string) []int {
+	seen := make(map[int]bool)
+	var indices []int
+	for _, m := range deletion.FindAllStringSubmatch(text, -1) {
+		n, err := strconv.Atoi(m[1])
+		if err != nil || n <= 0 || seen[n] {
+			continue
+		}
+		seen[n] = true
+		indices = append(indices, n)
+	}
+	sort.Ints(indices)
+	return indices
+}
+
+// Question renders text with deletion n hidden and every other deletion shown
+func Question(text string, n int) string {
+	return replace(text, func(index int, answer, hint string) string {
+		if index != n {
+			return answer
+		}
+		if hint != "" {
+			return "[" + hint + "]"
+		}
+		return Blank
+	})
+}
+
+// Answer renders text with deletion n filled in and emphasized
+func Answer(text string, n int) string {
+	return replace(text, func(index int, answer, hint string) string {
+		if index != n {
+			return answer
+		}
+		return "**" + answer + "**"
+	})
+}
+
+// Deleted returns the hidden text of deletion n, joining repeated deletions
+// with different text by ", "
+func Deleted(text string, n int) string {
+	var parts []string
+	seen := make(map[string]bool)
+	for _, m := range deletion.FindAllStringSubmatch(text, -1) {
+		if index, _ := strconv.Atoi(m[1]); index != n || seen[m[2]] {
+			continue
+		}
+		seen[m[2]] = true
+		parts = append(parts, m[2])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Reveal renders text with every deletion filled in and no markers, e.g. to
+// send notes with cloze markers to a model
+func Reveal(text string) string {
+	return replace(text, func(index int, answer, hint string) string {
+		return answer
+	})
+}
+
+// Extract returns the paragraphs of a markdown note that contain cloze deletions,
+// without a heading line directly above them
+// Deletions inside code blocks are not recognized, since code often contains {{ }}
+func Extract(content string) []string {
+	var found []string
+	for _, block := range markdown.Blocks(content) {
+		trimmed := strings.TrimSpace(block)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") || !Has(block) {
+			continue
+		}
+		lines := strings.Split(trimmed, "\n")
+		for len(lines) > 1 && strings.HasPrefix(lines[0], "#") {
+			lines = lines[1:]
+		}
+		found = append(found, strings.Join(lines, "\n"))
+	}
+	return found
+}
+
+// replace rewrites every deletion in text with the result of fn
+func replace(text string, fn func(index int, answer, hint string) string) string {
+	return deletion.ReplaceAllStringFunc(text, func(match string) string {
+		m := deletion.FindStringSubmatch(match)
+		n, _ := strconv.Atoi(m[1])
+		return fn(n, m[2], m[3])
+	})
+}
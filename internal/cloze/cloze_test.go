@@ -0,0 +1,61 @@
+package cloze
+
+import (
+	"reflect"
+	"testing"
+)
+
+const sample = "Go was designed at {{c1::Google}} and released in {{c2::2009::year}}; {{c1::Google}} still maintains it."
+
+func TestIndices(t *testing.T) {
+	if got := Indices(sample); !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Errorf("Indices() = %v, expected [1 2]", got)
+	}
+	if got := Indices("No deletions {{here}}"); got != nil {
+		t.Errorf("Indices() = %v, expected none", got)
+	}
+	if Has("{{c1::}") || !Has("{{c3::x}}") {
+		t.Error("Has() did not recognize deletions correctly")
+	}
+}
+
+func TestQuestionAndAnswer(t *testing.T) {
+	tests := []struct {
+		n        int
+		question string
+		answer   string
+	}{
+		{1,
+			"Go was designed at [...] and released in 2009; [...] still maintains it.",
+			"Go was designed at **Google** and released in 2009; **Google** still maintains it."},
+		{2,
+			"Go was designed at Google and released in [year]; Google still maintains it.",
+			"Go was designed at Google and released in **2009**; Google still maintains it."},
+	}
+	for _, tt := range tests {
+		if got := Question(sample, tt.n); got != tt.question {
+			t.Errorf("Question(%d) = %q, expected %q", tt.n, got, tt.question)
+		}
+		if got := Answer(sample, tt.n); got != tt.answer {
+			t.Errorf("Answer(%d) = %q, expected %q", tt.n, got, tt.answer)
+		}
+	}
+	if got := Deleted(sample, 1); got != "Google" {
+		t.Errorf("Deleted(1) = %q, expected %q", got, "Google")
+	}
+	if got := Deleted("{{c1::a}} and {{c1::b}}", 1); got != "a, b" {
+		t.Errorf("Deleted() = %q, expected %q", got, "a, b")
+	}
+	if got := Reveal(sample); got != "Go was designed at Google and released in 2009; Google still maintains it." {
+		t.Errorf("Reveal() = %q", got)
+	}
+}
+
+func TestExtract(t *testing.T) {
+	note := "# Go\nGo has {{c1::goroutines}}.\n\nPlain paragraph.\n\n```\ntemplate {{c1::not a cloze}}\n```\n\nChannels are {{c1::typed}}\nand {{c2::synchronized}}."
+	got := Extract(note)
+	expected := []string{"Go has {{c1::goroutines}}.", "Channels are {{c1::typed}}\nand {{c2::synchronized}}."}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Extract() = %q, expected %q", got, expected)
+	}
+}
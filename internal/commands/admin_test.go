@@ -38,4 +38,112 @@ func TestReviewCmd_Definition(t *testing.T) {
 	if ReviewCmd.Run == nil {
 		t.Error("ReviewCmd.Run should not be nil")
 	}
+
+	if ReviewCmd.Flags().Lookup("type") == nil {
+		t.Error("ReviewCmd should have a --type flag")
+	}
+
+	if ReviewCmd.Flags().Lookup("ai-grade") == nil {
+		t.Error("ReviewCmd should have an --ai-grade flag")
+	}
+
+	if ReviewCmd.Flags().Lookup("deck") == nil || ReviewCmd.Flags().Lookup("tag") == nil {
+		t.Error("ReviewCmd should have --deck and --tag flags")
+	}
+
+	for _, name := range []string{"file", "all", "limit", "new-limit", "shuffle"} {
+		if ReviewCmd.Flags().Lookup(name) == nil {
+			t.Errorf("ReviewCmd should have a --%s flag", name)
+		}
+	}
+}
+
+func TestDBMigrateCmd_Definition(t *testing.T) {
+	if DBMigrateCmd.Use != "migrate" {
+		t.Errorf("DBMigrateCmd.Use = %q, want %q", DBMigrateCmd.Use, "migrate")
+	}
+
+	if DBMigrateCmd.Parent() != DBCmd {
+		t.Error("DBMigrateCmd should be a subcommand of DBCmd")
+	}
+
+	if DBMigrateCmd.Flags().Lookup("status") == nil {
+		t.Error("DBMigrateCmd should have a --status flag")
+	}
+
+	if DBCmd.PersistentPreRun == nil {
+		t.Error("DBCmd should open the database without migrating it")
+	}
+}
+
+func TestStatsCmd_Definition(t *testing.T) {
+	if StatsCmd.Use != "stats" {
+		t.Errorf("StatsCmd.Use = %q, want %q", StatsCmd.Use, "stats")
+	}
+
+	for _, flag := range []string{"json", "days"} {
+		if StatsCmd.Flags().Lookup(flag) == nil {
+			t.Errorf("StatsCmd should have a --%s flag", flag)
+		}
+	}
+}
+
+func TestImportCmd_Definition(t *testing.T) {
+	if ImportCmd.Use != "import <file>" {
+		t.Errorf("ImportCmd.Use = %q, want %q", ImportCmd.Use, "import <file>")
+	}
+
+	for _, flag := range []string{"from", "question-col", "answer-col", "tags-col", "header"} {
+		if ImportCmd.Flags().Lookup(flag) == nil {
+			t.Errorf("ImportCmd should have a --%s flag", flag)
+		}
+	}
+}
+
+func TestExportCmd_Definition(t *testing.T) {
+	if ExportCmd.Use != "export" {
+		t.Errorf("ExportCmd.Use = %q, want %q", ExportCmd.Use, "export")
+	}
+
+	for _, flag := range []string{"output", "format", "file", "tag", "due"} {
+		if ExportCmd.Flags().Lookup(flag) == nil {
+			t.Errorf("ExportCmd should have a --%s flag", flag)
+		}
+	}
+}
+
+func TestConfigCmd_Definition(t *testing.T) {
+	if ConfigCmd.Use != "config" {
+		t.Errorf("ConfigCmd.Use = %q, want %q", ConfigCmd.Use, "config")
+	}
+
+	names := map[string]bool{}
+	for _, sub := range ConfigCmd.Commands() {
+		names[sub.Name()] = true
+	}
+	for _, name := range []string{"get", "set", "list", "path", "validate"} {
+		if !names[name] {
+			t.Errorf("ConfigCmd should have a %s subcommand", name)
+		}
+	}
+
+	if ConfigSetCmd.Flags().Lookup("project") == nil {
+		t.Error("ConfigSetCmd should have a --project flag")
+	}
+}
+
+func TestModelsCmd_Definition(t *testing.T) {
+	if ModelsCmd.Use != "models" {
+		t.Errorf("ModelsCmd.Use = %q, want %q", ModelsCmd.Use, "models")
+	}
+
+	names := map[string]bool{}
+	for _, sub := range ModelsCmd.Commands() {
+		names[sub.Name()] = true
+	}
+	for _, name := range []string{"list", "pull"} {
+		if !names[name] {
+			t.Errorf("ModelsCmd should have a %s subcommand", name)
+		}
+	}
 }
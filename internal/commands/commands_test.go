@@ -3,11 +3,7 @@ package commands
 import (
 	"os"
 	"path/filepath"
-	"strings"
 	"testing"
-
-	"github.com/charmbracelet/bubbles/spinner"
-	tea "github.com/charmbracelet/bubbletea"
 )
 
 func TestGetMarkdownFiles(t *testing.T) {
@@ -109,76 +105,6 @@ func TestGetMarkdownFiles(t *testing.T) {
 	}
 }
 
-func TestSpinnerModelInit(t *testing.T) {
-	sm := spinnerModel{
-		spinner: spinner.New(),
-		done:    false,
-		msg:     "test",
-	}
-	cmd := sm.Init()
-	if cmd == nil {
-		t.Error("Init() should return a command")
-	}
-}
-
-func TestSpinnerModelUpdate(t *testing.T) {
-	sm := spinnerModel{
-		spinner: spinner.New(),
-		done:    false,
-		msg:     "test",
-	}
-
-	// Test with tick message when not done
-	tickCmd := sm.spinner.Tick
-	sm.spinner, _ = sm.spinner.Update(tickCmd())
-	tickMsg := spinner.TickMsg{}
-	newModel, cmd := sm.Update(tickMsg)
-	if newModel == nil {
-		t.Error("Update() should return a model")
-	}
-	if cmd == nil && !sm.done {
-		t.Error("Update() should return a command when not done")
-	}
-
-	// Test with tick message when done
-	sm.done = true
-	newModel, _ = sm.Update(tickMsg)
-	if newModel == nil {
-		t.Error("Update() should return a model")
-	}
-
-	// Test with non-tick message
-	sm.done = false
-	newModel, _ = sm.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}})
-	if newModel == nil {
-		t.Error("Update() should return a model")
-	}
-}
-
-func TestSpinnerModelView(t *testing.T) {
-	sm := spinnerModel{
-		spinner: spinner.New(),
-		done:    false,
-		msg:     "test message",
-	}
-
-	// Test view when not done
-	view := sm.View()
-	if view == "" {
-		t.Error("View() should return a non-empty string")
-	}
-	if !strings.Contains(view, "Generating flashcards") {
-		t.Errorf("View() should contain 'Generating flashcards', got: %s", view)
-	}
-
-	// Test view when done
-	sm.done = true
-	view = sm.View()
-	if view != "test message" {
-		t.Errorf("View() should return the message when done, got: %s", view)
-	}
-}
-
 func TestExecute(t *testing.T) {
 	// Test Execute with invalid command (should not panic)
 	// We can't easily test os.Exit, but we can test that the function exists
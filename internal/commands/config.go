@@ -0,0 +1,169 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"catv/internal/config"
+	"catv/internal/scheduler"
+	"catv/internal/security"
+	"catv/internal/tui"
+
+	"github.com/spf13/cobra"
+)
+
+var ConfigCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Show and change settings",
+	Long: `Show and change the settings stored in configuration files.
+
+Settings are read, from lowest to highest precedence, from the built-in
+defaults, the user file (~/.config/catv/config.toml, or under
+$XDG_CONFIG_HOME), the closest .catv.toml in the notes folder or one of its
+parents, environment variables and finally command line flags. Files use
+TOML sections, for example:
+
+  [ollama]
+  model = "qwen2.5:7b"
+
+  [review]
+  timer = "45s"
+  learning_steps = ["1m", "10m"]
+
+Run catv config list to see every setting, its value and where it came from.`,
+	// Settings do not need the database
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {},
+}
+
+var ConfigGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print the value of a setting",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		value, err := loadConfig(cmd).Get(args[0])
+		if err != nil {
+			tui.PrintError("Invalid setting:", err)
+			os.Exit(1)
+		}
+		fmt.Println(value)
+	},
+}
+
+var ConfigSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Save a setting in the user or project configuration file",
+	Long: `Save a setting in the user configuration file, or with --project in the
+.catv.toml of the notes folder. The file is rewritten with its other settings
+kept, but comments in it are not kept.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		path := config.UserConfigPath()
+		if project, _ := cmd.Flags().GetBool("project"); project {
+			path = projectConfigPath(cmd)
+		}
+		if path == "" {
+			tui.PrintError("Could not find the configuration directory", nil)
+			os.Exit(1)
+		}
+		if err := config.SetInFile(path, args[0], args[1]); err != nil {
+			tui.PrintError("Could not save setting:", err)
+			os.Exit(1)
+		}
+		value := args[1]
+		if config.IsSecret(args[0]) {
+			value = "(hidden)"
+		}
+		tui.PrintSuccess(fmt.Sprintf("Set %s = %s in %s", args[0], value, path))
+	},
+}
+
+var ConfigListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List every setting with its value and source",
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := loadConfig(cmd)
+		fmt.Printf("%-24s %-32s %s\n", "Key", "Value", "Source")
+		for _, key := range config.Keys() {
+			value, _ := cfg.Get(key)
+			if value != "" && config.IsSecret(key) {
+				value = "(hidden)"
+			}
+			fmt.Printf("%-24s %-32s %s\n", key, value, cfg.Source(key))
+		}
+	},
+}
+
+var ConfigPathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "Print the configuration files that apply",
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Printf("user:    %s\n", config.UserConfigPath())
+		project := configPathFlag(cmd)
+		if found := config.FindProjectConfig(project); found != "" {
+			fmt.Printf("project: %s\n", found)
+		} else {
+			fmt.Printf("project: none (catv config set --project creates %s)\n", projectConfigPath(cmd))
+		}
+	},
+}
+
+var ConfigValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the configuration files and environment for mistakes",
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := loadConfig(cmd)
+		if err := validateConfig(cfg); err != nil {
+			tui.PrintError("Invalid configuration:", err)
+			os.Exit(1)
+		}
+		tui.PrintSuccess("Configuration is valid")
+	},
+}
+
+// validateConfig checks the configuration, including the values only the
+// commands can check
+func validateConfig(cfg *config.Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	if _, err := scheduler.New(cfg.Scheduler); err != nil {
+		return err
+	}
+	if _, err := newProvider(cfg); err != nil {
+		return err
+	}
+	if err := security.ValidateURL(cfg.OllamaURL); err != nil {
+		return fmt.Errorf("invalid model server URL: %w", err)
+	}
+	return nil
+}
+
+// configPathFlag returns the notes path given with --path
+func configPathFlag(cmd *cobra.Command) string {
+	path, _ := cmd.Flags().GetString("path")
+	return path
+}
+
+// projectConfigPath returns the project file that applies to --path, or where
+// a new one would be created
+func projectConfigPath(cmd *cobra.Command) string {
+	path := configPathFlag(cmd)
+	if found := config.FindProjectConfig(path); found != "" {
+		return found
+	}
+	dir, err := filepath.Abs(path)
+	if err != nil {
+		return ""
+	}
+	if info, err := os.Stat(dir); err == nil && !info.IsDir() {
+		dir = filepath.Dir(dir)
+	}
+	return filepath.Join(dir, config.ProjectConfigName)
+}
+
+func init() {
+	ConfigCmd.PersistentFlags().StringP("path", "p", ".", "Notes file or folder whose .catv.toml applies")
+	ConfigSetCmd.Flags().Bool("project", false, "Save the setting in the project's .catv.toml instead of the user file")
+	ConfigCmd.AddCommand(ConfigGetCmd, ConfigSetCmd, ConfigListCmd, ConfigPathCmd, ConfigValidateCmd)
+}
@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"catv/internal/store"
+	"catv/internal/tui"
+
+	"github.com/spf13/cobra"
+)
+
+var migrateStatus bool
+
+var DBCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Flashcards database maintenance",
+	// Open the database without migrating so pending migrations can be inspected
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		openDatabase(cmd, store.OpenStore)
+	},
+}
+
+var DBMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Apply pending database schema migrations",
+	Long: `Apply pending database schema migrations.
+
+Migrations are also applied automatically whenever catv opens the database;
+use --status to list which migrations have been applied without changing anything.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if migrateStatus {
+			statuses, err := Store.MigrationStatus()
+			if err != nil {
+				tui.PrintError("Could not read migration status:", err)
+				os.Exit(1)
+			}
+			printMigrationStatus(statuses)
+			return
+		}
+
+		applied, err := Store.Migrate()
+		for _, m := range applied {
+			tui.PrintSuccess(fmt.Sprintf("Applied migration %d: %s", m.Version, m.Name))
+		}
+		if err != nil {
+			tui.PrintError("Migration failed:", err)
+			os.Exit(1)
+		}
+		if len(applied) == 0 {
+			tui.PrintInfo(fmt.Sprintf("Database schema is up to date (version %d)", store.LatestSchemaVersion()))
+		}
+	},
+}
+
+// printMigrationStatus prints one line per known migration with when it was applied
+func printMigrationStatus(statuses []store.MigrationStatus) {
+	current, pending := 0, 0
+	fmt.Printf("%-8s %-36s %s\n", "Version", "Name", "Applied")
+	for _, m := range statuses {
+		applied := "pending"
+		if m.Applied() {
+			applied = m.AppliedAt.Local().Format("2006-01-02 15:04:05")
+			current = m.Version
+		} else {
+			pending++
+		}
+		fmt.Printf("%-8d %-36s %s\n", m.Version, m.Name, applied)
+	}
+
+	if pending == 0 {
+		tui.PrintInfo(fmt.Sprintf("Schema version %d, up to date", current))
+	} else {
+		tui.PrintInfo(fmt.Sprintf("Schema version %d, %d pending migration(s); run 'catv db migrate' to apply", current, pending))
+	}
+}
+
+func init() {
+	DBMigrateCmd.Flags().BoolVar(&migrateStatus, "status", false, "Show applied and pending migrations without applying them")
+	DBCmd.AddCommand(DBMigrateCmd)
+}
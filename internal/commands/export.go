@@ -0,0 +1,198 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"catv/internal/exporter"
+	"catv/internal/importer"
+	"catv/internal/store"
+	"catv/internal/tui"
+
+	"github.com/spf13/cobra"
+)
+
+// Export formats accepted by --format
+const (
+	exportAnki     = "apkg"
+	exportCSV      = "csv"
+	exportTSV      = "tsv"
+	exportJSONL    = "jsonl"
+	exportMarkdown = "md"
+)
+
+// exportFormats maps format names and output extensions to formats
+var exportFormats = map[string]string{
+	"apkg": exportAnki, "anki": exportAnki,
+	"csv": exportCSV,
+	"tsv": exportTSV, "txt": exportTSV,
+	"jsonl": exportJSONL, "json": exportJSONL,
+	"md": exportMarkdown, "markdown": exportMarkdown,
+}
+
+var ExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export flashcards to Anki, CSV/TSV, JSON lines or Markdown",
+	Long: `Export flashcards to share them or use them in other tools.
+
+Formats:
+  apkg      Anki package, one deck per source file, keeping scheduling and review history
+  csv, tsv  One row per flashcard: question, answer, tags, file and heading
+  jsonl     One JSON object per flashcard with its scheduling and review history
+  md        A Markdown document of questions and answers grouped by source file
+
+The format is taken from the --output extension unless --format is given.
+Without --output the export is written to standard output. Select a subset
+with --file (a source file or directory, or "anki:<deck>"), --tag and --due.`,
+	Example: `  catv export -o deck.apkg
+  catv export --format md --file notes/go.md
+  catv export -o due.csv --due --tag networking`,
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		format, _ := cmd.Flags().GetString("format")
+		format, err := exportFormat(output, format)
+		if err != nil {
+			tui.PrintError("Cannot export:", err)
+			os.Exit(1)
+		}
+		if format == exportAnki && output == "" {
+			tui.PrintError("Cannot export:", errors.New("write Anki packages to a file with --output"))
+			os.Exit(1)
+		}
+
+		var filter exporter.Filter
+		files, _ := cmd.Flags().GetStringSlice("file")
+		if filter.Files, err = exportFiles(files); err != nil {
+			tui.PrintError("Invalid file:", err)
+			os.Exit(1)
+		}
+		filter.Tags, _ = cmd.Flags().GetStringSlice("tag")
+		filter.DueOnly, _ = cmd.Flags().GetBool("due")
+		deck, err := loadExportDeck(filter, time.Now())
+		if err != nil {
+			tui.PrintError("Failed to load flashcards:", err)
+			os.Exit(1)
+		}
+		if len(deck.Flashcards) == 0 {
+			tui.PrintInfo("No flashcards match the selection.")
+			return
+		}
+
+		if output == "" {
+			if err := writeExport(os.Stdout, deck, format); err != nil {
+				tui.PrintError("Export failed:", err)
+				os.Exit(1)
+			}
+			return
+		}
+		if err := writeExportFile(output, deck, format); err != nil {
+			tui.PrintError("Export failed:", err)
+			os.Exit(1)
+		}
+		tui.PrintSuccess(fmt.Sprintf("Exported %d flashcards to %s", len(deck.Flashcards), output))
+	},
+}
+
+// exportFormat returns the format named by --format, or the one matching the
+// output file's extension when the flag is not set
+func exportFormat(output, format string) (string, error) {
+	if format == "" {
+		ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(output)), ".")
+		if f, ok := exportFormats[ext]; ok {
+			return f, nil
+		}
+		return "", errors.New("choose a format with --format apkg, csv, tsv, jsonl or md")
+	}
+	if f, ok := exportFormats[strings.ToLower(format)]; ok {
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown format %q; use apkg, csv, tsv, jsonl or md", format)
+}
+
+// exportFiles makes the --file values absolute as source files are stored,
+// leaving origins such as "anki:Spanish" as they are
+func exportFiles(files []string) ([]string, error) {
+	resolved := make([]string, 0, len(files))
+	for _, f := range files {
+		if strings.HasPrefix(f, importer.AnkiPrefix) {
+			resolved = append(resolved, f)
+			continue
+		}
+		abs, err := filepath.Abs(f)
+		if err != nil {
+			return nil, err
+		}
+		resolved = append(resolved, abs)
+	}
+	return resolved, nil
+}
+
+// loadExportDeck returns the active flashcards that match filter with their
+// review history
+func loadExportDeck(filter exporter.Filter, now time.Time) (exporter.Deck, error) {
+	flashcards, err := Store.GetAllFlashcards()
+	if err != nil {
+		return exporter.Deck{}, err
+	}
+	deck := exporter.Deck{Flashcards: filter.Apply(flashcards, now), Reviews: make(map[int][]store.Review)}
+
+	selected := make(map[int]bool, len(deck.Flashcards))
+	for _, fc := range deck.Flashcards {
+		selected[fc.ID] = true
+	}
+	reviews, err := Store.GetReviewsSince(time.Time{})
+	if err != nil {
+		return exporter.Deck{}, err
+	}
+	for _, r := range reviews {
+		if selected[r.FlashcardID] {
+			deck.Reviews[r.FlashcardID] = append(deck.Reviews[r.FlashcardID], r)
+		}
+	}
+	return deck, nil
+}
+
+// writeExport writes deck to w in the given format
+func writeExport(w io.Writer, deck exporter.Deck, format string) error {
+	switch format {
+	case exportAnki:
+		return exporter.WriteAnki(w, deck, time.Now())
+	case exportCSV:
+		return exporter.WriteCSV(w, deck, ',')
+	case exportTSV:
+		return exporter.WriteCSV(w, deck, '\t')
+	case exportJSONL:
+		return exporter.WriteJSONL(w, deck)
+	default:
+		return exporter.WriteMarkdown(w, deck)
+	}
+}
+
+// writeExportFile writes deck to the file at path, removing it if the export fails
+func writeExportFile(path string, deck exporter.Deck, format string) error {
+	f, err := os.OpenFile(filepath.Clean(path), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	err = writeExport(f, deck, format)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+	}
+	return err
+}
+
+func init() {
+	ExportCmd.Flags().StringP("output", "o", "", "File to write the export to (standard output by default)")
+	ExportCmd.Flags().StringP("format", "f", "", "Export format: apkg, csv, tsv, jsonl or md (taken from the output extension by default)")
+	ExportCmd.Flags().StringSlice("file", nil, "Only export flashcards from these source files or directories")
+	ExportCmd.Flags().StringSlice("tag", nil, "Only export flashcards with one of these tags")
+	ExportCmd.Flags().Bool("due", false, "Only export flashcards that are due for review")
+}
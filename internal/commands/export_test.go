@@ -0,0 +1,109 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"catv/internal/exporter"
+	"catv/internal/store"
+)
+
+func TestExportFormat(t *testing.T) {
+	tests := []struct {
+		output, format, want string
+	}{
+		{"deck.apkg", "", exportAnki},
+		{"cards.TSV", "", exportTSV},
+		{"cards.json", "", exportJSONL},
+		{"", "markdown", exportMarkdown},
+		{"notes.txt", "csv", exportCSV},
+	}
+	for _, tt := range tests {
+		if got, err := exportFormat(tt.output, tt.format); err != nil || got != tt.want {
+			t.Errorf("exportFormat(%q, %q) = %q, %v, want %q", tt.output, tt.format, got, err, tt.want)
+		}
+	}
+	if _, err := exportFormat("", ""); err == nil {
+		t.Error("Expected an error without an output file or format")
+	}
+	if _, err := exportFormat("deck.apkg", "xml"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
+
+func TestLoadExportDeck(t *testing.T) {
+	useTestStore(t)
+	now := time.Now()
+
+	for _, fc := range []store.Flashcard{
+		{File: "/notes/a.md", Question: "Q1", Answer: "A1", Tags: []string{"net"}},
+		{File: "/notes/a.md", Question: "Q2", Answer: "A2", DueAt: now.AddDate(0, 0, 5)},
+		{File: "/notes/b.md", Question: "Q3", Answer: "A3", Tags: []string{"net"}},
+	} {
+		if err := Store.InsertFlashcard(fc); err != nil {
+			t.Fatalf("InsertFlashcard() error = %v", err)
+		}
+	}
+	cards, _ := Store.GetAllFlashcards()
+	for _, fc := range cards {
+		if _, err := Store.InsertReview(store.Review{FlashcardID: fc.ID, Grade: 3}); err != nil {
+			t.Fatalf("InsertReview() error = %v", err)
+		}
+	}
+
+	deck, err := loadExportDeck(exporter.Filter{Files: []string{"/notes/a.md"}, DueOnly: true}, now)
+	if err != nil {
+		t.Fatalf("loadExportDeck() error = %v", err)
+	}
+	if len(deck.Flashcards) != 1 || deck.Flashcards[0].Question != "Q1" {
+		t.Fatalf("Expected only the due flashcard of a.md, got %+v", deck.Flashcards)
+	}
+	if len(deck.Reviews) != 1 || len(deck.Reviews[deck.Flashcards[0].ID]) != 1 {
+		t.Errorf("Expected only the history of the exported flashcard, got %+v", deck.Reviews)
+	}
+
+	// Files are written in the requested format
+	path := filepath.Join(t.TempDir(), "net.md")
+	deck, _ = loadExportDeck(exporter.Filter{Tags: []string{"net"}}, now)
+	if err := writeExportFile(path, deck, exportMarkdown); err != nil {
+		t.Fatalf("writeExportFile() error = %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "## /notes/b.md") || strings.Contains(string(data), "Q2") {
+		t.Errorf("Unexpected export:\n%s", data)
+	}
+}
+
+func TestExportFiles_Relative(t *testing.T) {
+	useTestStore(t)
+	dir := t.TempDir()
+	t.Chdir(dir)
+	abs := filepath.Join(dir, "notes", "go.md")
+	for _, fc := range []store.Flashcard{
+		{File: abs, Question: "Q1", Answer: "A1"},
+		{File: filepath.Join(dir, "other.md"), Question: "Q2", Answer: "A2"},
+		{File: "anki:Spanish", Question: "Q3", Answer: "A3"},
+	} {
+		if err := Store.InsertFlashcard(fc); err != nil {
+			t.Fatalf("InsertFlashcard() error = %v", err)
+		}
+	}
+
+	files, err := exportFiles([]string{"notes/go.md", "anki:Spanish"})
+	if err != nil {
+		t.Fatalf("exportFiles() error = %v", err)
+	}
+	if len(files) != 2 || files[0] != abs || files[1] != "anki:Spanish" {
+		t.Fatalf("exportFiles() = %v", files)
+	}
+	deck, err := loadExportDeck(exporter.Filter{Files: files}, time.Now())
+	if err != nil {
+		t.Fatalf("loadExportDeck() error = %v", err)
+	}
+	if len(deck.Flashcards) != 2 {
+		t.Errorf("Expected the flashcards of notes/go.md and the Anki deck, got %+v", deck.Flashcards)
+	}
+}
@@ -2,52 +2,30 @@ package commands
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
+	"catv/internal/cloze"
 	"catv/internal/config"
+	"catv/internal/llm"
+	"catv/internal/markdown"
 	"catv/internal/ollama"
+	"catv/internal/prompt"
 	"catv/internal/security"
 	"catv/internal/store"
 	"catv/internal/tui"
 
-	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 )
 
-type spinnerModel struct {
-	spinner spinner.Model
-	msg     string
-	done    bool
-}
-
-func (m spinnerModel) Init() tea.Cmd {
-	return m.spinner.Tick
-}
-
-func (m spinnerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	if tickMsg, ok := msg.(spinner.TickMsg); ok {
-		if m.done {
-			return m, tea.Quit
-		}
-		var cmd tea.Cmd
-		m.spinner, cmd = m.spinner.Update(tickMsg)
-		return m, cmd
-	}
-	return m, nil
-}
-
-func (m spinnerModel) View() string {
-	if m.done {
-		return m.msg
-	}
-	return fmt.Sprintf("%s Generating flashcards...", m.spinner.View())
-}
-
 var GenerateCmd = &cobra.Command{
 	Use:   "generate",
 	Short: "Generate flashcards from markdown files",
@@ -55,7 +33,28 @@ var GenerateCmd = &cobra.Command{
 	
 This command processes markdown files (or directories containing markdown files)
 and automatically generates question-answer pairs using the configured Ollama model.
-Each flashcard is stored in the local SQLite database for review.`,
+Each flashcard is stored in the local SQLite database for review.
+
+Notes are split into heading sections and only sections that changed since the
+last run are sent to the model. Flashcards from sections that changed or were
+removed can then be archived, keeping the review history of everything else.
+Use --force to regenerate every section.
+
+Long sections are split into chunks at paragraph boundaries so each request fits
+the model's context window. The chunk size can be set per model with
+generate.chunk_tokens in the configuration or CATV_CHUNK_TOKENS
+(e.g. "llama3.1=6000,1500"), or for a single run with --chunk-tokens.
+
+Prompts are Go text/template files. --prompt-template (or generate.template)
+picks a built-in template (default, definitions, concepts, code, vocabulary) or
+a template file; a note can pick its own with "template:" in its front matter,
+along with "language:", "difficulty:" and "cards:". A template file has the
+variables .Content, .File, .Heading, .Cards, .Language, .Difficulty and
+.Instructions, and must include {{template "format" .}} so the flashcards can
+be read back.
+
+The .catv.toml found in the notes folder or one of its parents applies on top
+of the user configuration; see catv config.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		path, _ := cmd.Flags().GetString("path")
 		if path == "" {
@@ -69,22 +68,40 @@ Each flashcard is stored in the local SQLite database for review.`,
 			os.Exit(1)
 		}
 
-		// Load configuration
-		cfg := config.LoadConfig()
-		model := Model // Use command line flag if provided, otherwise default
-		if model == "" {
-			model = cfg.OllamaModel
-		}
+		// Load configuration, including the .catv.toml of the notes folder
+		cfg := loadConfig(cmd)
+		model := ollamaModel(cfg)
 
-		// Validate Ollama URL
+		// Validate the model server URL
 		if err := security.ValidateURL(cfg.OllamaURL); err != nil {
-			tui.PrintError("Invalid Ollama URL:", err)
+			tui.PrintError("Invalid model server URL:", err)
 			os.Exit(1)
 		}
+		provider, err := newProvider(cfg)
+		if err != nil {
+			tui.PrintError("Invalid provider:", err)
+			os.Exit(1)
+		}
+
+		// Offer the installed models when none was chosen
+		if !modelConfigured(cfg) {
+			picked, err := pickModel(provider, model)
+			if errors.Is(err, errPickerCancelled) {
+				tui.PrintInfo("No model chosen; set one with --model or catv config set ollama.model")
+				return
+			}
+			if err != nil {
+				tui.PrintError("Model picker error:", err)
+				os.Exit(1)
+			}
+			if picked != "" {
+				model = picked
+			}
+		}
 
 		tui.PrintInfo(fmt.Sprintf("Model: %s", model))
 		tui.PrintInfo(fmt.Sprintf("Database: %s", cfg.DatabasePath))
-		tui.PrintInfo(fmt.Sprintf("API Target: %s", cfg.OllamaURL))
+		tui.PrintInfo(fmt.Sprintf("API Target: %s (%s)", cfg.OllamaURL, provider.Name()))
 
 		files, err := getMarkdownFiles(path)
 		if err != nil {
@@ -92,109 +109,505 @@ Each flashcard is stored in the local SQLite database for review.`,
 			os.Exit(1)
 		}
 
-		for _, f := range files {
-			absPath, _ := filepath.Abs(f)
-			processed, err := Store.IsFileProcessed(absPath)
+		force, _ := cmd.Flags().GetBool("force")
+		concurrency := cfg.Concurrency
+		if cmd.Flags().Changed("concurrency") {
+			concurrency, _ = cmd.Flags().GetInt("concurrency")
+		}
+		if concurrency < 1 {
+			tui.PrintError("Invalid --concurrency:", fmt.Errorf("must be at least 1, got %d", concurrency))
+			os.Exit(1)
+		}
+
+		chunkTokens, _ := cmd.Flags().GetInt("chunk-tokens")
+		if chunkTokens < 0 {
+			tui.PrintError("Invalid --chunk-tokens:", fmt.Errorf("must be at least 0, got %d", chunkTokens))
+			os.Exit(1)
+		}
+		if chunkTokens == 0 {
+			chunkTokens = cfg.ChunkTokensFor(model)
+		}
+
+		jobs, superseded := planGeneration(files, force, chunkTokens)
+		templateName, _ := cmd.Flags().GetString("prompt-template")
+		if templateName == "" {
+			templateName = cfg.PromptTemplate
+		}
+		if err := choosePrompts(jobs, templateName, cfg); err != nil {
+			tui.PrintError("Invalid prompt template:", err)
+			os.Exit(1)
+		}
+
+		// Check the model once before any note is sent to it
+		if len(jobs) > 0 {
+			if err := checkModel(provider, model); err != nil {
+				tui.PrintError("Model not available:", err)
+				os.Exit(1)
+			}
+		}
+
+		// Notes that were deleted take their flashcards with them
+		var missing []string
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			seen := make(map[string]bool, len(files))
+			for _, f := range files {
+				absPath, _ := filepath.Abs(f)
+				seen[absPath] = true
+			}
+			absRoot, _ := filepath.Abs(path)
+			recorded, err := Store.GetSourceFiles()
 			if err != nil {
 				tui.PrintError("DB query error:", err)
-				continue
 			}
-			if processed {
-				tui.PrintInfo(fmt.Sprintf("Skipping already processed: %s", absPath))
-				continue
+			missing = missingSourceFiles(recorded, absRoot, seen)
+			for _, file := range missing {
+				cards, err := Store.GetFlashcardsByFile(file)
+				if err != nil {
+					tui.PrintError("DB query error:", err)
+					continue
+				}
+				tui.PrintInfo(fmt.Sprintf("Source file removed: %s", file))
+				superseded = append(superseded, cards...)
 			}
+		}
 
-			data, err := os.ReadFile(filepath.Clean(f))
-			if err != nil {
-				tui.PrintError("Read error:", err)
-				continue
+		if len(jobs) > 0 {
+			timeout := time.Duration(cfg.RequestTimeout) * time.Second
+			generate := func(ctx context.Context, prompt string, structured bool, onToken func(string)) (string, error) {
+				// Bound each request so a stalled model cannot hold up the run
+				ctx, cancel := context.WithTimeout(ctx, timeout)
+				defer cancel()
+				req := llm.Request{Model: model, Prompt: prompt, OnToken: onToken}
+				if structured {
+					req.Schema = ollama.FlashcardSchema
+				}
+				return provider.Generate(ctx, req)
 			}
+			summary := generateWithProgress(jobs, concurrency, model, generate)
+			for _, e := range summary.errors {
+				tui.PrintError(e, nil)
+			}
+			superseded = append(superseded, summary.superseded...)
+		}
 
-			prompt := fmt.Sprintf(`You are an expert flashcard generator. Your task is to extract spaced repetition flashcards from the following markdown content.
+		archiveSuperseded(superseded, missing)
+	},
+}
 
-Strictly output ONLY pairs in this format, with no extra text, explanations, or numbering:
-Q: <question>
-A: <answer>
+// generateJob is a source file with sections that need flashcards
+type generateJob struct {
+	file     string               // Absolute path of the source file
+	fileHash string               // Fingerprint of the whole file
+	sections []markdown.Section   // Every section currently in the file
+	stored   store.SourceHashes   // Fingerprints recorded by the previous run
+	plan     sectionPlan          // Sections to regenerate
+	existing []store.Flashcard    // Active flashcards before regenerating
+	force    bool                 // Whether --force was given
+	meta     markdown.FrontMatter // Deck and tags from the note's front matter
 
-Repeat for each flashcard. Do not include any other text, headers, or formatting. Do not add explanations, summaries, or comments. Only output Q: and A: pairs, one after another.
+	chunkTokens int              // Token budget of each chunk sent to the model
+	template    *prompt.Template // Prompt template for the note (nil for the default)
+	vars        prompt.Data      // Prompt variables shared by the note's chunks
+}
 
-Example:
-Q: What is the capital of France?
-A: Paris
-Q: What is 2+2?
-A: 4
+// jobResult holds the flashcards generated for a job, before they are saved
+type jobResult struct {
+	job         generateJob
+	cards       []store.Flashcard
+	regenerated map[string]bool // Headings of sections the model processed successfully
+	failed      map[string]bool // Headings of sections that could not be processed
+	errors      []string
+}
 
-Markdown:
-%s`, string(data))
+// generationSummary is the outcome of generating flashcards for all jobs
+type generationSummary struct {
+	cards      int               // Flashcards saved
+	errors     []string          // Error messages to show once the progress view closes
+	superseded []store.Flashcard // Flashcards replaced by the regenerated sections
+}
 
-			doneChan := make(chan string)
-			go func() {
-				// Create context with timeout for Ollama request
-				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
-				defer cancel()
+// generateFunc sends a prompt to the model and returns its raw response
+// With structured set the model is asked for JSON following ollama.FlashcardSchema
+// onToken, if not nil, receives the response as the model writes it
+type generateFunc func(ctx context.Context, prompt string, structured bool, onToken func(string)) (string, error)
 
-				resp, err := ollama.GenerateQA(ctx, model, cfg.OllamaURL, prompt)
-				if err != nil {
-					doneChan <- fmt.Sprintf("Ollama error: %v", err)
-					return
-				}
-				// DEBUG: Print raw Ollama response for troubleshooting
-				// tui.PrintInfo("Raw Ollama response:")
-				// tui.PrintInfo(resp)
-				qas, err := ollama.ParseFlashcards(resp)
+// planGeneration decides which files need flashcards and records hashes for
+// files generated before changes were tracked
+// It returns the jobs to run and, for files with nothing to regenerate, the
+// flashcards of sections that were removed
+func planGeneration(files []string, force bool, chunkTokens int) ([]generateJob, []store.Flashcard) {
+	var jobs []generateJob
+	var superseded []store.Flashcard
+
+	for _, f := range files {
+		absPath, _ := filepath.Abs(f)
+
+		data, err := os.ReadFile(filepath.Clean(f))
+		if err != nil {
+			tui.PrintError("Read error:", err)
+			continue
+		}
+		content := string(data)
+		// The front matter is metadata for catv, not part of the note
+		meta, body := markdown.ParseFrontMatter(content)
+		job := generateJob{
+			file:        absPath,
+			fileHash:    markdown.Hash(content),
+			sections:    markdown.Split(body),
+			meta:        meta,
+			force:       force,
+			chunkTokens: chunkTokens,
+		}
+
+		stored, known, err := Store.GetSourceHashes(absPath)
+		if err != nil {
+			tui.PrintError("DB query error:", err)
+			continue
+		}
+		job.stored = stored
+		if !force {
+			if known && stored.Hash == job.fileHash {
+				tui.PrintInfo(fmt.Sprintf("Skipping unchanged: %s", absPath))
+				continue
+			}
+			if !known {
+				processed, err := Store.IsFileProcessed(absPath)
 				if err != nil {
-					doneChan <- fmt.Sprintf("Ollama parsing error: %v", err)
-					return
+					tui.PrintError("DB query error:", err)
+					continue
 				}
-				count := 0
-				for _, qa := range qas {
-					fc := store.Flashcard{
-						File:      absPath,
-						Question:  qa["question"],
-						Answer:    qa["answer"],
-						RevisitIn: 0, // Due immediately
-					}
-					err := Store.InsertFlashcard(fc)
-					if err != nil {
+				if processed {
+					// Generated before content hashes were tracked: keep its flashcards
+					// and start detecting changes from now on
+					if err := Store.SaveSourceHashes(recordedHashes(absPath, job.fileHash, job.sections, stored, nil)); err != nil {
 						tui.PrintError("DB insert error:", err)
-					} else {
-						count++
 					}
+					tui.PrintInfo(fmt.Sprintf("Skipping already processed: %s (now tracking changes)", absPath))
+					continue
 				}
-				if count > 0 {
-					doneChan <- fmt.Sprintf("Processed: %s (%d flashcards generated)", absPath, count)
-				} else {
-					doneChan <- fmt.Sprintf("No flashcards inserted for: %s", absPath)
-				}
-			}()
+			}
+		}
 
-			sm := spinnerModel{spinner: spinner.New(), done: false}
-			p := tea.NewProgram(&sm)
+		job.existing, err = Store.GetFlashcardsByFile(absPath)
+		if err != nil {
+			tui.PrintError("DB query error:", err)
+			continue
+		}
+		job.plan = planSections(job.sections, stored, force)
+		if len(job.plan.changed) == 0 {
+			// Only whitespace between sections or removed sections changed
+			tui.PrintInfo(fmt.Sprintf("No changed sections in: %s", absPath))
+			if err := Store.SaveSourceHashes(recordedHashes(absPath, job.fileHash, job.sections, stored, nil)); err != nil {
+				tui.PrintError("DB insert error:", err)
+			}
+			superseded = append(superseded, supersededFlashcards(job.existing, job.plan, nil, force)...)
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, superseded
+}
 
-			go func() {
-				for msg := range doneChan {
-					sm.msg = msg
-					sm.done = true
-					p.Quit()
-				}
-			}()
+// generateWithProgress runs the jobs while showing a single progress view
+// Quitting the view stops outstanding requests; flashcards already generated are kept
+func generateWithProgress(jobs []generateJob, concurrency int, model string, generate generateFunc) generationSummary {
+	files := make([]string, len(jobs))
+	for i, job := range jobs {
+		files[i] = job.file
+	}
+	progressModel := tui.NewGenerateModel(files, model)
+	p := tea.NewProgram(progressModel)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	summaryChan := make(chan generationSummary, 1)
+	go func() {
+		summary := runGeneration(ctx, jobs, concurrency, generate, p.Send)
+		p.Send(tui.GenerateFinishedMsg{})
+		summaryChan <- summary
+	}()
+
+	_, tuiErr := p.Run()
+	if tuiErr != nil {
+		// Without a terminal the jobs keep running, only the progress view is lost
+		tui.PrintError("TUI error:", tuiErr)
+	}
+	if progressModel.Interrupted() {
+		cancel()
+	}
+	summary := <-summaryChan
+	if tuiErr != nil {
+		tui.PrintSuccess(fmt.Sprintf("Generated %d flashcards from %d file(s)", summary.cards, len(jobs)))
+	}
+	return summary
+}
 
-			if _, err := p.Run(); err != nil {
-				tui.PrintError("TUI error:", err)
+// runGeneration generates flashcards for the jobs with a bounded pool of workers
+// Only the calling goroutine writes to the database, so SQLite never sees
+// concurrent writers; progress is reported through notify
+func runGeneration(ctx context.Context, jobs []generateJob, concurrency int, generate generateFunc, notify func(tea.Msg)) generationSummary {
+	jobChan := make(chan generateJob)
+	results := make(chan jobResult)
+	gen := newCardGenerator(generate)
+
+	var wg sync.WaitGroup
+	for range min(concurrency, len(jobs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobChan {
+				notify(tui.GenerateFileStartedMsg{File: job.file})
+				results <- generateCards(ctx, job, gen, notify)
 			}
-			if sm.msg != "" {
-				if sm.done {
-					tui.PrintSuccess(sm.msg)
-				} else {
-					tui.PrintInfo(sm.msg)
-				}
+		}()
+	}
+	go func() {
+		defer close(jobChan)
+		for _, job := range jobs {
+			select {
+			case jobChan <- job:
+			case <-ctx.Done():
+				return
 			}
 		}
-	},
+	}()
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var summary generationSummary
+	for res := range results {
+		saved, errs := saveResult(res)
+		summary.cards += saved
+		summary.errors = append(summary.errors, errs...)
+		summary.superseded = append(summary.superseded, supersededFlashcards(res.job.existing, res.job.plan, res.regenerated, res.job.force)...)
+
+		var err error
+		if len(errs) > 0 {
+			err = fmt.Errorf("%s", errs[0])
+		}
+		notify(tui.GenerateFileDoneMsg{File: res.job.file, Cards: saved, Err: err})
+	}
+	return summary
+}
+
+// generateCards asks the model for flashcards for each changed section of a job
+// A section only counts as regenerated when every one of its chunks succeeded
+// The cards are previewed through notify, if not nil, as the model writes them
+func generateCards(ctx context.Context, job generateJob, gen *cardGenerator, notify func(tea.Msg)) jobResult {
+	res := jobResult{job: job, regenerated: make(map[string]bool), failed: make(map[string]bool)}
+	var preview *cardPreview
+	if notify != nil {
+		preview = &cardPreview{file: job.file, notify: notify}
+	}
+	for _, sec := range job.plan.changed {
+		if ctx.Err() != nil {
+			res.failed[sec.Heading] = true
+			continue
+		}
+		cards, err := generateSection(ctx, job, sec, gen, preview)
+		if err != nil {
+			res.failed[sec.Heading] = true
+			if ctx.Err() == nil {
+				res.errors = append(res.errors, fmt.Sprintf("Ollama error for %s: %v", job.file, err))
+			}
+			continue
+		}
+		res.regenerated[sec.Heading] = true
+		res.cards = append(res.cards, cards...)
+	}
+	return res
+}
+
+// cardPreview reports the flashcards of a file to the progress view while the
+// model writes them
+type cardPreview struct {
+	file   string
+	notify func(tea.Msg)
+	done   int // Cards from the chunks already answered
+}
+
+// show reports the cards complete so far in the answer to the current chunk
+func (p *cardPreview) show(cards []ollama.GeneratedCard) {
+	msg := tui.GenerateCardsMsg{File: p.file, Cards: p.done + len(cards)}
+	if len(cards) > 0 {
+		msg.Latest = cards[len(cards)-1].Question
+	}
+	p.notify(msg)
+}
+
+// choosePrompts sets the prompt template and variables of each job
+// A note's front matter wins over name, the template given on the command line
+// or in the configuration; template paths in front matter are relative to the note
+func choosePrompts(jobs []generateJob, name string, cfg *config.Config) error {
+	loaded := make(map[string]*prompt.Template)
+	load := func(name string) (*prompt.Template, error) {
+		if tmpl, ok := loaded[name]; ok {
+			return tmpl, nil
+		}
+		tmpl, err := prompt.Load(name)
+		if err != nil {
+			return nil, err
+		}
+		loaded[name] = tmpl
+		return tmpl, nil
+	}
+
+	// Check the template even when no note needs it, so mistakes show up at once
+	base, err := load(name)
+	if err != nil {
+		return err
+	}
+	for i := range jobs {
+		job := &jobs[i]
+		job.template = base
+		if chosen := job.meta.Template; chosen != "" {
+			if !slices.Contains(prompt.Names(), chosen) && !filepath.IsAbs(chosen) {
+				chosen = filepath.Join(filepath.Dir(job.file), chosen)
+			}
+			if job.template, err = load(chosen); err != nil {
+				return fmt.Errorf("%s: %w", job.file, err)
+			}
+		}
+
+		job.vars = prompt.Data{
+			File:         filepath.Base(job.file),
+			Cards:        cfg.Cards,
+			Language:     cfg.Language,
+			Difficulty:   cfg.Difficulty,
+			Instructions: cfg.Prompt,
+		}
+		if job.meta.Cards > 0 {
+			job.vars.Cards = job.meta.Cards
+		}
+		if job.meta.Language != "" {
+			job.vars.Language = job.meta.Language
+		}
+		if job.meta.Difficulty != "" {
+			job.vars.Difficulty = job.meta.Difficulty
+		}
+	}
+	return nil
+}
+
+// generateSection sends a section to the model one chunk at a time, so long
+// notes never overflow the model's context window
+// Cloze deletions written in the note become flashcards directly, and the model
+// sees the note with the deletions filled in
+// preview, if not nil, is shown the cards as the model writes them
+func generateSection(ctx context.Context, job generateJob, sec markdown.Section, gen *cardGenerator, preview *cardPreview) ([]store.Flashcard, error) {
+	var show func([]ollama.GeneratedCard)
+	if preview != nil {
+		show = preview.show
+	}
+	var cards []store.Flashcard
+	for _, text := range cloze.Extract(sec.Content) {
+		cards = append(cards, newFlashcards(job.file, sec.Heading, ollama.GeneratedCard{Type: ollama.ClozeCard, Question: text})...)
+	}
+	for _, chunk := range markdown.Chunk(cloze.Reveal(sec.Content), job.chunkTokens) {
+		data := job.vars
+		data.Heading, data.Content = sec.Heading, chunk
+		generated, err := gen.flashcards(ctx, job.template, data, show)
+		if err != nil {
+			return nil, err
+		}
+		if preview != nil {
+			preview.done += len(generated)
+		}
+		for _, c := range generated {
+			cards = append(cards, newFlashcards(job.file, sec.Heading, c)...)
+		}
+	}
+	return job.classify(cards, sec), nil
+}
+
+// classify puts the flashcards of a section in the note's deck and tags them
+// with the note's front matter tags and the #hashtags of the section
+func (job generateJob) classify(cards []store.Flashcard, sec markdown.Section) []store.Flashcard {
+	tags := append(append([]string(nil), job.meta.Tags...), markdown.Hashtags(sec.Content)...)
+	for i := range cards {
+		cards[i].Deck = job.meta.Deck
+		cards[i].Tags = append(append([]string(nil), tags...), cards[i].Tags...)
+	}
+	return cards
+}
+
+// newFlashcards turns a generated card into flashcards: one for a basic card,
+// one per deletion number for a cloze card
+// Sections are split at every heading, so the section breadcrumb is used
+// rather than the heading reported by the model
+func newFlashcards(file, heading string, c ollama.GeneratedCard) []store.Flashcard {
+	// Zero DueAt makes the flashcards due immediately
+	base := store.Flashcard{File: file, Heading: heading, Question: c.Question, Answer: c.Answer, Tags: c.Tags, CardType: store.CardBasic}
+	if c.Type != ollama.ClozeCard {
+		return []store.Flashcard{base}
+	}
+	indices := cloze.Indices(c.Question)
+	cards := make([]store.Flashcard, len(indices))
+	for i, n := range indices {
+		cards[i] = base
+		cards[i].CardType = store.CardCloze
+		cards[i].ClozeIndex = n
+	}
+	return cards
+}
+
+// saveResult stores the generated flashcards and the file's new fingerprints
+// It returns the number of flashcards saved and any errors
+// The flashcards of a job are saved together; if that fails, its sections are
+// marked failed so they are retried and their old flashcards are kept
+func saveResult(res jobResult) (int, []string) {
+	errs := res.errors
+	saved := len(res.cards)
+	if err := Store.InsertFlashcards(res.cards); err != nil {
+		errs = append(errs, fmt.Sprintf("DB insert error: %v", err))
+		saved = 0
+		for heading := range res.regenerated {
+			res.failed[heading] = true
+			delete(res.regenerated, heading)
+		}
+	}
+	src := recordedHashes(res.job.file, res.job.fileHash, res.job.sections, res.job.stored, res.failed)
+	if err := Store.SaveSourceHashes(src); err != nil {
+		errs = append(errs, fmt.Sprintf("DB insert error: %v", err))
+	}
+	return saved, errs
+}
+
+// archiveSuperseded offers to archive flashcards whose source changed or disappeared
+func archiveSuperseded(superseded []store.Flashcard, missing []string) {
+	if len(superseded) == 0 {
+		return
+	}
+	question := fmt.Sprintf("Archive %d flashcard(s) whose source section changed or was removed? Their review history is kept.", len(superseded))
+	if !confirm(os.Stdin, os.Stdout, question) {
+		tui.PrintInfo("Kept all existing flashcards")
+		return
+	}
+	ids := make([]int, len(superseded))
+	for i, fc := range superseded {
+		ids[i] = fc.ID
+	}
+	if err := Store.ArchiveFlashcards(ids); err != nil {
+		tui.PrintError("DB update error:", err)
+		return
+	}
+	for _, file := range missing {
+		if err := Store.DeleteSourceHashes(file); err != nil {
+			tui.PrintError("DB update error:", err)
+		}
+	}
+	tui.PrintSuccess(fmt.Sprintf("Archived %d flashcard(s)", len(superseded)))
 }
 
 func init() {
 	GenerateCmd.Flags().StringP("path", "p", "", "Markdown file or folder to process")
+	GenerateCmd.Flags().Bool("force", false, "Regenerate flashcards for every section, even unchanged ones")
+	GenerateCmd.Flags().IntP("concurrency", "c", config.DefaultConcurrency, "Number of files to send to Ollama in parallel, overriding generate.concurrency")
+	GenerateCmd.Flags().String("prompt-template", "", fmt.Sprintf("Prompt template: built in (%s) or a template file, overriding generate.template", strings.Join(prompt.Names(), ", ")))
+	GenerateCmd.Flags().Int("chunk-tokens", 0, "Approximate token budget of each note chunk sent to the model (default from generate.chunk_tokens)")
 }
 
 func getMarkdownFiles(path string) ([]string, error) {
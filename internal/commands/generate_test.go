@@ -0,0 +1,342 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"catv/internal/config"
+	"catv/internal/markdown"
+	"catv/internal/prompt"
+	"catv/internal/store"
+	"catv/internal/tui"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// useTestStore points the package level Store at a fresh database for the test
+func useTestStore(t *testing.T) {
+	t.Helper()
+	s, err := store.NewStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	previous := Store
+	Store = s
+	t.Cleanup(func() {
+		s.Close()
+		Store = previous
+	})
+}
+
+func writeNotes(t *testing.T, notes map[string]string) []string {
+	t.Helper()
+	dir := t.TempDir()
+	files := make([]string, 0, len(notes))
+	for name, content := range notes {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+		files = append(files, path)
+	}
+	return files
+}
+
+func TestRunGeneration(t *testing.T) {
+	useTestStore(t)
+	files := writeNotes(t, map[string]string{
+		"a.md":      "# A\nalpha",
+		"b.md":      "# B\nbeta\n# C\ngamma",
+		"c.md":      "# D\ndelta",
+		"broken.md": "# E\nFAIL",
+	})
+
+	jobs, superseded := planGeneration(files, false, 1000)
+	if len(jobs) != 4 || len(superseded) != 0 {
+		t.Fatalf("Expected 4 jobs for new files, got %d (%d superseded)", len(jobs), len(superseded))
+	}
+
+	var inFlight, maxInFlight int32
+	generate := func(ctx context.Context, prompt string, structured bool, onToken func(string)) (string, error) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		if strings.Contains(prompt, "FAIL") {
+			return "", errors.New("model unavailable")
+		}
+		resp := `{"flashcards": [{"question": "What is this?", "answer": "A section"}]}`
+		if onToken != nil {
+			for _, token := range strings.SplitAfter(resp, " ") {
+				onToken(token)
+			}
+		}
+		return resp, nil
+	}
+
+	var mu sync.Mutex
+	var started, done int
+	var failedFiles []string
+	previewed := make(map[string]int) // Most cards previewed per file
+	notify := func(msg tea.Msg) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch msg := msg.(type) {
+		case tui.GenerateFileStartedMsg:
+			started++
+		case tui.GenerateCardsMsg:
+			previewed[filepath.Base(msg.File)] = max(previewed[filepath.Base(msg.File)], msg.Cards)
+		case tui.GenerateFileDoneMsg:
+			done++
+			if msg.Err != nil {
+				failedFiles = append(failedFiles, filepath.Base(msg.File))
+			}
+		}
+	}
+
+	summary := runGeneration(context.Background(), jobs, 2, generate, notify)
+
+	if summary.cards != 4 {
+		t.Errorf("Expected 4 flashcards (one per successful section), got %d", summary.cards)
+	}
+	if len(summary.errors) != 1 || !strings.Contains(summary.errors[0], "model unavailable") {
+		t.Errorf("Expected the failing file to be reported, got %v", summary.errors)
+	}
+	if maxInFlight > 2 {
+		t.Errorf("Expected at most 2 concurrent requests, got %d", maxInFlight)
+	}
+	if started != 4 || done != 4 || len(failedFiles) != 1 || failedFiles[0] != "broken.md" {
+		t.Errorf("Unexpected progress: %d started, %d done, failed %v", started, done, failedFiles)
+	}
+	// Cards are previewed as they stream in, counted across a file's sections
+	if previewed["a.md"] != 1 || previewed["b.md"] != 2 || previewed["broken.md"] != 0 {
+		t.Errorf("Unexpected previews %v", previewed)
+	}
+
+	cards, err := Store.GetAllFlashcards()
+	if err != nil {
+		t.Fatalf("GetAllFlashcards() error = %v", err)
+	}
+	if len(cards) != 4 {
+		t.Errorf("Expected 4 flashcards in the database, got %d", len(cards))
+	}
+
+	// Successful files are skipped next time, the failed one is retried
+	jobs, _ = planGeneration(files, false, 1000)
+	if len(jobs) != 1 || filepath.Base(jobs[0].file) != "broken.md" {
+		t.Errorf("Expected only broken.md to be retried, got %d jobs", len(jobs))
+	}
+}
+
+func TestRunGeneration_InsertFails(t *testing.T) {
+	useTestStore(t)
+	files := writeNotes(t, map[string]string{"a.md": "# A\nalpha\n# B\nbeta"})
+	jobs, _ := planGeneration(files, false, 1000)
+	if _, err := Store.DB.Exec(`CREATE TRIGGER fail_insert BEFORE INSERT ON flashcards
+		BEGIN SELECT RAISE(ABORT, 'disk full'); END`); err != nil {
+		t.Fatalf("Failed to create trigger: %v", err)
+	}
+	generate := func(ctx context.Context, prompt string, structured bool, onToken func(string)) (string, error) {
+		return `{"flashcards": [{"question": "What is this?", "answer": "A section"}]}`, nil
+	}
+
+	summary := runGeneration(context.Background(), jobs, 1, generate, func(tea.Msg) {})
+	if summary.cards != 0 || len(summary.errors) != 1 || !strings.Contains(summary.errors[0], "disk full") {
+		t.Errorf("Expected the insert error and no cards, got %d cards and %v", summary.cards, summary.errors)
+	}
+
+	// Nothing was saved, so every section is generated again next time
+	if _, err := Store.DB.Exec("DROP TRIGGER fail_insert"); err != nil {
+		t.Fatalf("Failed to drop trigger: %v", err)
+	}
+	jobs, _ = planGeneration(files, false, 1000)
+	if len(jobs) != 1 || len(jobs[0].plan.changed) != 2 {
+		t.Fatalf("Expected both sections to be retried, got %d jobs", len(jobs))
+	}
+}
+
+func TestRunGeneration_Cancelled(t *testing.T) {
+	useTestStore(t)
+	files := writeNotes(t, map[string]string{"a.md": "# A\nalpha", "b.md": "# B\nbeta"})
+	jobs, _ := planGeneration(files, false, 1000)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	calls := int32(0)
+	generate := func(ctx context.Context, prompt string, structured bool, onToken func(string)) (string, error) {
+		atomic.AddInt32(&calls, 1)
+		return `{"flashcards": []}`, nil
+	}
+
+	summary := runGeneration(ctx, jobs, 1, generate, func(tea.Msg) {})
+	if calls != 0 || summary.cards != 0 {
+		t.Errorf("Expected no requests after cancellation, got %d calls and %d cards", calls, summary.cards)
+	}
+}
+
+func TestGenerateCards_Chunks(t *testing.T) {
+	para := strings.TrimSpace(strings.Repeat("word ", 40))
+	content := "# Go\n## Channels\n" + para + "\n\n" + para + "\n\n" + para + "\n# Rust\n" + para + "\n\nFAIL"
+	sections := markdown.Split(content)
+	job := generateJob{
+		file:        "/notes/go.md",
+		sections:    sections,
+		plan:        planSections(sections, store.SourceHashes{}, false),
+		chunkTokens: 60,
+	}
+
+	var prompts []string
+	generate := func(ctx context.Context, prompt string, structured bool, onToken func(string)) (string, error) {
+		prompts = append(prompts, prompt)
+		if strings.Contains(prompt, "FAIL") {
+			return "", errors.New("model unavailable")
+		}
+		return `{"flashcards": [{"question": "What is this?", "answer": "A chunk", "tags": ["Go"]}]}`, nil
+	}
+
+	res := generateCards(context.Background(), job, newCardGenerator(generate), nil)
+
+	channels := 0
+	for _, p := range prompts {
+		if strings.Contains(p, `"Go > Channels"`) {
+			channels++
+		}
+	}
+	if channels != 3 {
+		t.Errorf("Expected 3 chunk prompts with the section breadcrumb, got %d of %d", channels, len(prompts))
+	}
+	if !res.regenerated["Go > Channels"] || !res.failed["Rust"] {
+		t.Errorf("Unexpected outcome: regenerated %v, failed %v", res.regenerated, res.failed)
+	}
+	// Cards from the successful chunks of a failed section are dropped
+	if len(res.cards) != 3 {
+		t.Errorf("Expected 3 flashcards, got %d", len(res.cards))
+	}
+	for _, fc := range res.cards {
+		if fc.Heading != "Go > Channels" {
+			t.Errorf("Expected flashcard heading %q, got %q", "Go > Channels", fc.Heading)
+		}
+		if len(fc.Tags) != 1 || fc.Tags[0] != "Go" {
+			t.Errorf("Expected the generated tags to be kept, got %v", fc.Tags)
+		}
+	}
+}
+
+func TestGenerateSection_Cloze(t *testing.T) {
+	sec := markdown.Split("# Go\nGo was created at {{c1::Google}} in {{c2::2009}}.\n\nIt compiles fast.")[0]
+	var prompt string
+	gen := newCardGenerator(func(ctx context.Context, p string, structured bool, onToken func(string)) (string, error) {
+		prompt = p
+		return `{"flashcards": [{"type": "cloze", "question": "Go {{c1::compiles}} fast", "answer": ""}, {"question": "Is Go fast?", "answer": "Yes"}]}`, nil
+	})
+
+	cards, err := generateSection(context.Background(), generateJob{file: "/notes/go.md"}, sec, gen, nil)
+	if err != nil {
+		t.Fatalf("generateSection() error = %v", err)
+	}
+	note := prompt[strings.Index(prompt, "Markdown:"):]
+	if strings.Contains(note, "{{c") || !strings.Contains(note, "created at Google in 2009") {
+		t.Errorf("Expected the model to see the note without cloze markers, got %q", note)
+	}
+
+	var clozes, basics []int
+	for _, fc := range cards {
+		switch fc.CardType {
+		case store.CardCloze:
+			clozes = append(clozes, fc.ClozeIndex)
+		case store.CardBasic:
+			basics = append(basics, fc.ID)
+		}
+		if fc.Heading != "Go" {
+			t.Errorf("Expected heading Go, got %q", fc.Heading)
+		}
+	}
+	// Two siblings from the note, one cloze and one basic card from the model
+	if len(clozes) != 3 || clozes[0] != 1 || clozes[1] != 2 || clozes[2] != 1 || len(basics) != 1 {
+		t.Errorf("Unexpected cards: %+v", cards)
+	}
+}
+
+func TestGenerateSection_DeckAndTags(t *testing.T) {
+	useTestStore(t)
+	files := writeNotes(t, map[string]string{
+		"channels.md": "---\ndeck: Go Interview\ntags: [go]\n---\n# Channels\nChannels connect goroutines. #concurrency\n",
+	})
+	jobs, _ := planGeneration(files, false, 0)
+	if len(jobs) != 1 || len(jobs[0].sections) != 1 {
+		t.Fatalf("Expected one job with one section, got %+v", jobs)
+	}
+	sec := jobs[0].sections[0]
+	if strings.Contains(sec.Content, "deck:") {
+		t.Errorf("Expected the front matter to be left out of the sections, got %q", sec.Content)
+	}
+
+	gen := newCardGenerator(func(ctx context.Context, p string, structured bool, onToken func(string)) (string, error) {
+		return `{"flashcards": [{"question": "What do channels connect?", "answer": "Goroutines", "tags": ["sync"]}]}`, nil
+	})
+	cards, err := generateSection(context.Background(), jobs[0], sec, gen, nil)
+	if err != nil {
+		t.Fatalf("generateSection() error = %v", err)
+	}
+	if len(cards) != 1 || cards[0].Deck != "Go Interview" {
+		t.Fatalf("Expected a flashcard in the front matter deck, got %+v", cards)
+	}
+	if want := []string{"go", "concurrency", "sync"}; strings.Join(cards[0].Tags, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected tags %q, got %q", want, cards[0].Tags)
+	}
+}
+
+func TestChoosePrompts(t *testing.T) {
+	useTestStore(t)
+	files := writeNotes(t, map[string]string{
+		"plain.md":   "# Go\nGoroutines",
+		"spanish.md": "---\ntemplate: vocabulary\nlanguage: English\n---\n# Animals\nperro",
+		"custom.md":  "---\ntemplate: cards.tmpl\ncards: 3\n---\n# Custom\ntext",
+	})
+	dir := filepath.Dir(files[0])
+	if err := os.WriteFile(filepath.Join(dir, "cards.tmpl"), []byte(`Custom {{.File}} {{.Cards}} {{.Language}}{{template "format" .}}{{template "note" .}}`), 0600); err != nil {
+		t.Fatalf("Failed to create template: %v", err)
+	}
+	jobs, _ := planGeneration(files, false, 1000)
+
+	cfg := config.DefaultConfig()
+	cfg.Language, cfg.Cards = "German", 10
+	if err := choosePrompts(jobs, "code", cfg); err != nil {
+		t.Fatalf("choosePrompts() error = %v", err)
+	}
+	for _, job := range jobs {
+		var want string
+		var vars prompt.Data
+		switch filepath.Base(job.file) {
+		case "plain.md":
+			want, vars = "code", prompt.Data{File: "plain.md", Cards: 10, Language: "German"}
+		case "spanish.md":
+			want, vars = "vocabulary", prompt.Data{File: "spanish.md", Cards: 10, Language: "English"}
+		case "custom.md":
+			want, vars = filepath.Join(dir, "cards.tmpl"), prompt.Data{File: "custom.md", Cards: 3, Language: "German"}
+		}
+		if job.template.Name() != want || job.vars != vars {
+			t.Errorf("%s: expected template %q with %+v, got %q with %+v", filepath.Base(job.file), want, vars, job.template.Name(), job.vars)
+		}
+	}
+
+	if err := choosePrompts(nil, "trivia", cfg); err == nil {
+		t.Error("Expected an unknown template to be rejected even without jobs")
+	}
+	jobs[0].meta.Template = "missing.tmpl"
+	if err := choosePrompts(jobs, "", cfg); err == nil || !strings.Contains(err.Error(), jobs[0].file) {
+		t.Errorf("Expected the note with a missing template to be named, got %v", err)
+	}
+}
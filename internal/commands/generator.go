@@ -0,0 +1,121 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"catv/internal/cloze"
+	"catv/internal/llm"
+	"catv/internal/ollama"
+	"catv/internal/prompt"
+)
+
+// maxGenerateAttempts is how many times a chunk is sent to the model
+// before a malformed response is reported as an error
+const maxGenerateAttempts = 3
+
+// cardGenerator asks the model for flashcards, preferring structured JSON output
+// and switching to the Q:/A: line format for the rest of the run once the
+// server rejects the JSON schema
+// It is safe for concurrent use by the generation workers
+type cardGenerator struct {
+	generate   generateFunc
+	lineFormat atomic.Bool // Set once structured output turned out to be unsupported
+}
+
+func newCardGenerator(generate generateFunc) *cardGenerator {
+	return &cardGenerator{generate: generate}
+}
+
+// flashcards generates the flashcards for one chunk of a section, described
+// by data, with the prompt rendered from tmpl (the default template if nil)
+// If preview is not nil it receives the cards complete so far while the model
+// is still answering, and nil whenever an attempt starts over
+// Malformed responses are retried; other errors are returned immediately
+func (g *cardGenerator) flashcards(ctx context.Context, tmpl *prompt.Template, data prompt.Data, preview func([]ollama.GeneratedCard)) ([]ollama.GeneratedCard, error) {
+	if tmpl == nil {
+		tmpl = prompt.Default()
+	}
+	var lastErr error
+	for attempt := 0; attempt < maxGenerateAttempts; {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		data.Structured = !g.lineFormat.Load()
+		text, err := tmpl.Render(data)
+		if err != nil {
+			return nil, err
+		}
+		structured := data.Structured
+		var onToken func(string)
+		if preview != nil {
+			preview(nil)
+			onToken = streamCards(structured, preview)
+		}
+		resp, err := g.generate(ctx, text, structured, onToken)
+		if structured && errors.Is(err, llm.ErrStructuredUnsupported) {
+			// Retry the same chunk in line format without using up an attempt
+			g.lineFormat.Store(true)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		cards, err := parseResponse(resp, structured)
+		if err == nil {
+			return cards, nil
+		}
+		lastErr = err
+		attempt++
+	}
+	return nil, fmt.Errorf("giving up after %d attempts: %w", maxGenerateAttempts, lastErr)
+}
+
+// streamCards returns a token callback that collects the response and passes
+// the cards complete so far to preview whenever another one is finished
+// The response is only parsed again when a token may end a card, a closing
+// brace in JSON or a line break in the line format
+func streamCards(structured bool, preview func([]ollama.GeneratedCard)) func(string) {
+	var response strings.Builder
+	shown := 0
+	end := "\n"
+	if structured {
+		end = "}"
+	}
+	return func(token string) {
+		response.WriteString(token)
+		if !strings.Contains(token, end) {
+			return
+		}
+		if cards := ollama.PartialCards(response.String(), structured); len(cards) != shown {
+			shown = len(cards)
+			preview(cards)
+		}
+	}
+}
+
+// parseResponse decodes a model response in the format it was requested in
+func parseResponse(resp string, structured bool) ([]ollama.GeneratedCard, error) {
+	if structured {
+		return ollama.ParseGeneratedCards(resp)
+	}
+	qas, err := ollama.ParseFlashcards(resp)
+	if err != nil {
+		return nil, err
+	}
+	if len(qas) == 0 {
+		return nil, fmt.Errorf("%w: no Q:/A: pairs found", ollama.ErrMalformedResponse)
+	}
+	cards := make([]ollama.GeneratedCard, len(qas))
+	for i, qa := range qas {
+		cards[i] = ollama.GeneratedCard{Type: ollama.BasicCard, Question: qa["question"], Answer: qa["answer"]}
+		if cloze.Has(qa["question"]) {
+			cards[i].Type = ollama.ClozeCard
+		}
+	}
+	return cards, nil
+}
@@ -0,0 +1,170 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"catv/internal/llm"
+	"catv/internal/ollama"
+	"catv/internal/prompt"
+)
+
+func TestCardGenerator_Structured(t *testing.T) {
+	var calls int32
+	gen := newCardGenerator(func(ctx context.Context, prompt string, structured bool, onToken func(string)) (string, error) {
+		atomic.AddInt32(&calls, 1)
+		if !structured || !strings.Contains(prompt, "JSON") {
+			t.Errorf("Expected a structured request, got structured=%v", structured)
+		}
+		return `{"flashcards": [{"question": "What does defer do?", "answer": "Runs a call when\nthe function returns", "tags": ["go"]}]}`, nil
+	})
+
+	cards, err := gen.flashcards(context.Background(), nil, prompt.Data{Heading: "Go", Content: "content"}, nil)
+	if err != nil {
+		t.Fatalf("flashcards() error = %v", err)
+	}
+	if calls != 1 || len(cards) != 1 || cards[0].Answer != "Runs a call when\nthe function returns" {
+		t.Errorf("Unexpected result after %d calls: %+v", calls, cards)
+	}
+}
+
+func TestCardGenerator_Preview(t *testing.T) {
+	attempts := 0
+	gen := newCardGenerator(func(ctx context.Context, prompt string, structured bool, onToken func(string)) (string, error) {
+		attempts++
+		resp := "not json at all"
+		if attempts > 1 {
+			resp = `{"flashcards": [{"question": "A?", "answer": "a"}, {"question": "B?", "answer": "b"}]}`
+		}
+		for _, token := range strings.SplitAfter(resp, " ") {
+			onToken(token)
+		}
+		return resp, nil
+	})
+
+	var previews []int
+	var latest string
+	cards, err := gen.flashcards(context.Background(), nil, prompt.Data{Content: "content"}, func(cards []ollama.GeneratedCard) {
+		previews = append(previews, len(cards))
+		if len(cards) > 0 {
+			latest = cards[len(cards)-1].Question
+		}
+	})
+	if err != nil || len(cards) != 2 {
+		t.Fatalf("flashcards() = %+v, %v", cards, err)
+	}
+	// Each attempt starts from an empty preview, then cards appear one at a time
+	if want := []int{0, 0, 1, 2}; !slices.Equal(previews, want) {
+		t.Errorf("Previews = %v, expected %v", previews, want)
+	}
+	if latest != "B?" {
+		t.Errorf("Latest previewed question = %q", latest)
+	}
+}
+
+func TestStreamCards_LineFormat(t *testing.T) {
+	var previews [][]ollama.GeneratedCard
+	onToken := streamCards(false, func(cards []ollama.GeneratedCard) {
+		previews = append(previews, cards)
+	})
+	for _, r := range "Q: A?\nA: a\nQ: B?\nA: b" {
+		onToken(string(r))
+	}
+	// A card is complete once the next question's line has ended; the last
+	// one is left to the parse of the full response
+	if len(previews) != 1 || len(previews[0]) != 1 || previews[0][0].Question != "A?" {
+		t.Errorf("Previews = %+v, expected only card A?", previews)
+	}
+}
+
+func TestCardGenerator_FallsBackToLineFormat(t *testing.T) {
+	var structuredCalls, lineCalls int32
+	gen := newCardGenerator(func(ctx context.Context, prompt string, structured bool, onToken func(string)) (string, error) {
+		if structured {
+			atomic.AddInt32(&structuredCalls, 1)
+			return "", llm.ErrStructuredUnsupported
+		}
+		atomic.AddInt32(&lineCalls, 1)
+		return "Q: What is Go?\nA: A language", nil
+	})
+
+	for range 2 {
+		cards, err := gen.flashcards(context.Background(), nil, prompt.Data{Content: "content"}, nil)
+		if err != nil {
+			t.Fatalf("flashcards() error = %v", err)
+		}
+		if len(cards) != 1 || cards[0].Question != "What is Go?" {
+			t.Errorf("Unexpected cards: %+v", cards)
+		}
+	}
+	// The unsupported schema is only tried once per run
+	if structuredCalls != 1 || lineCalls != 2 {
+		t.Errorf("Expected 1 structured and 2 line requests, got %d and %d", structuredCalls, lineCalls)
+	}
+}
+
+func TestCardGenerator_RetriesMalformed(t *testing.T) {
+	responses := []string{"not json", `{"flashcards": [{"question": "", "answer": ""}]}`, `{"flashcards": [{"question": "Q", "answer": "A"}]}`}
+	var calls int32
+	gen := newCardGenerator(func(ctx context.Context, prompt string, structured bool, onToken func(string)) (string, error) {
+		n := atomic.AddInt32(&calls, 1)
+		return responses[n-1], nil
+	})
+	cards, err := gen.flashcards(context.Background(), nil, prompt.Data{Content: "content"}, nil)
+	if err != nil || len(cards) != 1 || calls != 3 {
+		t.Errorf("Expected success on the third attempt, got %d calls, %v, %v", calls, cards, err)
+	}
+
+	calls = 0
+	gen = newCardGenerator(func(ctx context.Context, prompt string, structured bool, onToken func(string)) (string, error) {
+		atomic.AddInt32(&calls, 1)
+		return "Sure! Here are some flashcards.", nil
+	})
+	_, err = gen.flashcards(context.Background(), nil, prompt.Data{Content: "content"}, nil)
+	if !errors.Is(err, ollama.ErrMalformedResponse) || calls != maxGenerateAttempts {
+		t.Errorf("Expected a malformed response error after %d attempts, got %d calls and %v", maxGenerateAttempts, calls, err)
+	}
+}
+
+func TestCardGenerator_RequestErrorsAreNotRetried(t *testing.T) {
+	var calls int32
+	gen := newCardGenerator(func(ctx context.Context, prompt string, structured bool, onToken func(string)) (string, error) {
+		atomic.AddInt32(&calls, 1)
+		return "", errors.New("connection refused")
+	})
+	if _, err := gen.flashcards(context.Background(), nil, prompt.Data{Content: "content"}, nil); err == nil || calls != 1 {
+		t.Errorf("Expected the request error to be returned at once, got %d calls and %v", calls, err)
+	}
+}
+
+func TestCardGenerator_Template(t *testing.T) {
+	tmpl, err := prompt.Load("vocabulary")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	var prompts []string
+	gen := newCardGenerator(func(ctx context.Context, p string, structured bool, onToken func(string)) (string, error) {
+		prompts = append(prompts, p)
+		if structured {
+			return "", llm.ErrStructuredUnsupported
+		}
+		return "Q: perro\nA: dog", nil
+	})
+
+	cards, err := gen.flashcards(context.Background(), tmpl, prompt.Data{Content: "perro", Language: "English"}, nil)
+	if err != nil || len(cards) != 1 {
+		t.Fatalf("flashcards() = %+v, %v", cards, err)
+	}
+	if len(prompts) != 2 || !strings.Contains(prompts[0], `"flashcards" array`) || !strings.Contains(prompts[1], "Q: <question>") {
+		t.Fatalf("Expected a structured then a line format prompt, got %q", prompts)
+	}
+	for _, p := range prompts {
+		if !strings.Contains(p, "vocabulary flashcards") || !strings.Contains(p, "meaning in English") {
+			t.Errorf("Expected the vocabulary template with its variables, got %q", p)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"catv/internal/importer"
+	"catv/internal/security"
+	"catv/internal/store"
+	"catv/internal/tui"
+
+	"github.com/spf13/cobra"
+)
+
+// Import formats accepted by --from
+const (
+	importAnki = "anki"
+	importCSV  = "csv"
+	importTSV  = "tsv"
+)
+
+var ImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import flashcards from Anki packages or CSV/TSV files",
+	Long: `Import flashcards from an Anki package (.apkg) or a CSV/TSV file.
+
+Anki cards keep their scheduling, so reviews continue where they left off, and
+go into a deck named after their Anki deck; review them with
+catv review --deck <name>. Cloze notes become cloze flashcards. Packages must be exported with "Support older Anki
+versions" checked.
+
+CSV and TSV files hold one flashcard per row. Choose the columns with
+--question-col, --answer-col and --tags-col, by 1-based number or, with
+--header, by header name. Cards go into a deck named after the imported file.
+
+Importing the same file again only adds flashcards that are not there yet.`,
+	Example: `  catv import --from anki Spanish.apkg
+  catv import --from csv words.csv --header --question-col front --answer-col back
+  catv import notes.tsv --question-col 2 --answer-col 3 --tags-col 4`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		path := args[0]
+		if err := security.ValidateFilePath(path); err != nil {
+			tui.PrintError("Invalid file path:", err)
+			os.Exit(1)
+		}
+		from, _ := cmd.Flags().GetString("from")
+		format, err := importFormat(path, from)
+		if err != nil {
+			tui.PrintError("Cannot import:", err)
+			os.Exit(1)
+		}
+
+		flashcards, err := readImport(cmd, path, format)
+		if err != nil {
+			tui.PrintError("Import failed:", err)
+			os.Exit(1)
+		}
+		added, skipped, err := importFlashcards(flashcards)
+		if err != nil {
+			tui.PrintError("Failed to save imported flashcards:", err)
+			os.Exit(1)
+		}
+		if skipped > 0 {
+			tui.PrintInfo(fmt.Sprintf("Skipped %d flashcards that were already imported", skipped))
+		}
+		tui.PrintSuccess(fmt.Sprintf("Imported %d flashcards from %s", added, path))
+	},
+}
+
+// importFormat returns the format named by --from, or guesses it from the
+// file extension when the flag is not set
+func importFormat(path, from string) (string, error) {
+	if from == "" {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".apkg":
+			return importAnki, nil
+		case ".tsv", ".txt":
+			return importTSV, nil
+		case ".csv":
+			return importCSV, nil
+		}
+		return "", fmt.Errorf("cannot tell the format of %s; use --from anki, csv or tsv", path)
+	}
+	switch from = strings.ToLower(from); from {
+	case importAnki, importCSV, importTSV:
+		return from, nil
+	}
+	return "", fmt.Errorf("unknown format %q; use anki, csv or tsv", from)
+}
+
+// readImport reads the flashcards of the file at path in the given format
+func readImport(cmd *cobra.Command, path, format string) ([]store.Flashcard, error) {
+	if format == importAnki {
+		return importer.ReadAnki(path)
+	}
+
+	opts := importer.CSVOptions{Comma: ','}
+	if format == importTSV {
+		opts.Comma = '\t'
+	}
+	opts.Header, _ = cmd.Flags().GetBool("header")
+	opts.Question, _ = cmd.Flags().GetString("question-col")
+	opts.Answer, _ = cmd.Flags().GetString("answer-col")
+	opts.Tags, _ = cmd.Flags().GetString("tags-col")
+
+	origin, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	return importer.ReadCSV(f, origin, opts)
+}
+
+// importFlashcards saves the flashcards that are not in the database yet,
+// returning how many were added and how many were already there
+func importFlashcards(flashcards []store.Flashcard) (int, int, error) {
+	var existing []store.Flashcard
+	seen := make(map[string]bool)
+	for _, fc := range flashcards {
+		if seen[fc.File] {
+			continue
+		}
+		seen[fc.File] = true
+		cards, err := Store.GetFlashcardsByFile(fc.File)
+		if err != nil {
+			return 0, 0, err
+		}
+		existing = append(existing, cards...)
+	}
+
+	fresh := importer.Deduplicate(flashcards, existing)
+	if err := Store.InsertFlashcards(fresh); err != nil {
+		return 0, 0, err
+	}
+	return len(fresh), len(flashcards) - len(fresh), nil
+}
+
+func init() {
+	ImportCmd.Flags().String("from", "", "Format of the file: anki, csv or tsv (guessed from the extension by default)")
+	ImportCmd.Flags().String("question-col", "1", "CSV/TSV column holding the question")
+	ImportCmd.Flags().String("answer-col", "2", "CSV/TSV column holding the answer")
+	ImportCmd.Flags().String("tags-col", "", "CSV/TSV column holding space or comma separated tags")
+	ImportCmd.Flags().Bool("header", false, "The first CSV/TSV row names the columns")
+}
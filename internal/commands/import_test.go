@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"testing"
+
+	"catv/internal/store"
+)
+
+func TestImportFormat(t *testing.T) {
+	tests := []struct {
+		path, from, want string
+	}{
+		{"deck.apkg", "", importAnki},
+		{"words.CSV", "", importCSV},
+		{"export.txt", "", importTSV},
+		{"words.txt", "CSV", importCSV},
+	}
+	for _, tt := range tests {
+		if got, err := importFormat(tt.path, tt.from); err != nil || got != tt.want {
+			t.Errorf("importFormat(%q, %q) = %q, %v, want %q", tt.path, tt.from, got, err, tt.want)
+		}
+	}
+	if _, err := importFormat("notes.md", ""); err == nil {
+		t.Error("Expected an error for an unknown extension")
+	}
+	if _, err := importFormat("deck.apkg", "mnemosyne"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
+
+func TestImportFlashcards(t *testing.T) {
+	useTestStore(t)
+
+	cards := []store.Flashcard{
+		{File: "anki:Spanish", Question: "perro", Answer: "dog"},
+		{File: "anki:Spanish", Question: "gato", Answer: "cat"},
+		{File: "/notes/words.csv", Question: "perro", Answer: "dog"},
+	}
+	added, skipped, err := importFlashcards(cards)
+	if err != nil || added != 3 || skipped != 0 {
+		t.Fatalf("importFlashcards() = %d, %d, %v, want 3 added", added, skipped, err)
+	}
+
+	// Importing again only adds the new flashcard
+	cards = append(cards, store.Flashcard{File: "anki:Spanish", Question: "pájaro", Answer: "bird"})
+	added, skipped, err = importFlashcards(cards)
+	if err != nil || added != 1 || skipped != 3 {
+		t.Fatalf("importFlashcards() = %d, %d, %v, want 1 added and 3 skipped", added, skipped, err)
+	}
+
+	files, err := Store.GetUniqueFiles()
+	if err != nil || len(files) != 2 || files[1] != "anki:Spanish" {
+		t.Errorf("Expected the origins to be listed as files, got %q", files)
+	}
+}
@@ -0,0 +1,93 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"catv/internal/markdown"
+	"catv/internal/store"
+)
+
+// sectionPlan describes what has to be regenerated for a source file
+type sectionPlan struct {
+	changed []markdown.Section // Sections that are new or whose content changed
+	removed map[string]bool    // Headings of recorded sections that no longer exist
+	current map[string]bool    // Headings of every section currently in the file
+}
+
+// planSections compares the sections of a file with the recorded fingerprints
+// When force is set every section is regenerated and all recorded sections are replaced
+func planSections(sections []markdown.Section, stored store.SourceHashes, force bool) sectionPlan {
+	plan := sectionPlan{removed: make(map[string]bool), current: make(map[string]bool, len(sections))}
+	for _, sec := range sections {
+		plan.current[sec.Heading] = true
+		if force || stored.Sections[sec.Heading] != sec.Hash {
+			plan.changed = append(plan.changed, sec)
+		}
+	}
+	for heading := range stored.Sections {
+		if !plan.current[heading] {
+			plan.removed[heading] = true
+		}
+	}
+	return plan
+}
+
+// supersededFlashcards returns the flashcards made obsolete by regenerating a file:
+// those from removed sections and the previous cards of regenerated sections
+// With force, cards that match no current section are replaced too, which covers
+// flashcards generated before sections were tracked
+func supersededFlashcards(existing []store.Flashcard, plan sectionPlan, regenerated map[string]bool, force bool) []store.Flashcard {
+	var superseded []store.Flashcard
+	for _, fc := range existing {
+		if plan.removed[fc.Heading] || regenerated[fc.Heading] || (force && !plan.current[fc.Heading]) {
+			superseded = append(superseded, fc)
+		}
+	}
+	return superseded
+}
+
+// recordedHashes builds the fingerprints to store after generating a file
+// Sections that failed keep their previous fingerprint so they are retried next
+// time, and the file fingerprint is only stored once every section succeeded
+func recordedHashes(file, fileHash string, sections []markdown.Section, stored store.SourceHashes, failed map[string]bool) store.SourceHashes {
+	src := store.SourceHashes{File: file, Hash: fileHash, Sections: make(map[string]string, len(sections))}
+	for _, sec := range sections {
+		if !failed[sec.Heading] {
+			src.Sections[sec.Heading] = sec.Hash
+			continue
+		}
+		src.Hash = ""
+		if old, ok := stored.Sections[sec.Heading]; ok {
+			src.Sections[sec.Heading] = old
+		}
+	}
+	return src
+}
+
+// missingSourceFiles returns recorded source files below root that no longer exist
+func missingSourceFiles(recorded []string, root string, seen map[string]bool) []string {
+	var missing []string
+	prefix := strings.TrimSuffix(root, string(filepath.Separator)) + string(filepath.Separator)
+	for _, file := range recorded {
+		if seen[file] || (file != root && !strings.HasPrefix(file, prefix)) {
+			continue
+		}
+		if _, err := os.Stat(file); os.IsNotExist(err) {
+			missing = append(missing, file)
+		}
+	}
+	return missing
+}
+
+// confirm asks a yes/no question, defaulting to no
+func confirm(in io.Reader, out io.Writer, question string) bool {
+	_, _ = fmt.Fprintf(out, "%s [y/N]: ", question)
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,132 @@
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"catv/internal/markdown"
+	"catv/internal/store"
+)
+
+func TestPlanSections(t *testing.T) {
+	sections := markdown.Split("# A\nsame\n# B\nedited\n# C\nnew")
+	stored := store.SourceHashes{Sections: map[string]string{
+		"A": markdown.Hash("# A\nsame"),
+		"B": markdown.Hash("# B\noriginal"),
+		"D": markdown.Hash("# D\ngone"),
+	}}
+
+	plan := planSections(sections, stored, false)
+	var changed []string
+	for _, sec := range plan.changed {
+		changed = append(changed, sec.Heading)
+	}
+	if strings.Join(changed, ",") != "B,C" {
+		t.Errorf("Expected sections B and C to change, got %v", changed)
+	}
+	if len(plan.removed) != 1 || !plan.removed["D"] {
+		t.Errorf("Expected section D to be removed, got %v", plan.removed)
+	}
+
+	forced := planSections(sections, stored, true)
+	if len(forced.changed) != 3 {
+		t.Errorf("Expected --force to regenerate all 3 sections, got %d", len(forced.changed))
+	}
+}
+
+func TestSupersededFlashcards(t *testing.T) {
+	existing := []store.Flashcard{
+		{ID: 1, Heading: "A"}, // unchanged
+		{ID: 2, Heading: "B"}, // regenerated
+		{ID: 3, Heading: "D"}, // section removed
+		{ID: 4, Heading: ""},  // generated before sections were tracked
+	}
+	plan := sectionPlan{
+		removed: map[string]bool{"D": true},
+		current: map[string]bool{"A": true, "B": true},
+	}
+
+	ids := func(cards []store.Flashcard) []int {
+		out := make([]int, len(cards))
+		for i, fc := range cards {
+			out[i] = fc.ID
+		}
+		return out
+	}
+
+	got := ids(supersededFlashcards(existing, plan, map[string]bool{"B": true}, false))
+	if len(got) != 2 || got[0] != 2 || got[1] != 3 {
+		t.Errorf("Expected flashcards 2 and 3 to be superseded, got %v", got)
+	}
+
+	got = ids(supersededFlashcards(existing, plan, map[string]bool{"A": true, "B": true}, true))
+	if len(got) != 4 {
+		t.Errorf("Expected --force to supersede every flashcard, got %v", got)
+	}
+}
+
+func TestRecordedHashes(t *testing.T) {
+	sections := []markdown.Section{
+		{Heading: "A", Hash: "a2"},
+		{Heading: "B", Hash: "b2"},
+		{Heading: "C", Hash: "c1"},
+	}
+	stored := store.SourceHashes{Sections: map[string]string{"A": "a1", "B": "b1"}}
+
+	src := recordedHashes("/notes.md", "file2", sections, stored, nil)
+	if src.Hash != "file2" || src.Sections["A"] != "a2" || len(src.Sections) != 3 {
+		t.Errorf("Expected every new hash to be recorded, got %+v", src)
+	}
+
+	src = recordedHashes("/notes.md", "file2", sections, stored, map[string]bool{"B": true, "C": true})
+	if src.Hash != "" {
+		t.Error("File hash should not be recorded when a section failed")
+	}
+	if src.Sections["A"] != "a2" || src.Sections["B"] != "b1" {
+		t.Errorf("Failed sections should keep their previous hash, got %+v", src.Sections)
+	}
+	if _, ok := src.Sections["C"]; ok {
+		t.Error("A new section that failed should not be recorded")
+	}
+}
+
+func TestMissingSourceFiles(t *testing.T) {
+	root := t.TempDir()
+	kept := filepath.Join(root, "kept.md")
+	if err := os.WriteFile(kept, []byte("# Kept"), 0600); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	deleted := filepath.Join(root, "deleted.md")
+	elsewhere := filepath.Join(root+"-other", "deleted.md")
+
+	missing := missingSourceFiles([]string{kept, deleted, elsewhere}, root, map[string]bool{kept: true})
+	if len(missing) != 1 || missing[0] != deleted {
+		t.Errorf("Expected only %s to be missing, got %v", deleted, missing)
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"y\n", true},
+		{"YES\n", true},
+		{"n\n", false},
+		{"\n", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		if got := confirm(strings.NewReader(tt.input), &out, "Archive?"); got != tt.expected {
+			t.Errorf("confirm(%q) = %v, expected %v", tt.input, got, tt.expected)
+		}
+		if !strings.Contains(out.String(), "Archive? [y/N]") {
+			t.Errorf("confirm should print the question, got %q", out.String())
+		}
+	}
+}
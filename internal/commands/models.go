@@ -0,0 +1,220 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"catv/internal/config"
+	"catv/internal/llm"
+	"catv/internal/security"
+	"catv/internal/tui"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// modelsTimeout bounds how long asking the server for its models may take
+const modelsTimeout = 10 * time.Second
+
+var ModelsCmd = &cobra.Command{
+	Use:   "models",
+	Short: "List and download the models of the model server",
+	Long: `List the models installed on the configured model server and, with Ollama,
+download new ones.
+
+catv generate checks that the model exists before sending any note to it, and
+when no model is configured it offers the installed models to choose from.`,
+	// Models do not need the database
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {},
+}
+
+var ModelsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the models installed on the model server",
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := loadConfig(cmd)
+		provider := modelServer(cfg)
+		models, err := listModels(provider)
+		if err != nil {
+			tui.PrintError("Could not list models:", err)
+			os.Exit(1)
+		}
+		if len(models) == 0 {
+			tui.PrintInfo(fmt.Sprintf("No models are installed on %s", cfg.OllamaURL))
+			return
+		}
+
+		current := ollamaModel(cfg)
+		fmt.Printf("  %-32s %-10s %-8s %-9s %s\n", "Name", "Params", "Quant", "Size", "Modified")
+		for _, m := range models {
+			marker := " "
+			if llm.HasModel([]llm.Model{m}, current) {
+				marker = "*"
+			}
+			size, modified := "", ""
+			if m.Size > 0 {
+				size = tui.FormatBytes(m.Size)
+			}
+			if !m.ModifiedAt.IsZero() {
+				modified = m.ModifiedAt.Format("2006-01-02")
+			}
+			fmt.Printf("%s %-32s %-10s %-8s %-9s %s\n", marker, m.Name, m.ParameterSize, m.Quantization, size, modified)
+		}
+	},
+}
+
+var ModelsPullCmd = &cobra.Command{
+	Use:   "pull [model]",
+	Short: "Download a model with Ollama, the configured model by default",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := loadConfig(cmd)
+		ollamaServer, ok := modelServer(cfg).(*llm.Ollama)
+		if !ok {
+			tui.PrintError("Cannot pull models:", fmt.Errorf("only the Ollama providers can download models, not %q", cfg.Provider))
+			os.Exit(1)
+		}
+		name := ollamaModel(cfg)
+		if len(args) > 0 {
+			name = args[0]
+		}
+		if err := pullModel(ollamaServer, name); err != nil {
+			tui.PrintError("Could not pull "+name+":", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// modelServer returns the configured provider, exiting if the provider or the
+// server URL is invalid
+func modelServer(cfg *config.Config) llm.LLMProvider {
+	if err := security.ValidateURL(cfg.OllamaURL); err != nil {
+		tui.PrintError("Invalid model server URL:", err)
+		os.Exit(1)
+	}
+	provider, err := newProvider(cfg)
+	if err != nil {
+		tui.PrintError("Invalid provider:", err)
+		os.Exit(1)
+	}
+	return provider
+}
+
+// listModels returns the models of the server behind provider
+func listModels(provider llm.LLMProvider) ([]llm.Model, error) {
+	lister, ok := provider.(llm.ModelLister)
+	if !ok {
+		return nil, fmt.Errorf("the %s provider cannot list models", provider.Name())
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), modelsTimeout)
+	defer cancel()
+	return lister.Models(ctx)
+}
+
+// checkModel verifies that the server behind provider has model, so a typo is
+// reported once rather than as a failed request for every note
+// Providers that cannot list their models are trusted
+func checkModel(provider llm.LLMProvider, model string) error {
+	if _, ok := provider.(llm.ModelLister); !ok {
+		return nil
+	}
+	models, err := listModels(provider)
+	if err != nil {
+		return fmt.Errorf("could not reach the model server: %w", err)
+	}
+	if llm.HasModel(models, model) {
+		return nil
+	}
+	hint := ""
+	if _, ok := provider.(*llm.Ollama); ok {
+		hint = fmt.Sprintf("; run catv models pull %s to download it", model)
+	}
+	if len(models) == 0 {
+		return fmt.Errorf("model %q is not available, the server has no models%s", model, hint)
+	}
+	return fmt.Errorf("model %q is not available (installed: %s)%s", model, strings.Join(llm.ModelNames(models), ", "), hint)
+}
+
+// modelConfigured reports whether a model was chosen with --model, an
+// environment variable or a configuration file
+func modelConfigured(cfg *config.Config) bool {
+	return Model != "" || cfg.Source("ollama.model") != "default"
+}
+
+// pickModel lets the user choose one of the installed models and saves it as
+// ollama.model in the user configuration
+// It returns "" when the picker cannot be shown, and errPickerCancelled when the
+// user quits it
+func pickModel(provider llm.LLMProvider, current string) (string, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) { // #nosec G115 -- file descriptors fit in an int
+		return "", nil
+	}
+	models, err := listModels(provider)
+	if err != nil || len(models) == 0 {
+		// Left to checkModel to report
+		return "", nil
+	}
+
+	picker := tui.NewModelPickerModel(models, current)
+	if _, err := tea.NewProgram(picker).Run(); err != nil {
+		return "", err
+	}
+	model := picker.Selected()
+	if model == "" {
+		return "", errPickerCancelled
+	}
+	if path := config.UserConfigPath(); path != "" {
+		if err := config.SetInFile(path, "ollama.model", model); err != nil {
+			tui.PrintError("Could not save the model:", err)
+		} else {
+			tui.PrintSuccess(fmt.Sprintf("Saved ollama.model = %s in %s", model, path))
+		}
+	}
+	return model, nil
+}
+
+// errPickerCancelled is returned when the user quits the model picker
+var errPickerCancelled = errors.New("no model was chosen")
+
+// pullModel downloads a model, showing its progress in a progress bar on a
+// terminal and as status lines otherwise
+func pullModel(server *llm.Ollama, name string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if !term.IsTerminal(int(os.Stdout.Fd())) { // #nosec G115 -- file descriptors fit in an int
+		last := ""
+		return server.Pull(ctx, name, func(p llm.PullProgress) {
+			if p.Status != last {
+				fmt.Println(p.Status)
+				last = p.Status
+			}
+		})
+	}
+
+	model := tui.NewPullModel(name)
+	p := tea.NewProgram(model)
+	go func() {
+		err := server.Pull(ctx, name, func(update llm.PullProgress) {
+			p.Send(tui.PullProgressMsg(update))
+		})
+		p.Send(tui.PullDoneMsg{Err: err})
+	}()
+	if _, err := p.Run(); err != nil {
+		return err
+	}
+	if model.Interrupted() {
+		return context.Canceled
+	}
+	return model.Err()
+}
+
+func init() {
+	ModelsCmd.PersistentFlags().StringP("path", "p", ".", "Notes file or folder whose .catv.toml applies")
+	ModelsCmd.AddCommand(ModelsListCmd, ModelsPullCmd)
+}
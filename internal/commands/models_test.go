@@ -0,0 +1,70 @@
+package commands
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"catv/internal/config"
+	"catv/internal/llm"
+)
+
+func TestCheckModel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/models" {
+			_, _ = w.Write([]byte(`{"data":[{"id":"qwen2.5-7b-instruct"}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"models":[{"name":"llama3.1:latest"},{"name":"qwen2.5:7b"}]}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		provider llm.LLMProvider
+		model    string
+		wantErr  string
+	}{
+		{"installed", llm.NewOllama(server.URL), "llama3.1", ""},
+		{"missing", llm.NewOllama(server.URL), "mistral", `model "mistral" is not available (installed: llama3.1:latest, qwen2.5:7b); run catv models pull mistral`},
+		{"missing without pull", llm.NewOpenAI(server.URL, ""), "mistral", `model "mistral" is not available (installed: qwen2.5-7b-instruct)`},
+		{"unreachable", llm.NewOllama("http://127.0.0.1:1"), "llama3.1", "could not reach the model server"},
+		{"cannot list", &llm.Fake{}, "anything", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkModel(tt.provider, tt.model)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkModel() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("checkModel() error = %v, expected %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestModelConfigured(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("CATV_MODEL", "")
+	oldModel := Model
+	defer func() { Model = oldModel }()
+
+	Model = ""
+	if modelConfigured(config.Load(t.TempDir())) {
+		t.Error("The default model should not count as configured")
+	}
+	Model = "qwen2.5:7b"
+	if !modelConfigured(config.Load(t.TempDir())) {
+		t.Error("--model should count as configured")
+	}
+	Model = ""
+	t.Setenv("CATV_MODEL", "qwen2.5:7b")
+	if !modelConfigured(config.Load(t.TempDir())) {
+		t.Error("CATV_MODEL should count as configured")
+	}
+}
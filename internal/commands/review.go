@@ -1,81 +1,286 @@
 package commands
 
 import (
+	"catv/internal/config"
+	"catv/internal/llm"
+	"catv/internal/ollama"
+	"catv/internal/scheduler"
+	"catv/internal/security"
+	"catv/internal/store"
 	"catv/internal/tui"
+	"context"
+	"errors"
 	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 var ReviewCmd = &cobra.Command{
 	Use:   "review",
 	Short: "Review flashcards",
+	Long: `Review the flashcards that are due in the selected decks and tags.
+
+Without --deck or --tag a selector lists every deck and tag with its number of
+due cards. Selecting both decks and tags reviews the cards in those decks that
+carry one of the tags. --deck, --tag, --file and --all skip the selector, so
+a session can be started from a shell alias or a key binding:
+
+  catv review --file ~/notes/go --limit 20 --new-limit 5 --shuffle
+
+--file takes source files or directories and can be repeated.
+
+Each day at most review.new_per_day cards never reviewed before (default 20) and
+review.reviews_per_day cards studied before (default 200) are shown, counting
+earlier sessions of the day. New cards are spread between the reviews, and the
+most overdue reviews come first unless --shuffle is given. --limit and
+--new-limit cap a single session further.
+
+Cards graded Again come back later in the session after each learning step
+in review.learning_steps (default "1m,10m"; "none" turns this off) until they
+are graded Good on the last step or Easy. Each question is shown for
+review.timer (default 30s) before its answer is revealed. These settings are
+read from the configuration files or their environment variables; see catv config.
+
+Each grade is saved as soon as it is given, so nothing is lost if the session
+is interrupted. Press u (esc while typing an answer) to undo the last grade.
+Review needs a terminal and fails straight away when stdout is not one.
+
+With --type the answer is typed before it is revealed. The typed answer is
+compared character by character with the stored one and a grade is suggested
+from how closely they match; press Enter to accept it or 1-4 to override it.
+
+With --ai-grade the answer is also typed, and the Ollama model judges it
+against the stored answer, suggesting a grade with a one-line explanation.
+If the model cannot be reached the character match is suggested instead.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		// Step 1: Get all unique files from database
-		allFiles, err := Store.GetUniqueFiles()
+		if !term.IsTerminal(int(os.Stdout.Fd())) { // #nosec G115 -- file descriptors fit in an int
+			tui.PrintError("Cannot review flashcards:", errNotTerminal)
+			os.Exit(1)
+		}
+		limit, _ := cmd.Flags().GetInt("limit")
+		newLimit, _ := cmd.Flags().GetInt("new-limit")
+		if limit < 0 || newLimit < 0 {
+			tui.PrintError("Invalid limit:", errors.New("--limit and --new-limit cannot be negative"))
+			return
+		}
+
+		cfg := loadConfig(cmd)
+		sched, err := newScheduler(cfg)
 		if err != nil {
-			tui.PrintError("Failed to get files from database:", err)
+			tui.PrintError("Invalid scheduler:", err)
 			return
 		}
+		aiGrade, _ := cmd.Flags().GetBool("ai-grade")
+		var provider llm.LLMProvider
+		if aiGrade {
+			if err := security.ValidateURL(cfg.OllamaURL); err != nil {
+				tui.PrintError("Invalid model server URL:", err)
+				return
+			}
+			if provider, err = newProvider(cfg); err != nil {
+				tui.PrintError("Invalid provider:", err)
+				return
+			}
+		}
 
-		if len(allFiles) == 0 {
-			tui.PrintInfo("No files found in the database. Generate flashcards first.")
+		sel, err := reviewSelection(cmd)
+		if err != nil {
+			tui.PrintError("Invalid file:", err)
 			return
 		}
+		if all, _ := cmd.Flags().GetBool("all"); !all && len(sel.Decks) == 0 && len(sel.Tags) == 0 && len(sel.Files) == 0 {
+			// Step 1: Get decks and tags with their due counts from database
+			deckCounts, err := Store.GetDeckCounts()
+			if err != nil {
+				tui.PrintError("Failed to get decks from database:", err)
+				return
+			}
+			if len(deckCounts) == 0 {
+				tui.PrintInfo("No decks found in the database. Generate flashcards first.")
+				return
+			}
+			tagCounts, err := Store.GetTagCounts()
+			if err != nil {
+				tui.PrintError("Failed to get tags from database:", err)
+				return
+			}
 
-		// Step 2: Show file selector UI
-		fileSelector := tui.NewFileSelectorModel(allFiles)
-		p := tea.NewProgram(fileSelector)
-		if _, err := p.Run(); err != nil {
-			fmt.Println("Error running file selector:", err)
+			// Step 2: Show deck and tag selector UI
+			selector := tui.NewDeckSelectorModel(deckCounts, tagCounts)
+			if _, err := tea.NewProgram(selector).Run(); err != nil {
+				fmt.Println("Error running deck selector:", err)
+				return
+			}
+
+			// Step 3: Get selected decks and tags
+			sel.Decks, sel.Tags = selector.GetSelectedDecks(), selector.GetSelectedTags()
+			if len(sel.Decks) == 0 && len(sel.Tags) == 0 {
+				tui.PrintInfo("No decks or tags selected. See you next time!")
+				return
+			}
+		}
+
+		// Step 4: Get flashcards for selected decks and tags
+		flashcards, err := Store.GetFlashcardsForReviewBySelection(sel)
+		if err != nil {
+			tui.PrintError("DB query error:", err)
 			return
 		}
 
-		// Step 3: Get selected files
-		selectedFiles := fileSelector.GetSelectedFiles()
-		if len(selectedFiles) == 0 {
-			tui.PrintInfo("No files selected. See you next time!")
+		if len(flashcards) == 0 {
+			tui.PrintInfo("No flashcards due for review in the selected deck(s) and tag(s). Well done!")
 			return
 		}
+		// Cloze deletions of the same text would give each other away
+		flashcards = store.BurySiblings(flashcards)
 
-		// Step 4: Get flashcards for selected files
-		flashcards, err := Store.GetFlashcardsForReviewByFiles(selectedFiles)
+		// New and review cards are limited per day, counting earlier sessions
+		studied, err := Store.GetStudyDay(time.Now())
 		if err != nil {
 			tui.PrintError("DB query error:", err)
 			return
 		}
-
+		limits := scheduler.Limits{NewCards: cfg.NewCardsPerDay, Reviews: cfg.ReviewsPerDay}
+		flashcards = limitSession(scheduler.NewQueue(flashcards).Limit(limits, studied).Interleave(), limit, newLimit)
 		if len(flashcards) == 0 {
-			tui.PrintInfo("No flashcards due for review in the selected file(s). Well done!")
+			tui.PrintInfo(fmt.Sprintf("Daily limits reached: %d new and %d review card(s) studied today. See you tomorrow!",
+				studied.NewCards, studied.Reviews))
 			return
 		}
+		if shuffle, _ := cmd.Flags().GetBool("shuffle"); shuffle {
+			// #nosec G404 -- the review order does not need a secure random source
+			rand.Shuffle(len(flashcards), func(i, j int) {
+				flashcards[i], flashcards[j] = flashcards[j], flashcards[i]
+			})
+		}
 
 		// Step 5: Run Bubble Tea TUI for review
-		model := tui.NewReviewModel(flashcards)
-		p = tea.NewProgram(model)
+		// Grades are saved as they are given, so an interrupted session keeps them
+		opts := []tui.ReviewOption{tui.WithStore(Store), tui.WithLearningSteps(cfg.LearningSteps), tui.WithTimer(cfg.ReviewTimer)}
+		if aiGrade {
+			opts = append(opts, tui.WithAIGrader(newAIGrader(provider, ollamaModel(cfg))))
+		} else if typeAnswers, _ := cmd.Flags().GetBool("type"); typeAnswers {
+			opts = append(opts, tui.WithTypedAnswers())
+		}
+		model := tui.NewReviewModel(flashcards, sched, opts...)
+		p := tea.NewProgram(model)
 		if _, err := p.Run(); err != nil {
 			fmt.Println("Error running review TUI:", err)
 		}
 
-		// Step 6: After review, update DB only for flashcards that were actually answered
-		for i, fc := range flashcards {
-			if model.FlashcardWasCorrect(i) && model.FlashcardRevisitIn(i) > 0 {
-				fc.RevisitIn = model.FlashcardRevisitIn(i)
-				if err := Store.UpdateFlashcard(fc); err != nil {
-					tui.PrintError("DB update error:", err)
-				} else {
-					tui.PrintSuccess(fmt.Sprintf("Updated flashcard %d: revisitin=%d", fc.ID, fc.RevisitIn))
-				}
-			} else if !model.FlashcardWasCorrect(i) && model.FlashcardRevisitIn(i) > 0 {
-				// Incorrect -> revisit sooner (e.g. tomorrow => 1)
-				fc.RevisitIn = 1
-				if err := Store.UpdateFlashcard(fc); err != nil {
-					tui.PrintError("DB update error:", err)
-				} else {
-					tui.PrintSuccess(fmt.Sprintf("Marked flashcard %d incorrect: revisitin set to 1", fc.ID))
-				}
+		// Step 6: After review, list the flashcards that were graded and saved
+		for i := range flashcards {
+			grade := model.FlashcardGrade(i)
+			if grade == 0 || model.FlashcardReview(i).ID == 0 {
+				continue
 			}
+			fc := model.Flashcard(i)
+			tui.PrintSuccess(fmt.Sprintf("Graded flashcard %d %s: due %s", fc.ID, grade, fc.DueAt.Format("2006-01-02")))
+		}
+		if err := model.SaveError(); err != nil {
+			tui.PrintError("DB update error:", err)
 		}
 	},
 }
+
+// newScheduler returns the configured scheduler with its configured parameters
+func newScheduler(cfg *config.Config) (scheduler.Scheduler, error) {
+	sched, err := scheduler.New(cfg.Scheduler)
+	if err != nil {
+		return nil, err
+	}
+	switch s := sched.(type) {
+	case *scheduler.SM2:
+		s.HardFactor, s.EasyBonus = cfg.SM2HardFactor, cfg.SM2EasyBonus
+	case *scheduler.FSRS:
+		s.RequestRetention = cfg.FSRSRetention
+	}
+	return sched, nil
+}
+
+// errNotTerminal is reported when review is started without a terminal to draw on
+var errNotTerminal = errors.New("review is interactive and needs a terminal, but stdout is not a TTY")
+
+// reviewSelection returns the decks, tags and files given as flags, with files
+// made absolute as they are stored
+func reviewSelection(cmd *cobra.Command) (store.Selection, error) {
+	var sel store.Selection
+	sel.Decks, _ = cmd.Flags().GetStringSlice("deck")
+	sel.Tags, _ = cmd.Flags().GetStringSlice("tag")
+	files, _ := cmd.Flags().GetStringSlice("file")
+	for _, f := range files {
+		abs, err := filepath.Abs(f)
+		if err != nil {
+			return store.Selection{}, err
+		}
+		sel.Files = append(sel.Files, abs)
+	}
+	return sel, nil
+}
+
+// limitSession keeps at most newLimit flashcards that were never reviewed and at
+// most limit flashcards overall, in their due order; zero means no limit
+func limitSession(flashcards []store.Flashcard, limit, newLimit int) []store.Flashcard {
+	kept := make([]store.Flashcard, 0, len(flashcards))
+	newCards := 0
+	for _, fc := range flashcards {
+		if limit > 0 && len(kept) == limit {
+			break
+		}
+		if fc.IsNew() {
+			if newLimit > 0 && newCards == newLimit {
+				continue
+			}
+			newCards++
+		}
+		kept = append(kept, fc)
+	}
+	return kept
+}
+
+// judgeTimeout bounds how long the review waits for the model to grade an answer
+const judgeTimeout = time.Minute
+
+// ollamaModel returns the model flag if provided, otherwise the configured model
+func ollamaModel(cfg *config.Config) string {
+	if Model != "" {
+		return Model
+	}
+	return cfg.OllamaModel
+}
+
+// newProvider returns the configured model server provider
+func newProvider(cfg *config.Config) (llm.LLMProvider, error) {
+	return llm.New(cfg.Provider, cfg.OllamaURL, cfg.APIKey)
+}
+
+// newAIGrader asks the model behind provider to judge typed answers
+func newAIGrader(provider llm.LLMProvider, model string) tui.AnswerGrader {
+	return func(ctx context.Context, question, expected, typed string) (scheduler.Grade, string, error) {
+		ctx, cancel := context.WithTimeout(ctx, judgeTimeout)
+		defer cancel()
+		j, err := ollama.JudgeAnswer(ctx, provider, model, question, expected, typed)
+		if err != nil {
+			return 0, "", err
+		}
+		return scheduler.Grade(j.Grade), j.Explanation, nil
+	}
+}
+
+func init() {
+	ReviewCmd.Flags().Bool("type", false, "Type each answer and get a suggested grade from how closely it matches")
+	ReviewCmd.Flags().StringSlice("deck", nil, "Review these decks without showing the selector")
+	ReviewCmd.Flags().StringSlice("tag", nil, "Review cards with these tags without showing the selector")
+	ReviewCmd.Flags().StringSlice("file", nil, "Review cards from these source files or directories without showing the selector")
+	ReviewCmd.Flags().Bool("all", false, "Review every due card without showing the selector")
+	ReviewCmd.Flags().Int("limit", 0, "Maximum number of cards in the session (0 for no limit)")
+	ReviewCmd.Flags().Int("new-limit", 0, "Maximum number of never reviewed cards in the session (0 for no limit)")
+	ReviewCmd.Flags().Bool("shuffle", false, "Review the cards in random order")
+	ReviewCmd.Flags().Bool("ai-grade", false, "Type each answer and let the Ollama model suggest a grade")
+}
@@ -0,0 +1,102 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"catv/internal/config"
+	"catv/internal/llm"
+	"catv/internal/scheduler"
+	"catv/internal/store"
+)
+
+func TestNewAIGrader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req["model"] != "test-model" || !strings.Contains(req["prompt"].(string), "defer") {
+			t.Errorf("Unexpected request %v", req)
+		}
+		resp := `{"grade": 1, "explanation": "defer runs at function exit, not at the end of the block."}`
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"response": resp, "done": true})
+	}))
+	defer server.Close()
+
+	grade, explanation, err := newAIGrader(llm.NewOllama(server.URL), "test-model")(context.Background(), "When does defer run?", "When the function returns", "At the end of the block")
+	if err != nil {
+		t.Fatalf("grader error = %v", err)
+	}
+	if grade != scheduler.Again || !strings.HasPrefix(explanation, "defer runs") {
+		t.Errorf("Unexpected verdict %s %q", grade, explanation)
+	}
+
+	server.Close()
+	if _, _, err := newAIGrader(llm.NewOllama(server.URL), "test-model")(context.Background(), "Q", "A", "A"); err == nil {
+		t.Error("Expected an error when Ollama is unreachable")
+	}
+}
+
+func TestLimitSession(t *testing.T) {
+	reviewed := time.Now().AddDate(0, 0, -3)
+	cards := []store.Flashcard{
+		{ID: 1, LastReviewedAt: reviewed, Repetitions: 2},
+		{ID: 2},
+		{ID: 3},
+		{ID: 4, LastReviewedAt: reviewed},
+		{ID: 5},
+	}
+
+	tests := []struct {
+		name            string
+		limit, newLimit int
+		want            []int
+	}{
+		{"no limits", 0, 0, []int{1, 2, 3, 4, 5}},
+		{"limit", 3, 0, []int{1, 2, 3}},
+		{"new limit", 0, 1, []int{1, 2, 4}},
+		{"both", 2, 1, []int{1, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := limitSession(cards, tt.limit, tt.newLimit)
+			ids := make([]int, len(got))
+			for i, fc := range got {
+				ids[i] = fc.ID
+			}
+			if len(ids) != len(tt.want) {
+				t.Fatalf("limitSession() = %v, want %v", ids, tt.want)
+			}
+			for i := range ids {
+				if ids[i] != tt.want[i] {
+					t.Fatalf("limitSession() = %v, want %v", ids, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewScheduler(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.SM2HardFactor, cfg.FSRSRetention = 1.5, 0.8
+
+	sched, err := newScheduler(cfg)
+	if sm2, ok := sched.(*scheduler.SM2); err != nil || !ok || sm2.HardFactor != 1.5 || sm2.EasyBonus != cfg.SM2EasyBonus {
+		t.Errorf("Expected a tuned SM-2 scheduler, got %+v (%v)", sched, err)
+	}
+
+	cfg.Scheduler = scheduler.NameFSRS
+	sched, err = newScheduler(cfg)
+	if fsrs, ok := sched.(*scheduler.FSRS); err != nil || !ok || fsrs.RequestRetention != 0.8 {
+		t.Errorf("Expected a tuned FSRS scheduler, got %+v (%v)", sched, err)
+	}
+
+	cfg.Scheduler = "leitner"
+	if _, err := newScheduler(cfg); err == nil {
+		t.Error("Expected an unknown scheduler to be rejected")
+	}
+}
@@ -23,22 +23,8 @@ repetition. Simply point CATV at your folder of markdown notes, and it uses
 Ollama's local AI models to automatically generate flashcards and quiz you in 
 a colorful terminal interface.`,
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
-		// Load configuration
-		cfg := config.LoadConfig()
-
-		// Ensure data directory exists
-		if err := cfg.EnsureDataDir(); err != nil {
-			tui.PrintError("Could not create data directory:", err)
-			os.Exit(1)
-		}
-
-		// Initialize database
-		var err error
-		Store, err = store.NewStore(cfg.DatabasePath)
-		if err != nil {
-			tui.PrintError("Database initialization failed:", err)
-			os.Exit(1)
-		}
+		// Initialize database, applying any pending schema migrations
+		openDatabase(cmd, store.NewStore)
 	},
 	Run: func(cmd *cobra.Command, args []string) {
 		// Default to review command
@@ -51,6 +37,41 @@ a colorful terminal interface.`,
 	},
 }
 
+// loadConfig loads the configuration for the notes given with --path, so the
+// .catv.toml of a notes folder applies wherever catv is run from
+func loadConfig(cmd *cobra.Command) *config.Config {
+	path := "."
+	if f := cmd.Flags().Lookup("path"); f != nil && f.Value.String() != "" {
+		path = f.Value.String()
+	}
+	return config.Load(path)
+}
+
+// openDatabase loads the configuration and opens the flashcard database into Store
+// using open, exiting if the configuration is invalid or the database cannot be
+// initialized
+func openDatabase(cmd *cobra.Command, open func(dbName string) (*store.Store, error)) {
+	// Load configuration
+	cfg := loadConfig(cmd)
+	if err := cfg.Validate(); err != nil {
+		tui.PrintError("Invalid configuration:", err)
+		os.Exit(1)
+	}
+
+	// Ensure data directory exists
+	if err := cfg.EnsureDataDir(); err != nil {
+		tui.PrintError("Could not create data directory:", err)
+		os.Exit(1)
+	}
+
+	var err error
+	Store, err = open(cfg.DatabasePath)
+	if err != nil {
+		tui.PrintError("Database initialization failed:", err)
+		os.Exit(1)
+	}
+}
+
 func Execute() {
 	if err := RootCmd.Execute(); err != nil {
 		fmt.Println(err)
@@ -59,8 +80,14 @@ func Execute() {
 }
 
 func init() {
-	RootCmd.PersistentFlags().StringVar(&Model, "model", "llama3.1", "Ollama model to use for flashcard generation")
+	RootCmd.PersistentFlags().StringVar(&Model, "model", "", "Ollama model to use for flashcard generation (default from the configuration, llama3.1)")
 	RootCmd.AddCommand(GenerateCmd)
 	RootCmd.AddCommand(ReviewCmd)
 	RootCmd.AddCommand(AdminCmd)
+	RootCmd.AddCommand(StatsCmd)
+	RootCmd.AddCommand(DBCmd)
+	RootCmd.AddCommand(ImportCmd)
+	RootCmd.AddCommand(ExportCmd)
+	RootCmd.AddCommand(ConfigCmd)
+	RootCmd.AddCommand(ModelsCmd)
 }
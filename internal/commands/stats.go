@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"catv/internal/stats"
+	"catv/internal/tui"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+var statsJSON bool
+var statsDays int
+
+var StatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show review statistics, streaks and upcoming workload",
+	Long: `Show review statistics, streaks and upcoming workload.
+
+Accuracy and reviews per day count the reviews that scheduled a card. The
+extra reviews of a forgotten card while it is relearned in the same session
+are not counted.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if statsDays < 1 {
+			tui.PrintError("Invalid --days:", fmt.Errorf("must be at least 1, got %d", statsDays))
+			os.Exit(1)
+		}
+
+		cards, err := Store.GetAllFlashcards()
+		if err != nil {
+			tui.PrintError("DB query error:", err)
+			return
+		}
+		// The full history is needed to compute streaks and accuracy
+		reviews, err := Store.GetReviewsSince(time.Time{})
+		if err != nil {
+			tui.PrintError("DB query error:", err)
+			return
+		}
+		summary := stats.Compute(cards, reviews, time.Now(), statsDays)
+
+		if statsJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(summary); err != nil {
+				tui.PrintError("JSON encoding error:", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		if _, err := tea.NewProgram(tui.NewStatsModel(summary)).Run(); err != nil {
+			fmt.Println("Error running stats TUI:", err)
+		}
+	},
+}
+
+func init() {
+	StatsCmd.Flags().BoolVar(&statsJSON, "json", false, "Print statistics as JSON instead of opening the TUI")
+	StatsCmd.Flags().IntVar(&statsDays, "days", 84, "Number of days of review history to include")
+}
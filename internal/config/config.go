@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // Config holds all configuration for the CATV application
@@ -12,15 +15,58 @@ type Config struct {
 	// Database settings
 	DatabasePath string
 
-	// Ollama settings
-	OllamaURL      string
+	// Model server settings
+	Provider       string // "ollama", "ollama-chat", "openai" or "fake"
+	OllamaURL      string // address of the model server, whichever the provider
 	OllamaModel    string
-	RequestTimeout int // seconds
+	APIKey         string // sent to OpenAI-compatible servers that need one
+	RequestTimeout int    // seconds
+
+	// Generation settings
+	Concurrency      int            // files sent to the model in parallel
+	ChunkTokens      int            // token budget of a note chunk sent to the model
+	ModelChunkTokens map[string]int // per-model overrides of ChunkTokens
+	Prompt           string         // extra instructions added to the generation prompt
+	PromptTemplate   string         // built-in prompt template name or template file path
+	Language         string         // language to write flashcards in ("" for the note's language)
+	Difficulty       string         // level to pitch flashcards at ("" for any)
+	Cards            int            // desired flashcards per chunk (0 lets the model decide)
+
+	// Review settings
+	Scheduler      string          // spaced repetition algorithm: "sm2" or "fsrs"
+	NewCardsPerDay int             // flashcards studied for the first time per day
+	ReviewsPerDay  int             // reviews of previously studied flashcards per day
+	LearningSteps  []time.Duration // delays before a failed flashcard is shown again in the session
+	ReviewTimer    time.Duration   // time to answer a question before the answer is revealed
+	SM2HardFactor  float64         // SM-2 multiplier of the previous interval on Hard
+	SM2EasyBonus   float64         // SM-2 extra multiplier of the Good interval on Easy
+	FSRSRetention  float64         // FSRS probability of recall targeted when a card comes due
 
 	// Application settings
 	DataDir string
+
+	sources map[string]string // where each setting that is not a default came from
+	loadErr error             // first malformed configuration file value
 }
 
+// DefaultConcurrency sends two files at a time without overloading a local model
+const DefaultConcurrency = 2
+
+// DefaultReviewTimer is how long a question is shown before its answer is revealed
+const DefaultReviewTimer = 30 * time.Second
+
+// DefaultChunkTokens keeps prompts well inside the context window of small local models
+const DefaultChunkTokens = 1500
+
+// DefaultLearningSteps shows a failed flashcard again after a minute, then after ten
+var DefaultLearningSteps = []time.Duration{time.Minute, 10 * time.Minute}
+
+// Default daily limits keep sessions short after generating many flashcards at once
+const (
+	DefaultNewCardsPerDay = 20
+	DefaultReviewsPerDay  = 200
+)
+
 // DefaultConfig returns a configuration with sensible defaults
 func DefaultConfig() *Config {
 	homeDir, err := os.UserHomeDir()
@@ -32,32 +78,87 @@ func DefaultConfig() *Config {
 
 	return &Config{
 		DatabasePath:   filepath.Join(dataDir, "flashcards.db"),
+		Provider:       "ollama",
 		OllamaURL:      "http://localhost:11434/api/generate",
 		OllamaModel:    "llama3.1",
 		RequestTimeout: 300, // 5 minutes
+		Concurrency:    DefaultConcurrency,
+		ChunkTokens:    DefaultChunkTokens,
+		Scheduler:      "sm2",
+		NewCardsPerDay: DefaultNewCardsPerDay,
+		ReviewsPerDay:  DefaultReviewsPerDay,
+		LearningSteps:  DefaultLearningSteps,
+		ReviewTimer:    DefaultReviewTimer,
+		SM2HardFactor:  1.2,
+		SM2EasyBonus:   1.3,
+		FSRSRetention:  0.9,
 		DataDir:        dataDir,
 	}
 }
 
-// LoadConfig loads configuration from environment variables with defaults
+// LoadConfig loads the configuration for the current directory
 func LoadConfig() *Config {
-	cfg := DefaultConfig()
+	return Load(".")
+}
 
-	// Override with environment variables if present
-	if model := os.Getenv("CATV_MODEL"); model != "" {
-		cfg.OllamaModel = model
+// parseLearningSteps reads learning steps in the form "1m,10m", or "none" to
+// stop requeuing failed flashcards
+// Malformed entries are ignored
+func (c *Config) parseLearningSteps(value string) {
+	if strings.EqualFold(strings.TrimSpace(value), "none") {
+		c.LearningSteps = nil
+		return
 	}
-
-	if url := os.Getenv("CATV_OLLAMA_URL"); url != "" {
-		cfg.OllamaURL = url
+	var steps []time.Duration
+	for _, entry := range strings.Split(value, ",") {
+		d, err := time.ParseDuration(strings.TrimSpace(entry))
+		if err != nil || d <= 0 {
+			continue
+		}
+		steps = append(steps, d)
+	}
+	if len(steps) > 0 {
+		c.LearningSteps = steps
 	}
+}
 
-	if dataDir := os.Getenv("CATV_DATA_DIR"); dataDir != "" {
-		cfg.DataDir = dataDir
-		cfg.DatabasePath = filepath.Join(dataDir, "flashcards.db")
+// parseChunkTokens reads chunk budgets in the form "2000" or
+// "llama3.1=6000,phi3=1000,2000" where the bare number is the default
+// Malformed entries are ignored
+func (c *Config) parseChunkTokens(value string) {
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		model, tokens, perModel := strings.Cut(entry, "=")
+		if !perModel {
+			tokens = entry
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(tokens))
+		if err != nil || n <= 0 {
+			continue
+		}
+		if !perModel {
+			c.ChunkTokens = n
+			continue
+		}
+		if c.ModelChunkTokens == nil {
+			c.ModelChunkTokens = make(map[string]int)
+		}
+		c.ModelChunkTokens[strings.TrimSpace(model)] = n
 	}
+}
 
-	return cfg
+// ChunkTokensFor returns the chunk budget for a model
+// An override for "llama3.1" also applies to tags such as "llama3.1:70b"
+func (c *Config) ChunkTokensFor(model string) int {
+	if n, ok := c.ModelChunkTokens[model]; ok {
+		return n
+	}
+	if base, _, ok := strings.Cut(model, ":"); ok {
+		if n, ok := c.ModelChunkTokens[base]; ok {
+			return n
+		}
+	}
+	return c.ChunkTokens
 }
 
 // EnsureDataDir creates the data directory if it doesn't exist
@@ -67,6 +168,12 @@ func (c *Config) EnsureDataDir() error {
 
 // Validate checks if the configuration is valid
 func (c *Config) Validate() error {
+	if c.loadErr != nil {
+		return c.loadErr
+	}
+	if c.Provider == "" {
+		return fmt.Errorf("provider cannot be empty")
+	}
 	if c.OllamaURL == "" {
 		return fmt.Errorf("ollama URL cannot be empty")
 	}
@@ -76,5 +183,26 @@ func (c *Config) Validate() error {
 	if c.RequestTimeout <= 0 {
 		return fmt.Errorf("request timeout must be positive")
 	}
+	if c.ChunkTokens <= 0 {
+		return fmt.Errorf("chunk tokens must be positive")
+	}
+	if c.Scheduler == "" {
+		return fmt.Errorf("scheduler cannot be empty")
+	}
+	if c.NewCardsPerDay < 0 || c.ReviewsPerDay < 0 {
+		return fmt.Errorf("daily limits cannot be negative")
+	}
+	if c.Concurrency < 1 {
+		return fmt.Errorf("concurrency must be at least 1")
+	}
+	if c.ReviewTimer <= 0 {
+		return fmt.Errorf("review timer must be positive")
+	}
+	if c.SM2HardFactor <= 0 || c.SM2EasyBonus <= 0 {
+		return fmt.Errorf("SM-2 factors must be positive")
+	}
+	if c.FSRSRetention <= 0 || c.FSRSRetention >= 1 {
+		return fmt.Errorf("FSRS retention must be between 0 and 1")
+	}
 	return nil
 }
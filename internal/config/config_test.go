@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -28,17 +29,32 @@ func TestDefaultConfig(t *testing.T) {
 	if cfg.DatabasePath == "" {
 		t.Error("Expected DatabasePath to be set")
 	}
+
+	if cfg.Scheduler != "sm2" {
+		t.Errorf("Expected default scheduler 'sm2', got '%s'", cfg.Scheduler)
+	}
+
+	if cfg.NewCardsPerDay != DefaultNewCardsPerDay || cfg.ReviewsPerDay != DefaultReviewsPerDay {
+		t.Errorf("Expected default daily limits %d/%d, got %d/%d", DefaultNewCardsPerDay, DefaultReviewsPerDay, cfg.NewCardsPerDay, cfg.ReviewsPerDay)
+	}
 }
 
 func TestLoadConfig(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
 	// Test environment variable override
 	os.Setenv("CATV_MODEL", "test-model")
 	os.Setenv("CATV_OLLAMA_URL", "http://test:1234")
 	os.Setenv("CATV_DATA_DIR", "/tmp/test-catv")
+	os.Setenv("CATV_SCHEDULER", "fsrs")
+	os.Setenv("CATV_NEW_PER_DAY", "0")
+	os.Setenv("CATV_REVIEWS_PER_DAY", "lots")
 	defer func() {
 		os.Unsetenv("CATV_MODEL")
 		os.Unsetenv("CATV_OLLAMA_URL")
 		os.Unsetenv("CATV_DATA_DIR")
+		os.Unsetenv("CATV_SCHEDULER")
+		os.Unsetenv("CATV_NEW_PER_DAY")
+		os.Unsetenv("CATV_REVIEWS_PER_DAY")
 	}()
 
 	cfg := LoadConfig()
@@ -59,6 +75,18 @@ func TestLoadConfig(t *testing.T) {
 	if cfg.DatabasePath != expectedDBPath {
 		t.Errorf("Expected DatabasePath '%s', got '%s'", expectedDBPath, cfg.DatabasePath)
 	}
+
+	if cfg.Scheduler != "fsrs" {
+		t.Errorf("Expected scheduler 'fsrs', got '%s'", cfg.Scheduler)
+	}
+
+	if cfg.NewCardsPerDay != 0 {
+		t.Errorf("Expected no new cards per day, got %d", cfg.NewCardsPerDay)
+	}
+
+	if cfg.ReviewsPerDay != DefaultReviewsPerDay {
+		t.Errorf("Expected a malformed review limit to be ignored, got %d", cfg.ReviewsPerDay)
+	}
 }
 
 func TestConfigValidate(t *testing.T) {
@@ -70,12 +98,30 @@ func TestConfigValidate(t *testing.T) {
 		{
 			name: "valid config",
 			cfg: Config{
+				Provider:       "ollama",
 				OllamaURL:      "http://localhost:11434/api/generate",
 				OllamaModel:    "llama3.1",
 				RequestTimeout: 300,
+				ChunkTokens:    1500,
+				Scheduler:      "sm2",
+				Concurrency:    2,
+				ReviewTimer:    DefaultReviewTimer,
+				SM2HardFactor:  1.2,
+				SM2EasyBonus:   1.3,
+				FSRSRetention:  0.9,
 			},
 			wantErr: false,
 		},
+		{
+			name: "zero chunk tokens",
+			cfg: Config{
+				OllamaURL:      "http://localhost:11434/api/generate",
+				OllamaModel:    "llama3.1",
+				RequestTimeout: 300,
+				Scheduler:      "sm2",
+			},
+			wantErr: true,
+		},
 		{
 			name: "empty URL",
 			cfg: Config{
@@ -94,6 +140,36 @@ func TestConfigValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "empty scheduler",
+			cfg: Config{
+				OllamaURL:      "http://localhost:11434/api/generate",
+				OllamaModel:    "llama3.1",
+				RequestTimeout: 300,
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative daily limit",
+			cfg: Config{
+				OllamaURL:      "http://localhost:11434/api/generate",
+				OllamaModel:    "llama3.1",
+				RequestTimeout: 300,
+				ChunkTokens:    1500,
+				Scheduler:      "sm2",
+				NewCardsPerDay: -1,
+			},
+			wantErr: true,
+		},
+		{
+			name: "retention out of range",
+			cfg: func() Config {
+				cfg := *DefaultConfig()
+				cfg.FSRSRetention = 1
+				return cfg
+			}(),
+			wantErr: true,
+		},
 		{
 			name: "negative timeout",
 			cfg: Config{
@@ -115,6 +191,56 @@ func TestConfigValidate(t *testing.T) {
 	}
 }
 
+func TestChunkTokensFor(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.parseChunkTokens("llama3.1=6000, phi3=800, bogus=abc, 2500, -3")
+
+	tests := []struct {
+		model    string
+		expected int
+	}{
+		{"llama3.1", 6000},
+		{"llama3.1:70b", 6000},
+		{"phi3", 800},
+		{"mistral", 2500},
+		{"bogus", 2500},
+	}
+	for _, tt := range tests {
+		if got := cfg.ChunkTokensFor(tt.model); got != tt.expected {
+			t.Errorf("ChunkTokensFor(%q) = %d, expected %d", tt.model, got, tt.expected)
+		}
+	}
+
+	if got := DefaultConfig().ChunkTokensFor("llama3.1"); got != DefaultChunkTokens {
+		t.Errorf("Expected default chunk budget %d, got %d", DefaultChunkTokens, got)
+	}
+}
+
+func TestParseLearningSteps(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected []time.Duration
+	}{
+		{"30s, 5m,1h", []time.Duration{30 * time.Second, 5 * time.Minute, time.Hour}},
+		{"2m,soon,-1m", []time.Duration{2 * time.Minute}},
+		{"bogus", DefaultLearningSteps},
+		{"None", nil},
+	}
+	for _, tt := range tests {
+		cfg := DefaultConfig()
+		cfg.parseLearningSteps(tt.value)
+		if len(cfg.LearningSteps) != len(tt.expected) {
+			t.Errorf("parseLearningSteps(%q) = %v, expected %v", tt.value, cfg.LearningSteps, tt.expected)
+			continue
+		}
+		for i := range tt.expected {
+			if cfg.LearningSteps[i] != tt.expected[i] {
+				t.Errorf("parseLearningSteps(%q) = %v, expected %v", tt.value, cfg.LearningSteps, tt.expected)
+			}
+		}
+	}
+}
+
 func TestEnsureDataDir(t *testing.T) {
 	// Create a temporary directory for testing
 	tempDir := t.TempDir()
@@ -0,0 +1,356 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ProjectConfigName is the per-project configuration file, looked up from the
+// notes directory towards the root
+const ProjectConfigName = ".catv.toml"
+
+// setting is a configuration value that can be set in configuration files and
+// overridden by an environment variable
+type setting struct {
+	key    string // Key in configuration files, e.g. "review.timer"
+	env    string // Environment variable overriding the files
+	number bool   // Whether the value is written to files unquoted
+	secret bool   // Whether the value is hidden when settings are listed
+	get    func(c *Config) string
+	set    func(c *Config, value string) error
+}
+
+// settings lists every configurable value in the order they are listed
+var settings = []setting{
+	{
+		key: "data_dir", env: "CATV_DATA_DIR",
+		get: func(c *Config) string { return c.DataDir },
+		set: func(c *Config, value string) error {
+			dir, err := expandHome(value)
+			if err != nil {
+				return err
+			}
+			c.DataDir = dir
+			c.DatabasePath = filepath.Join(dir, "flashcards.db")
+			return nil
+		},
+	},
+	stringSetting("llm.provider", "CATV_PROVIDER", func(c *Config) *string { return &c.Provider }),
+	{
+		key: "llm.api_key", env: "CATV_API_KEY", secret: true,
+		get: func(c *Config) string { return c.APIKey },
+		set: func(c *Config, value string) error {
+			c.APIKey = value
+			return nil
+		},
+	},
+	stringSetting("ollama.url", "CATV_OLLAMA_URL", func(c *Config) *string { return &c.OllamaURL }),
+	stringSetting("ollama.model", "CATV_MODEL", func(c *Config) *string { return &c.OllamaModel }),
+	intSetting("ollama.timeout", "CATV_TIMEOUT", 1, func(c *Config) *int { return &c.RequestTimeout }),
+	intSetting("generate.concurrency", "CATV_CONCURRENCY", 1, func(c *Config) *int { return &c.Concurrency }),
+	{
+		key: "generate.chunk_tokens", env: "CATV_CHUNK_TOKENS",
+		get: func(c *Config) string {
+			models := make([]string, 0, len(c.ModelChunkTokens))
+			for model := range c.ModelChunkTokens {
+				models = append(models, model)
+			}
+			sort.Strings(models)
+			entries := make([]string, 0, len(models)+1)
+			for _, model := range models {
+				entries = append(entries, fmt.Sprintf("%s=%d", model, c.ModelChunkTokens[model]))
+			}
+			return strings.Join(append(entries, strconv.Itoa(c.ChunkTokens)), ",")
+		},
+		set: func(c *Config, value string) error {
+			c.parseChunkTokens(value)
+			return nil
+		},
+	},
+	stringSetting("generate.prompt", "CATV_PROMPT", func(c *Config) *string { return &c.Prompt }),
+	stringSetting("generate.template", "CATV_PROMPT_TEMPLATE", func(c *Config) *string { return &c.PromptTemplate }),
+	stringSetting("generate.language", "CATV_LANGUAGE", func(c *Config) *string { return &c.Language }),
+	stringSetting("generate.difficulty", "CATV_DIFFICULTY", func(c *Config) *string { return &c.Difficulty }),
+	intSetting("generate.cards", "CATV_CARDS", 0, func(c *Config) *int { return &c.Cards }),
+	stringSetting("review.scheduler", "CATV_SCHEDULER", func(c *Config) *string { return &c.Scheduler }),
+	{
+		key: "review.timer", env: "CATV_REVIEW_TIMER",
+		get: func(c *Config) string { return formatDuration(c.ReviewTimer) },
+		set: func(c *Config, value string) error {
+			d, err := parseTimer(value)
+			if err != nil {
+				return err
+			}
+			c.ReviewTimer = d
+			return nil
+		},
+	},
+	intSetting("review.new_per_day", "CATV_NEW_PER_DAY", 0, func(c *Config) *int { return &c.NewCardsPerDay }),
+	intSetting("review.reviews_per_day", "CATV_REVIEWS_PER_DAY", 0, func(c *Config) *int { return &c.ReviewsPerDay }),
+	{
+		key: "review.learning_steps", env: "CATV_LEARNING_STEPS",
+		get: func(c *Config) string {
+			if len(c.LearningSteps) == 0 {
+				return "none"
+			}
+			steps := make([]string, len(c.LearningSteps))
+			for i, step := range c.LearningSteps {
+				steps[i] = formatDuration(step)
+			}
+			return strings.Join(steps, ",")
+		},
+		set: func(c *Config, value string) error {
+			c.parseLearningSteps(value)
+			return nil
+		},
+	},
+	floatSetting("review.sm2_hard_factor", "CATV_SM2_HARD_FACTOR", func(c *Config) *float64 { return &c.SM2HardFactor }),
+	floatSetting("review.sm2_easy_bonus", "CATV_SM2_EASY_BONUS", func(c *Config) *float64 { return &c.SM2EasyBonus }),
+	floatSetting("review.fsrs_retention", "CATV_FSRS_RETENTION", func(c *Config) *float64 { return &c.FSRSRetention }),
+}
+
+func stringSetting(key, env string, field func(c *Config) *string) setting {
+	return setting{
+		key: key, env: env,
+		get: func(c *Config) string { return *field(c) },
+		set: func(c *Config, value string) error {
+			*field(c) = value
+			return nil
+		},
+	}
+}
+
+// intSetting is a whole number setting that cannot go below minimum
+func intSetting(key, env string, minimum int, field func(c *Config) *int) setting {
+	return setting{
+		key: key, env: env, number: true,
+		get: func(c *Config) string { return strconv.Itoa(*field(c)) },
+		set: func(c *Config, value string) error {
+			n, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return fmt.Errorf("expected a whole number, got %q", value)
+			}
+			if n < minimum {
+				return fmt.Errorf("must be at least %d, got %d", minimum, n)
+			}
+			*field(c) = n
+			return nil
+		},
+	}
+}
+
+func floatSetting(key, env string, field func(c *Config) *float64) setting {
+	return setting{
+		key: key, env: env, number: true,
+		get: func(c *Config) string { return strconv.FormatFloat(*field(c), 'g', -1, 64) },
+		set: func(c *Config, value string) error {
+			f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				return fmt.Errorf("expected a number, got %q", value)
+			}
+			*field(c) = f
+			return nil
+		},
+	}
+}
+
+// parseTimer reads a duration such as "45s", or a bare number of seconds
+func parseTimer(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("expected a duration such as 30s, got %q", value)
+	}
+	return d, nil
+}
+
+// formatDuration writes d without the zero units time.Duration adds, e.g. "1m"
+// rather than "1m0s"
+func formatDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+// expandHome replaces a leading ~ with the home directory
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, path[1:]), nil
+}
+
+func lookupSetting(key string) (setting, error) {
+	for _, s := range settings {
+		if s.key == key {
+			return s, nil
+		}
+	}
+	return setting{}, fmt.Errorf("unknown setting %q (available: %s)", key, strings.Join(Keys(), ", "))
+}
+
+// Keys returns the key of every setting
+func Keys() []string {
+	keys := make([]string, len(settings))
+	for i, s := range settings {
+		keys[i] = s.key
+	}
+	return keys
+}
+
+// IsSecret reports whether the value of a setting should not be displayed
+func IsSecret(key string) bool {
+	s, err := lookupSetting(key)
+	return err == nil && s.secret
+}
+
+// Get returns the value of a setting as it would be written in a configuration file
+func (c *Config) Get(key string) (string, error) {
+	s, err := lookupSetting(key)
+	if err != nil {
+		return "", err
+	}
+	return s.get(c), nil
+}
+
+// Source returns where the value of a setting came from: "default", the path
+// of a configuration file, or the environment variable
+func (c *Config) Source(key string) string {
+	if source, ok := c.sources[key]; ok {
+		return source
+	}
+	return "default"
+}
+
+// UserConfigPath returns the user configuration file, following the XDG base
+// directory specification
+func UserConfigPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" || !filepath.IsAbs(dir) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "catv", "config.toml")
+}
+
+// FindProjectConfig returns the closest .catv.toml in the directory of path or
+// one of its parents, or "" if there is none
+func FindProjectConfig(path string) string {
+	dir, err := filepath.Abs(path)
+	if err != nil {
+		return ""
+	}
+	if info, err := os.Stat(dir); err == nil && !info.IsDir() {
+		dir = filepath.Dir(dir)
+	}
+	for {
+		candidate := filepath.Join(dir, ProjectConfigName)
+		if info, err := os.Stat(candidate); err == nil && info.Mode().IsRegular() {
+			return candidate
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// Load returns the configuration for notes at path, from lowest to highest
+// precedence: defaults, the user file, the project file and environment variables
+// Command line flags are applied on top by the commands
+// A malformed configuration file is reported by Validate
+func Load(path string) *Config {
+	cfg := DefaultConfig()
+	cfg.sources = make(map[string]string)
+	for _, file := range []string{UserConfigPath(), FindProjectConfig(path)} {
+		if err := cfg.loadFile(file); err != nil && cfg.loadErr == nil {
+			cfg.loadErr = err
+		}
+	}
+
+	// Malformed environment variables are ignored
+	for _, s := range settings {
+		if value := os.Getenv(s.env); value != "" && s.set(cfg, value) == nil {
+			cfg.sources[s.key] = s.env
+		}
+	}
+	return cfg
+}
+
+// loadFile applies the settings of a configuration file, if it exists
+func (c *Config) loadFile(path string) error {
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path) // #nosec G304 -- configuration files are chosen by the user
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	values, err := readTOML(string(data))
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		s, err := lookupSetting(key)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if err := s.set(c, values[key]); err != nil {
+			return fmt.Errorf("%s: %s: %w", path, key, err)
+		}
+		c.sources[key] = path
+	}
+	return nil
+}
+
+// SetInFile writes a setting to the configuration file at path, keeping its
+// other settings
+func SetInFile(path, key, value string) error {
+	s, err := lookupSetting(key)
+	if err != nil {
+		return err
+	}
+	if err := s.set(DefaultConfig(), value); err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	var encoded any = value
+	if s.number {
+		value = strings.TrimSpace(value)
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			encoded = n
+		} else if f, err := strconv.ParseFloat(value, 64); err == nil {
+			encoded = f
+		}
+	}
+	return writeTOML(path, key, encoded)
+}
@@ -0,0 +1,197 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeConfig writes a configuration file, creating its directory
+func writeConfig(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	xdg := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	t.Setenv("CATV_CONCURRENCY", "6")
+	t.Setenv("CATV_REVIEW_TIMER", "soon")
+
+	user := filepath.Join(xdg, "catv", "config.toml")
+	writeConfig(t, user, "[ollama]\nmodel = \"qwen2.5\"\ntimeout = 60\n\n[generate]\nconcurrency = 3\n\n[review]\ntimer = 45\n")
+	project := t.TempDir()
+	notes := filepath.Join(project, "go", "channels")
+	if err := os.MkdirAll(notes, 0700); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	writeConfig(t, filepath.Join(project, ProjectConfigName), "[ollama]\nmodel = \"phi3\"\n[review]\nlearning_steps = [\"30s\", \"5m\"]\n")
+
+	cfg := Load(notes)
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	tests := []struct {
+		key, value, source string
+	}{
+		{"ollama.model", "phi3", filepath.Join(project, ProjectConfigName)},
+		{"ollama.timeout", "60", user},
+		{"generate.concurrency", "6", "CATV_CONCURRENCY"},
+		{"review.timer", "45s", user},
+		{"review.learning_steps", "30s,5m", filepath.Join(project, ProjectConfigName)},
+		{"review.scheduler", "sm2", "default"},
+	}
+	for _, tt := range tests {
+		value, err := cfg.Get(tt.key)
+		if err != nil || value != tt.value {
+			t.Errorf("Get(%q) = %q, %v, want %q", tt.key, value, err, tt.value)
+		}
+		if source := cfg.Source(tt.key); source != tt.source {
+			t.Errorf("Source(%q) = %q, want %q", tt.key, source, tt.source)
+		}
+	}
+	if cfg.RequestTimeout != 60 || cfg.ReviewTimer != 45*time.Second {
+		t.Errorf("Expected the typed fields to be set, got %+v", cfg)
+	}
+
+	// Without a project file only the user file applies
+	if cfg := Load(t.TempDir()); cfg.OllamaModel != "qwen2.5" {
+		t.Errorf("Expected the user model outside the project, got %q", cfg.OllamaModel)
+	}
+}
+
+func TestLoad_InvalidFile(t *testing.T) {
+	xdg := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	tests := map[string]string{
+		"unknown key":   "[review]\ntimeer = \"30s\"\n",
+		"invalid value": "[generate]\nconcurrency = 0\n",
+		"syntax":        "[review\n",
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(xdg, "catv", "config.toml")
+			writeConfig(t, path, data)
+			err := Load(t.TempDir()).Validate()
+			if err == nil || !strings.Contains(err.Error(), path) {
+				t.Errorf("Expected an error naming the file, got %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadFile_TOML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	writeConfig(t, path, `# catv settings
+review.timer = "1m"
+
+[ollama]
+model = 'qwen2.5:7b' # literal string
+timeout = 1_200
+
+[generate]
+prompt = """
+Write the answers in German.
+Keep them short."""
+
+[review]
+learning_steps = [
+  "30s",
+  "5m",
+]
+fsrs_retention = 0.85
+`)
+	cfg := DefaultConfig()
+	cfg.sources = make(map[string]string)
+	if err := cfg.loadFile(path); err != nil {
+		t.Fatalf("loadFile() error = %v", err)
+	}
+	if cfg.ReviewTimer != time.Minute || cfg.OllamaModel != "qwen2.5:7b" || cfg.RequestTimeout != 1200 || cfg.FSRSRetention != 0.85 {
+		t.Errorf("Unexpected settings %+v", cfg)
+	}
+	if cfg.Prompt != "Write the answers in German.\nKeep them short." {
+		t.Errorf("Prompt = %q", cfg.Prompt)
+	}
+	if len(cfg.LearningSteps) != 2 || cfg.LearningSteps[1] != 5*time.Minute {
+		t.Errorf("LearningSteps = %v", cfg.LearningSteps)
+	}
+
+	writeConfig(t, path, "[[review]]\ntimer = 30\n")
+	if err := cfg.loadFile(path); err == nil {
+		t.Error("Expected an array of tables to be rejected")
+	}
+}
+
+func TestSetInFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catv", "config.toml")
+	writeConfig(t, path, "[ollama]\nmodel = \"phi3\"\n\n[review]\nlearning_steps = [\"30s\", \"5m\"]\n")
+	for _, kv := range [][2]string{
+		{"review.timer", "1m"},
+		{"generate.concurrency", "4"},
+		{"generate.prompt", "Write the answers in \"German\""},
+		{"generate.concurrency", "5"},
+	} {
+		if err := SetInFile(path, kv[0], kv[1]); err != nil {
+			t.Fatalf("SetInFile(%q, %q) error = %v", kv[0], kv[1], err)
+		}
+	}
+	if err := SetInFile(path, "generate.concurrency", "many"); err == nil {
+		t.Error("Expected an invalid value to be rejected")
+	}
+	if err := SetInFile(path, "review.colour", "red"); err == nil {
+		t.Error("Expected an unknown key to be rejected")
+	}
+
+	cfg := DefaultConfig()
+	cfg.sources = make(map[string]string)
+	if err := cfg.loadFile(path); err != nil {
+		t.Fatalf("loadFile() error = %v", err)
+	}
+	if cfg.ReviewTimer != time.Minute || cfg.Concurrency != 5 || cfg.Prompt != `Write the answers in "German"` {
+		t.Errorf("Unexpected settings read back: timer %v, concurrency %d, prompt %q", cfg.ReviewTimer, cfg.Concurrency, cfg.Prompt)
+	}
+	// Settings already in the file are kept
+	if cfg.OllamaModel != "phi3" || len(cfg.LearningSteps) != 2 {
+		t.Errorf("Expected the existing settings to be kept, got model %q and steps %v", cfg.OllamaModel, cfg.LearningSteps)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "concurrency = 5\n") {
+		t.Errorf("Expected numbers to be written unquoted, got:\n%s", data)
+	}
+}
+
+func TestFindProjectConfig(t *testing.T) {
+	root := t.TempDir()
+	note := filepath.Join(root, "a", "b", "note.md")
+	writeConfig(t, note, "# Note")
+	if got := FindProjectConfig(note); got != "" {
+		t.Errorf("Expected no project configuration, got %q", got)
+	}
+
+	want := filepath.Join(root, "a", ProjectConfigName)
+	writeConfig(t, want, "")
+	for _, path := range []string{note, filepath.Dir(note), filepath.Join(root, "a")} {
+		if got := FindProjectConfig(path); got != want {
+			t.Errorf("FindProjectConfig(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestUserConfigPath(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/xdg")
+	if got := UserConfigPath(); got != filepath.Join("/xdg", "catv", "config.toml") {
+		t.Errorf("UserConfigPath() = %q", got)
+	}
+	t.Setenv("XDG_CONFIG_HOME", "")
+	home, _ := os.UserHomeDir()
+	if got := UserConfigPath(); got != filepath.Join(home, ".config", "catv", "config.toml") {
+		t.Errorf("UserConfigPath() = %q", got)
+	}
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// readTOML decodes a configuration file into values keyed "section.key"
+// Arrays are joined with commas, the way settings such as learning steps are
+// also given in environment variables
+func readTOML(data string) (map[string]string, error) {
+	var table map[string]any
+	if _, err := toml.Decode(data, &table); err != nil {
+		return nil, err
+	}
+	values := make(map[string]string)
+	if err := flattenTOML("", table, values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// flattenTOML adds the values of table and its subtables to values
+func flattenTOML(prefix string, table map[string]any, values map[string]string) error {
+	for name, v := range table {
+		key := prefix + name
+		if sub, ok := v.(map[string]any); ok {
+			if err := flattenTOML(key+".", sub, values); err != nil {
+				return err
+			}
+			continue
+		}
+		value, err := tomlString(v)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		values[key] = value
+	}
+	return nil
+}
+
+// tomlString writes a decoded value the way settings parse it
+func tomlString(v any) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			s, err := tomlString(item)
+			if err != nil {
+				return "", err
+			}
+			items[i] = s
+		}
+		return strings.Join(items, ","), nil
+	}
+	return "", fmt.Errorf("unsupported value %v", v)
+}
+
+// writeTOML sets key, "section.key", to value in the file at path, creating
+// it and its directory when needed
+// The file is decoded and encoded again, so its comments are not kept
+func writeTOML(path, key string, value any) error {
+	data, err := os.ReadFile(path) // #nosec G304 -- path is a configuration file chosen by the user
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	table := make(map[string]any)
+	if _, err := toml.Decode(string(data), &table); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	parts := strings.Split(key, ".")
+	parent := table
+	for _, name := range parts[:len(parts)-1] {
+		sub, ok := parent[name].(map[string]any)
+		if !ok {
+			sub = make(map[string]any)
+			parent[name] = sub
+		}
+		parent = sub
+	}
+	parent[parts[len(parts)-1]] = value
+
+	var buf bytes.Buffer
+	enc := toml.NewEncoder(&buf)
+	enc.Indent = ""
+	if err := enc.Encode(table); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0600)
+}
@@ -0,0 +1,351 @@
+package exporter
+
+import (
+	"archive/zip"
+	"crypto/sha1" // #nosec G505 -- Anki's note checksum is defined as SHA-1
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"catv/internal/importer"
+	"catv/internal/scheduler"
+	"catv/internal/store"
+
+	_ "github.com/mattn/go-sqlite3" // SQLite driver for the collection inside the package
+)
+
+// Note type ids are fixed so notes exported at different times share their
+// note types once imported
+const (
+	ankiBasicModelID = 1700000000001
+	ankiClozeModelID = 1700000000002
+	ankiDefaultDeck  = 1
+	ankiFieldSep     = "\x1f"
+	ankiDeckPrefix   = "catv::"
+)
+
+// ankiSchema is Anki's legacy (version 11) collection schema, which every
+// Anki version can import
+var ankiSchema = []string{
+	`CREATE TABLE col (id integer primary key, crt integer not null, mod integer not null, scm integer not null,
+		ver integer not null, dty integer not null, usn integer not null, ls integer not null, conf text not null,
+		models text not null, decks text not null, dconf text not null, tags text not null)`,
+	`CREATE TABLE notes (id integer primary key, guid text not null, mid integer not null, mod integer not null,
+		usn integer not null, tags text not null, flds text not null, sfld integer not null, csum integer not null,
+		flags integer not null, data text not null)`,
+	`CREATE TABLE cards (id integer primary key, nid integer not null, did integer not null, ord integer not null,
+		mod integer not null, usn integer not null, type integer not null, queue integer not null, due integer not null,
+		ivl integer not null, factor integer not null, reps integer not null, lapses integer not null, left integer not null,
+		odue integer not null, odid integer not null, flags integer not null, data text not null)`,
+	`CREATE TABLE revlog (id integer primary key, cid integer not null, usn integer not null, ease integer not null,
+		ivl integer not null, lastIvl integer not null, factor integer not null, time integer not null, type integer not null)`,
+	`CREATE TABLE graves (usn integer not null, oid integer not null, type integer not null)`,
+}
+
+// WriteAnki writes the flashcards as an Anki package (.apkg)
+// Each source file becomes a deck; flashcards imported from Anki go back to
+// their deck. Scheduling and review history are kept, so reviews continue in
+// Anki where they left off in catv.
+func WriteAnki(w io.Writer, deck Deck, now time.Time) error {
+	dir, err := os.MkdirTemp("", "catv-export-")
+	if err != nil {
+		return fmt.Errorf("failed to create Anki collection: %w", err)
+	}
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	collection := filepath.Join(dir, "collection.anki2")
+	if err := writeCollection(collection, deck, now); err != nil {
+		return err
+	}
+	data, err := os.ReadFile(filepath.Clean(collection))
+	if err != nil {
+		return fmt.Errorf("failed to read Anki collection: %w", err)
+	}
+
+	zw := zip.NewWriter(w)
+	for _, entry := range []struct {
+		name string
+		data []byte
+	}{{"collection.anki2", data}, {"media", []byte("{}")}} {
+		f, err := zw.Create(entry.name)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(entry.data); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// writeCollection creates the SQLite collection of the package at path
+func writeCollection(path string, deck Deck, now time.Time) error {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return fmt.Errorf("failed to create Anki collection: %w", err)
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+	for _, stmt := range ankiSchema {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("failed to create Anki collection: %w", err)
+		}
+	}
+
+	// Due dates of review cards count days from the collection's creation, so
+	// it starts on the earliest day a card was due or reviewed
+	created := store.DueAfter(now, 0)
+	for _, fc := range deck.Flashcards {
+		for _, t := range []time.Time{fc.DueAt, fc.LastReviewedAt} {
+			if !t.IsZero() && t.Before(created) {
+				created = store.DueAfter(t, 0)
+			}
+		}
+	}
+
+	w := ankiWriter{tx: tx, created: created, mod: now.Unix(), nextID: now.UnixMilli(), decks: make(map[string]int64)}
+	for i, n := range notes(deck.Flashcards) {
+		if err := w.writeNote(n, i, deck.Reviews); err != nil {
+			return err
+		}
+	}
+	if err := w.writeCol(); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ankiWriter fills a collection, assigning ids and decks as notes are added
+type ankiWriter struct {
+	tx      *sql.Tx
+	created time.Time
+	mod     int64
+	nextID  int64 // Anki ids are millisecond timestamps
+	decks   map[string]int64
+}
+
+func (w *ankiWriter) id() int64 {
+	w.nextID++
+	return w.nextID
+}
+
+// deckID returns the id of the Anki deck for a flashcard, creating it if needed
+func (w *ankiWriter) deckID(fc store.Flashcard) int64 {
+	name := fc.Deck
+	if name == "" {
+		name = store.DeckName(fc.File)
+	}
+	// Decks that came from Anki go back under their own name
+	if !strings.HasPrefix(fc.File, importer.AnkiPrefix) {
+		name = ankiDeckPrefix + name
+	}
+	if id, ok := w.decks[name]; ok {
+		return id
+	}
+	id := w.id()
+	w.decks[name] = id
+	return id
+}
+
+// writeNote adds a note with its cards and their review history
+func (w *ankiWriter) writeNote(n note, position int, reviews map[int][]store.Review) error {
+	fc := n.first()
+	model := int64(ankiBasicModelID)
+	if fc.IsCloze() {
+		model = ankiClozeModelID
+	}
+	// The checksum of the sort field lets Anki find duplicates, and the guid
+	// identifies the note when the same export is imported again
+	sum := sha1.Sum([]byte(fc.Question))                           // #nosec G401 -- checksum, not a security measure
+	guid := sha1.Sum([]byte(fc.File + ankiFieldSep + fc.Question)) // #nosec G401
+	csum, _ := strconv.ParseInt(hex.EncodeToString(sum[:4]), 16, 64)
+	tags := ""
+	if len(fc.Tags) > 0 {
+		tags = " " + strings.Join(fc.Tags, " ") + " "
+	}
+
+	noteID := w.id()
+	if _, err := w.tx.Exec(`INSERT INTO notes VALUES (?, ?, ?, ?, -1, ?, ?, ?, ?, 0, '')`,
+		noteID, hex.EncodeToString(guid[:8]), model, w.mod, tags, markdownToHTML(fc.Question)+ankiFieldSep+markdownToHTML(fc.Answer),
+		fc.Question, csum); err != nil {
+		return fmt.Errorf("failed to write Anki note: %w", err)
+	}
+
+	did := w.deckID(fc)
+	for _, card := range n.cards {
+		cardID := w.id()
+		ord := 0
+		if card.IsCloze() {
+			ord = card.ClozeIndex - 1
+		}
+		s := w.schedule(card, position)
+		if _, err := w.tx.Exec(`INSERT INTO cards VALUES (?, ?, ?, ?, ?, -1, ?, ?, ?, ?, ?, ?, ?, 0, 0, 0, 0, ?)`,
+			cardID, noteID, did, ord, w.mod, s.typ, s.typ, s.due, s.ivl, s.factor, card.Repetitions, card.Lapses, s.data); err != nil {
+			return fmt.Errorf("failed to write Anki card: %w", err)
+		}
+		for _, r := range reviews[card.ID] {
+			if err := w.writeReview(cardID, r, s.factor); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ankiSchedule is the scheduling of a card in Anki's terms
+type ankiSchedule struct {
+	typ    int   // 0 for new cards, 2 for cards in review; the queue is the same
+	due    int64 // Position of new cards, day number of cards in review
+	ivl    int
+	factor int
+	data   string
+}
+
+func (w *ankiWriter) schedule(fc store.Flashcard, position int) ankiSchedule {
+	if fc.LastReviewedAt.IsZero() && fc.Repetitions == 0 {
+		return ankiSchedule{due: int64(position)}
+	}
+	s := ankiSchedule{typ: 2, ivl: max(fc.IntervalDays, 1), factor: int(fc.Ease * 1000)}
+	if s.factor == 0 {
+		s.factor = int(scheduler.DefaultEase * 1000)
+	}
+	due := fc.DueAt
+	if due.IsZero() {
+		due = w.created
+	}
+	s.due = int64(store.DueAfter(due, 0).Sub(w.created).Round(24*time.Hour) / (24 * time.Hour))
+	if fc.Stability > 0 {
+		memory, _ := json.Marshal(map[string]float64{"s": fc.Stability, "d": fc.Difficulty})
+		s.data = string(memory)
+	}
+	return s
+}
+
+// writeReview adds a review to the revlog, whose ids are review timestamps
+func (w *ankiWriter) writeReview(cardID int64, r store.Review, factor int) error {
+	kind := 1 // Review
+	switch {
+	case r.Relearning:
+		kind = 2 // Relearning
+	case r.IntervalBefore == 0:
+		kind = 0 // Learning
+	}
+	id := r.ReviewedAt.UnixMilli()
+	for {
+		res, err := w.tx.Exec(`INSERT OR IGNORE INTO revlog VALUES (?, ?, -1, ?, ?, ?, ?, ?, ?)`,
+			id, cardID, r.Grade, r.IntervalAfter, r.IntervalBefore, factor, r.ResponseTime.Milliseconds(), kind)
+		if err != nil {
+			return fmt.Errorf("failed to write Anki review: %w", err)
+		}
+		// Reviews made in the same millisecond need distinct ids
+		if n, _ := res.RowsAffected(); n > 0 {
+			return nil
+		}
+		id++
+	}
+}
+
+// writeCol writes the collection row holding the note types, decks and settings
+func (w *ankiWriter) writeCol() error {
+	decks := map[string]interface{}{strconv.Itoa(ankiDefaultDeck): ankiDeck(ankiDefaultDeck, "Default", w.mod)}
+	for name, id := range w.decks {
+		decks[strconv.FormatInt(id, 10)] = ankiDeck(id, name, w.mod)
+	}
+	models := map[string]interface{}{
+		strconv.Itoa(ankiBasicModelID): ankiModel(ankiBasicModelID, "catv Basic", 0, []string{"Front", "Back"},
+			"{{Front}}", "{{FrontSide}}\n\n<hr id=answer>\n\n{{Back}}", w.mod),
+		strconv.Itoa(ankiClozeModelID): ankiModel(ankiClozeModelID, "catv Cloze", 1, []string{"Text", "Back Extra"},
+			"{{cloze:Text}}", "{{cloze:Text}}<br>\n{{Back Extra}}", w.mod),
+	}
+	conf := map[string]interface{}{
+		"activeDecks": []int{ankiDefaultDeck}, "curDeck": ankiDefaultDeck, "newSpread": 0, "collapseTime": 1200,
+		"timeLim": 0, "estTimes": true, "dueCounts": true, "curModel": nil, "nextPos": 1, "sortType": "noteFld",
+		"sortBackwards": false, "addToCur": true,
+	}
+
+	fields := make([]string, 0, 4)
+	for _, v := range []interface{}{conf, models, decks, ankiDeckConf(w.mod)} {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		fields = append(fields, string(data))
+	}
+	_, err := w.tx.Exec(`INSERT INTO col VALUES (1, ?, ?, ?, 11, 0, 0, 0, ?, ?, ?, ?, '{}')`,
+		w.created.Unix(), w.mod*1000, w.mod*1000, fields[0], fields[1], fields[2], fields[3])
+	if err != nil {
+		return fmt.Errorf("failed to write Anki collection: %w", err)
+	}
+	return nil
+}
+
+func ankiDeck(id int64, name string, mod int64) map[string]interface{} {
+	return map[string]interface{}{
+		"id": id, "name": name, "desc": "", "mod": mod, "usn": -1, "conf": 1, "dyn": 0,
+		"collapsed": false, "browserCollapsed": false, "extendNew": 0, "extendRev": 0,
+		"newToday": []int{0, 0}, "revToday": []int{0, 0}, "lrnToday": []int{0, 0}, "timeToday": []int{0, 0},
+	}
+}
+
+func ankiModel(id int64, name string, kind int, fieldNames []string, qfmt, afmt string, mod int64) map[string]interface{} {
+	fields := make([]map[string]interface{}, len(fieldNames))
+	for i, f := range fieldNames {
+		fields[i] = map[string]interface{}{"name": f, "ord": i, "sticky": false, "rtl": false, "font": "Arial", "size": 20, "media": []string{}}
+	}
+	return map[string]interface{}{
+		"id": id, "name": name, "type": kind, "mod": mod, "usn": -1, "sortf": 0, "did": ankiDefaultDeck,
+		"flds": fields,
+		"tmpls": []map[string]interface{}{{
+			"name": "Card 1", "ord": 0, "qfmt": qfmt, "afmt": afmt, "bqfmt": "", "bafmt": "", "did": nil,
+		}},
+		"req":       []interface{}{[]interface{}{0, "any", []int{0}}},
+		"css":       ".card { font-family: arial; font-size: 20px; text-align: center; }\n.cloze { font-weight: bold; color: blue; }",
+		"latexPre":  "\\documentclass[12pt]{article}\n\\special{papersize=3in,5in}\n\\usepackage{amssymb,amsmath}\n\\pagestyle{empty}\n\\begin{document}\n",
+		"latexPost": "\\end{document}",
+		"tags":      []string{},
+		"vers":      []int{},
+	}
+}
+
+// ankiDeckConf is the default deck options group Anki creates
+func ankiDeckConf(mod int64) map[string]interface{} {
+	return map[string]interface{}{
+		"1": map[string]interface{}{
+			"id": 1, "name": "Default", "mod": mod, "usn": -1, "maxTaken": 60, "timer": 0, "autoplay": true,
+			"replayq": true, "dyn": false,
+			"new": map[string]interface{}{
+				"delays": []float64{1, 10}, "ints": []int{1, 4, 0}, "initialFactor": 2500, "order": 1,
+				"perDay": 20, "bury": false, "separate": true,
+			},
+			"rev": map[string]interface{}{
+				"perDay": 200, "ease4": 1.3, "fuzz": 0.05, "maxIvl": 36500, "ivlFct": 1, "bury": false, "hardFactor": 1.2,
+			},
+			"lapse": map[string]interface{}{
+				"delays": []float64{10}, "mult": 0, "minInt": 1, "leechFails": 8, "leechAction": 1,
+			},
+		},
+	}
+}
+
+// markdownToHTML escapes flashcard text for an Anki field, keeping line breaks
+func markdownToHTML(s string) string {
+	return strings.ReplaceAll(html.EscapeString(s), "\n", "<br>")
+}
@@ -0,0 +1,76 @@
+package exporter
+
+import (
+	"archive/zip"
+	"bytes"
+	"database/sql"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"catv/internal/importer"
+	"catv/internal/store"
+)
+
+func TestWriteAnki_RoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteAnki(&buf, testDeck(), now); err != nil {
+		t.Fatalf("WriteAnki() error = %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Expected a zip archive: %v", err)
+	}
+	if len(zr.File) != 2 || zr.File[0].Name != "collection.anki2" || zr.File[1].Name != "media" {
+		t.Errorf("Unexpected package entries %v", zr.File)
+	}
+
+	// The review history goes to the revlog
+	collection := filepath.Join(t.TempDir(), "collection.anki2")
+	src, err := zr.File[0].Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(src)
+	if err := os.WriteFile(collection, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("sqlite3", collection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var reviews int
+	if err := db.QueryRow("SELECT COUNT(*) FROM revlog WHERE ease = 4 OR ease = 3").Scan(&reviews); err != nil || reviews != 2 {
+		t.Errorf("Expected 2 reviews in the revlog, got %d (%v)", reviews, err)
+	}
+	_ = db.Close()
+
+	path := filepath.Join(t.TempDir(), "export.apkg")
+	if err := os.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cards, err := importer.ReadAnki(path)
+	if err != nil {
+		t.Fatalf("ReadAnki() error = %v", err)
+	}
+	if len(cards) != 4 {
+		t.Fatalf("Expected 4 flashcards back, got %d: %+v", len(cards), cards)
+	}
+
+	basic := cards[0]
+	if basic.File != "anki:catv::go" || basic.Question != "What does `defer` do?" || basic.Answer != "Runs a call when\nthe function returns" {
+		t.Errorf("Unexpected flashcard %+v", basic)
+	}
+	want := testDeck().Flashcards[0]
+	// Anki schedules reviews by day
+	if !basic.DueAt.Equal(store.DueAfter(want.DueAt, 0)) || basic.IntervalDays != 6 || basic.Ease != 2.6 {
+		t.Errorf("Expected the scheduling to be kept, got %+v", basic)
+	}
+	if !cards[1].IsCloze() || cards[1].ClozeIndex != 1 || cards[2].ClozeIndex != 2 || !cards[1].DueAt.IsZero() {
+		t.Errorf("Expected two new cloze flashcards, got %+v", cards[1:3])
+	}
+	if cards[3].File != "anki:Spanish" || len(cards[3].Tags) != 2 {
+		t.Errorf("Expected the Anki deck and tags to be kept, got %+v", cards[3])
+	}
+}
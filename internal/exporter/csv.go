@@ -0,0 +1,29 @@
+package exporter
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+)
+
+// csvHeader names the exported columns; the first three match the defaults
+// of catv import, so an export can be imported again with --header
+var csvHeader = []string{"question", "answer", "tags", "file", "heading"}
+
+// WriteCSV writes one row per flashcard, separated by comma, after a header row
+// Cloze texts are written once; importing them creates a flashcard per deletion
+func WriteCSV(w io.Writer, deck Deck, comma rune) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, n := range notes(deck.Flashcards) {
+		fc := n.first()
+		if err := cw.Write([]string{fc.Question, fc.Answer, strings.Join(fc.Tags, " "), fc.File, fc.Heading}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,99 @@
+// Package exporter writes flashcards and their review history in formats
+// other tools can read: Anki packages, CSV/TSV, JSON lines and Markdown
+package exporter
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"catv/internal/store"
+)
+
+// Deck is a set of flashcards to export with their review history
+type Deck struct {
+	Flashcards []store.Flashcard
+	Reviews    map[int][]store.Review // Review history by flashcard ID, oldest first
+}
+
+// Filter selects the flashcards to export; empty fields match every flashcard
+type Filter struct {
+	Files   []string // Source files, or directories holding them
+	Tags    []string // Flashcards with at least one of these tags
+	DueOnly bool     // Only flashcards due for review
+}
+
+// Apply returns the flashcards that match the filter at now
+func (f Filter) Apply(flashcards []store.Flashcard, now time.Time) []store.Flashcard {
+	tags := store.NormalizeTags(f.Tags)
+	matched := make([]store.Flashcard, 0, len(flashcards))
+	for _, fc := range flashcards {
+		if f.DueOnly && !fc.IsDue(now) {
+			continue
+		}
+		if len(f.Files) > 0 && !matchesFile(fc.File, f.Files) {
+			continue
+		}
+		if len(tags) > 0 && !hasAnyTag(fc.Tags, tags) {
+			continue
+		}
+		matched = append(matched, fc)
+	}
+	return matched
+}
+
+// matchesFile reports whether file is one of files or lies in one of them
+func matchesFile(file string, files []string) bool {
+	for _, f := range files {
+		f = strings.TrimSuffix(f, string(filepath.Separator))
+		if file == f || strings.HasPrefix(file, f+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAnyTag(tags, wanted []string) bool {
+	for _, tag := range tags {
+		for _, w := range wanted {
+			if tag == w {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// note is the text shared by the flashcards of one cloze paragraph, or a
+// single question/answer flashcard
+type note struct {
+	cards []store.Flashcard // Flashcards of the note, the first one holding its text
+}
+
+func (n note) first() store.Flashcard {
+	return n.cards[0]
+}
+
+// notes groups the cloze flashcards of each cloze text, so formats that store
+// the text once, and create one card per deletion on import, do not repeat it
+// Notes are sorted by source file, keeping the flashcards' order within a file
+func notes(flashcards []store.Flashcard) []note {
+	var result []note
+	clozeNotes := make(map[[2]string]int)
+	for _, fc := range flashcards {
+		if fc.IsCloze() {
+			key := [2]string{fc.File, fc.Question}
+			if i, ok := clozeNotes[key]; ok {
+				result[i].cards = append(result[i].cards, fc)
+				continue
+			}
+			clozeNotes[key] = len(result)
+		}
+		result = append(result, note{cards: []store.Flashcard{fc}})
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].first().File < result[j].first().File
+	})
+	return result
+}
@@ -0,0 +1,132 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"catv/internal/importer"
+	"catv/internal/store"
+)
+
+var now = time.Date(2025, 3, 10, 9, 0, 0, 0, time.Local)
+
+func testDeck() Deck {
+	return Deck{
+		Flashcards: []store.Flashcard{
+			{ID: 1, File: "/notes/go.md", Heading: "Go > Basics", Question: "What does `defer` do?", Answer: "Runs a call when\nthe function returns",
+				CardType: store.CardBasic, Tags: []string{"go"}, DueAt: now.AddDate(0, 0, 3), LastReviewedAt: now.AddDate(0, 0, -3),
+				Ease: 2.6, Repetitions: 3, IntervalDays: 6},
+			{ID: 2, File: "/notes/go.md", Heading: "Go > History", Question: "Go was released in {{c1::2009}} by {{c2::Google}}", CardType: store.CardCloze, ClozeIndex: 1},
+			{ID: 3, File: "/notes/go.md", Heading: "Go > History", Question: "Go was released in {{c1::2009}} by {{c2::Google}}", CardType: store.CardCloze, ClozeIndex: 2},
+			{ID: 4, File: "anki:Spanish", Question: "perro", Answer: "dog", CardType: store.CardBasic, Tags: []string{"animals", "spanish"}},
+		},
+		Reviews: map[int][]store.Review{
+			1: {
+				{FlashcardID: 1, ReviewedAt: now.AddDate(0, 0, -9), Grade: 3, ResponseTime: 4 * time.Second, IntervalAfter: 1},
+				{FlashcardID: 1, ReviewedAt: now.AddDate(0, 0, -3), Grade: 4, ResponseTime: 2 * time.Second, IntervalBefore: 1, IntervalAfter: 6},
+			},
+		},
+	}
+}
+
+func TestFilter(t *testing.T) {
+	cards := testDeck().Flashcards
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []int
+	}{
+		{"everything", Filter{}, []int{1, 2, 3, 4}},
+		{"due", Filter{DueOnly: true}, []int{2, 3, 4}},
+		{"directory", Filter{Files: []string{"/notes/"}}, []int{1, 2, 3}},
+		{"deck", Filter{Files: []string{"anki:Spanish"}}, []int{4}},
+		{"tags", Filter{Tags: []string{"#Go", "animals"}}, []int{1, 4}},
+		{"combined", Filter{Files: []string{"/notes/go.md"}, Tags: []string{"go"}, DueOnly: true}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ids []int
+			for _, fc := range tt.filter.Apply(cards, now) {
+				ids = append(ids, fc.ID)
+			}
+			if len(ids) != len(tt.want) {
+				t.Fatalf("Apply() = %v, want %v", ids, tt.want)
+			}
+			for i := range ids {
+				if ids[i] != tt.want[i] {
+					t.Fatalf("Apply() = %v, want %v", ids, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteCSV_RoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, testDeck(), '\t'); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+	if lines := strings.Count(buf.String(), "\n"); lines != 5 {
+		t.Errorf("Expected a header and 3 notes over 5 lines, got %d:\n%s", lines, buf.String())
+	}
+
+	// The export can be imported again with the default columns
+	opts := importer.CSVOptions{Comma: '\t', Header: true, Question: "1", Answer: "2", Tags: "tags"}
+	cards, err := importer.ReadCSV(&buf, "export.tsv", opts)
+	if err != nil {
+		t.Fatalf("ReadCSV() error = %v", err)
+	}
+	if len(cards) != 4 || cards[0].Answer != "Runs a call when\nthe function returns" || !cards[2].IsCloze() || len(cards[3].Tags) != 2 {
+		t.Errorf("Unexpected flashcards after a round trip: %+v", cards)
+	}
+}
+
+func TestWriteJSONL(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJSONL(&buf, testDeck()); err != nil {
+		t.Fatalf("WriteJSONL() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Expected one line per flashcard, got %d", len(lines))
+	}
+
+	var first map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatalf("Invalid JSON %q: %v", lines[0], err)
+	}
+	reviews, _ := first["reviews"].([]interface{})
+	if first["interval_days"] != 6.0 || first["due_at"] == nil || len(reviews) != 2 {
+		t.Errorf("Expected the scheduling and history, got %v", first)
+	}
+	if review := reviews[1].(map[string]interface{}); review["grade"] != 4.0 || review["response_ms"] != 2000.0 {
+		t.Errorf("Unexpected review %v", review)
+	}
+
+	var cloze map[string]interface{}
+	_ = json.Unmarshal([]byte(lines[2]), &cloze)
+	if cloze["due_at"] != nil || cloze["cloze_index"] != 2.0 || len(cloze["tags"].([]interface{})) != 0 {
+		t.Errorf("Expected a new cloze flashcard without a due date, got %v", cloze)
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, testDeck()); err != nil {
+		t.Fatalf("WriteMarkdown() error = %v", err)
+	}
+	want := "# Flashcards\n" +
+		"\n## /notes/go.md\n" +
+		"\n### Go > Basics\n" +
+		"\nQ: What does `defer` do?\nA: Runs a call when\nthe function returns\nTags: #go\n" +
+		"\n### Go > History\n" +
+		"\nGo was released in {{c1::2009}} by {{c2::Google}}\n" +
+		"\n## anki:Spanish\n" +
+		"\nQ: perro\nA: dog\nTags: #animals #spanish\n"
+	if got := buf.String(); got != want {
+		t.Errorf("WriteMarkdown() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,92 @@
+package exporter
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// jsonFlashcard is the JSON lines representation of a flashcard
+type jsonFlashcard struct {
+	ID             int          `json:"id"`
+	File           string       `json:"file"`
+	Deck           string       `json:"deck,omitempty"`
+	Heading        string       `json:"heading,omitempty"`
+	Type           string       `json:"type"`
+	Question       string       `json:"question"`
+	Answer         string       `json:"answer"`
+	ClozeIndex     int          `json:"cloze_index,omitempty"`
+	Tags           []string     `json:"tags"`
+	DueAt          *time.Time   `json:"due_at,omitempty"`
+	LastReviewedAt *time.Time   `json:"last_reviewed_at,omitempty"`
+	Ease           float64      `json:"ease"`
+	Repetitions    int          `json:"repetitions"`
+	Lapses         int          `json:"lapses"`
+	Stability      float64      `json:"stability,omitempty"`
+	Difficulty     float64      `json:"difficulty,omitempty"`
+	IntervalDays   int          `json:"interval_days"`
+	Reviews        []jsonReview `json:"reviews"`
+}
+
+// jsonReview is the JSON lines representation of a graded review
+type jsonReview struct {
+	ReviewedAt     time.Time `json:"reviewed_at"`
+	Grade          int       `json:"grade"`
+	ResponseMS     int64     `json:"response_ms"`
+	IntervalBefore int       `json:"interval_before"`
+	IntervalAfter  int       `json:"interval_after"`
+	Relearning     bool      `json:"relearning,omitempty"`
+}
+
+// WriteJSONL writes one JSON object per line for every flashcard, holding its
+// content, scheduling state and review history
+func WriteJSONL(w io.Writer, deck Deck) error {
+	enc := json.NewEncoder(w)
+	for _, fc := range deck.Flashcards {
+		record := jsonFlashcard{
+			ID:             fc.ID,
+			File:           fc.File,
+			Deck:           fc.Deck,
+			Heading:        fc.Heading,
+			Type:           fc.CardType,
+			Question:       fc.Question,
+			Answer:         fc.Answer,
+			ClozeIndex:     fc.ClozeIndex,
+			Tags:           fc.Tags,
+			DueAt:          timeOrNil(fc.DueAt),
+			LastReviewedAt: timeOrNil(fc.LastReviewedAt),
+			Ease:           fc.Ease,
+			Repetitions:    fc.Repetitions,
+			Lapses:         fc.Lapses,
+			Stability:      fc.Stability,
+			Difficulty:     fc.Difficulty,
+			IntervalDays:   fc.IntervalDays,
+			Reviews:        make([]jsonReview, 0, len(deck.Reviews[fc.ID])),
+		}
+		if record.Tags == nil {
+			record.Tags = []string{}
+		}
+		for _, r := range deck.Reviews[fc.ID] {
+			record.Reviews = append(record.Reviews, jsonReview{
+				ReviewedAt:     r.ReviewedAt,
+				Grade:          r.Grade,
+				ResponseMS:     r.ResponseTime.Milliseconds(),
+				IntervalBefore: r.IntervalBefore,
+				IntervalAfter:  r.IntervalAfter,
+				Relearning:     r.Relearning,
+			})
+		}
+		if err := enc.Encode(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// timeOrNil leaves unset times out of the JSON
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,42 @@
+package exporter
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// WriteMarkdown writes the flashcards as a Markdown document with a section
+// per source file and a subsection per heading
+// Question/answer flashcards use the "Q:"/"A:" lines catv generates, and cloze
+// texts are written as paragraphs with their deletions, as in notes.
+func WriteMarkdown(w io.Writer, deck Deck) error {
+	bw := bufio.NewWriter(w)
+	_, _ = bw.WriteString("# Flashcards\n")
+
+	file, heading := "", ""
+	for i, n := range notes(deck.Flashcards) {
+		fc := n.first()
+		if i == 0 || fc.File != file {
+			file, heading = fc.File, ""
+			_, _ = bw.WriteString("\n## " + file + "\n")
+		}
+		if fc.Heading != heading {
+			heading = fc.Heading
+			_, _ = bw.WriteString("\n### " + heading + "\n")
+		}
+		_, _ = bw.WriteString("\n")
+		if fc.IsCloze() {
+			_, _ = bw.WriteString(fc.Question + "\n")
+			if fc.Answer != "" {
+				_, _ = bw.WriteString("\n" + fc.Answer + "\n")
+			}
+		} else {
+			_, _ = bw.WriteString("Q: " + fc.Question + "\nA: " + fc.Answer + "\n")
+		}
+		if len(fc.Tags) > 0 {
+			_, _ = bw.WriteString("Tags: #" + strings.Join(fc.Tags, " #") + "\n")
+		}
+	}
+	return bw.Flush()
+}
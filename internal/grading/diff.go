@@ -0,0 +1,67 @@
+package grading
+
+// Op is the kind of change in a diff segment
+type Op int
+
+const (
+	Equal  Op = iota // Typed correctly
+	Insert           // Missing from the typed answer
+	Delete           // Typed but not in the answer
+)
+
+// Segment is a run of characters sharing the same Op
+type Segment struct {
+	Op   Op
+	Text string
+}
+
+// Diff returns the character-level differences that turn typed into expected
+// Comparison is exact, so the diff also shows case and spacing mistakes
+func Diff(typed, expected string) []Segment {
+	a, b := []rune(typed), []rune(expected)
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var segments []Segment
+	add := func(op Op, r rune) {
+		if n := len(segments); n > 0 && segments[n-1].Op == op {
+			segments[n-1].Text += string(r)
+			return
+		}
+		segments = append(segments, Segment{Op: op, Text: string(r)})
+	}
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			add(Equal, a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			add(Delete, a[i])
+			i++
+		default:
+			add(Insert, b[j])
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		add(Delete, a[i])
+	}
+	for ; j < len(b); j++ {
+		add(Insert, b[j])
+	}
+	return segments
+}
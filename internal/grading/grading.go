@@ -0,0 +1,73 @@
+// Package grading compares typed answers with the stored answer and suggests
+// a grade from how close they are
+package grading
+
+import (
+	"strings"
+	"unicode"
+
+	"catv/internal/scheduler"
+)
+
+// HardThreshold is the minimum similarity of an answer with small mistakes,
+// e.g. a typo in a flag name; anything below is graded Again
+const HardThreshold = 0.8
+
+// Normalize lowercases s, collapses runs of whitespace into a single space and
+// trims surrounding whitespace and trailing sentence punctuation, so only
+// differences that matter are graded
+func Normalize(s string) string {
+	s = strings.Join(strings.Fields(strings.ToLower(s)), " ")
+	return strings.TrimRightFunc(s, func(r rune) bool {
+		return r == '.' || r == '!' || unicode.IsSpace(r)
+	})
+}
+
+// Similarity returns how close typed is to expected after normalizing both,
+// from 0 (nothing in common) to 1 (identical)
+func Similarity(typed, expected string) float64 {
+	a, b := []rune(Normalize(typed)), []rune(Normalize(expected))
+	longest := max(len(a), len(b))
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(distance(a, b))/float64(longest)
+}
+
+// Suggest proposes a grade for a typed answer: Good for a match, Hard for small
+// mistakes and Again otherwise
+// Easy is left to the user, since typing does not tell how hard recalling was
+func Suggest(typed, expected string) (scheduler.Grade, float64) {
+	similarity := Similarity(typed, expected)
+	switch {
+	case strings.TrimSpace(typed) == "":
+		return scheduler.Again, 0
+	case similarity == 1:
+		return scheduler.Good, similarity
+	case similarity >= HardThreshold:
+		return scheduler.Hard, similarity
+	default:
+		return scheduler.Again, similarity
+	}
+}
+
+// distance is the Levenshtein edit distance between a and b
+func distance(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,78 @@
+package grading
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"catv/internal/scheduler"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]string{
+		"  Git  Rebase  -i ": "git rebase -i",
+		"Paris.":             "paris",
+		"line one\nline two": "line one line two",
+		"--force-with-lease": "--force-with-lease",
+	}
+	for input, expected := range tests {
+		if got := Normalize(input); got != expected {
+			t.Errorf("Normalize(%q) = %q, expected %q", input, got, expected)
+		}
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	if got := Similarity("kitten", "sitting"); math.Abs(got-(1-3.0/7)) > 1e-9 {
+		t.Errorf("Similarity(kitten, sitting) = %v", got)
+	}
+	if Similarity("", "") != 1 || Similarity("abc", "") != 0 {
+		t.Error("Unexpected similarity for empty strings")
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	tests := []struct {
+		typed, expected string
+		grade           scheduler.Grade
+	}{
+		{"git push --force-with-lease", "git push --force-with-lease", scheduler.Good},
+		{"Git push  --force-with-lease.", "git push --force-with-lease", scheduler.Good},
+		{"git push --force-with-leese", "git push --force-with-lease", scheduler.Hard},
+		{"git push -f", "git push --force-with-lease", scheduler.Again},
+		{"   ", "anything", scheduler.Again},
+	}
+	for _, tt := range tests {
+		if grade, _ := Suggest(tt.typed, tt.expected); grade != tt.grade {
+			t.Errorf("Suggest(%q, %q) = %s, expected %s", tt.typed, tt.expected, grade, tt.grade)
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	got := Diff("git comit -m", "git commit -am")
+	expected := []Segment{
+		{Equal, "git com"},
+		{Insert, "m"},
+		{Equal, "it -"},
+		{Insert, "a"},
+		{Equal, "m"},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Diff() = %+v, expected %+v", got, expected)
+	}
+
+	got = Diff("ls -la", "ls -al")
+	var typed, answer string
+	for _, s := range got {
+		if s.Op != Insert {
+			typed += s.Text
+		}
+		if s.Op != Delete {
+			answer += s.Text
+		}
+	}
+	if typed != "ls -la" || answer != "ls -al" {
+		t.Errorf("Diff() does not rebuild both strings: %q, %q", typed, answer)
+	}
+}
@@ -0,0 +1,261 @@
+package importer
+
+import (
+	"archive/zip"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"catv/internal/store"
+
+	_ "github.com/mattn/go-sqlite3" // SQLite driver for the collection inside the package
+)
+
+// AnkiPrefix starts the origin recorded in the file column of flashcards
+// imported from Anki, followed by the deck name
+const AnkiPrefix = "anki:"
+
+// maxCollectionSize bounds how much is extracted from a package, guarding
+// against archives that decompress to far more than they hold
+const maxCollectionSize = 2 << 30
+
+// ErrUnsupportedPackage is returned for packages without a collection catv can read
+var ErrUnsupportedPackage = errors.New("unsupported Anki package")
+
+// Anki note types, cards types and the field separator of the notes table
+const (
+	ankiClozeModel  = 1
+	ankiNewCard     = 0
+	ankiReviewCard  = 2
+	ankiFieldSep    = "\x1f"
+	ankiTimestamped = 1_000_000_000 // Due values above this are Unix timestamps rather than day numbers
+)
+
+// AnkiOrigin returns the file recorded for flashcards imported from an Anki deck
+func AnkiOrigin(deck string) string {
+	return AnkiPrefix + deck
+}
+
+// ankiModel is a note type of the collection's models JSON
+type ankiModel struct {
+	Type int `json:"type"`
+}
+
+// ankiDeck is a deck of the collection's decks JSON
+type ankiDeck struct {
+	Name string `json:"name"`
+}
+
+// ankiCard is a row of the cards table joined with its note
+type ankiCard struct {
+	deck    int64
+	ord     int
+	typ     int
+	due     int64
+	ivl     int
+	factor  int
+	reps    int
+	lapses  int
+	data    string
+	model   int64
+	fields  []string
+	noteTag string
+}
+
+// ReadAnki reads the cards of an Anki package (.apkg) as flashcards
+// Each flashcard records its deck as origin and keeps the card's scheduling,
+// so reviews continue where they left off in Anki. Notes of standard types
+// become one question/answer flashcard from their first two fields; cloze
+// notes become one cloze flashcard per deletion.
+func ReadAnki(path string) ([]store.Flashcard, error) {
+	collection, err := extractCollection(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = os.Remove(collection)
+	}()
+
+	db, err := sql.Open("sqlite3", "file:"+collection+"?mode=ro")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open Anki collection: %w", err)
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+
+	var created int64
+	var modelsJSON, decksJSON string
+	if err := db.QueryRow("SELECT crt, models, decks FROM col").Scan(&created, &modelsJSON, &decksJSON); err != nil {
+		return nil, fmt.Errorf("%w: failed to read collection: %w", ErrUnsupportedPackage, err)
+	}
+	var models map[int64]ankiModel
+	if err := json.Unmarshal([]byte(modelsJSON), &models); err != nil {
+		return nil, fmt.Errorf("%w: failed to parse note types: %w", ErrUnsupportedPackage, err)
+	}
+	var decks map[int64]ankiDeck
+	if err := json.Unmarshal([]byte(decksJSON), &decks); err != nil {
+		return nil, fmt.Errorf("%w: failed to parse decks: %w", ErrUnsupportedPackage, err)
+	}
+
+	cards, err := readAnkiCards(db)
+	if err != nil {
+		return nil, err
+	}
+
+	flashcards := make([]store.Flashcard, 0, len(cards))
+	for _, c := range cards {
+		fc, ok := c.flashcard(models[c.model].Type == ankiClozeModel)
+		if !ok {
+			continue
+		}
+		fc.File = AnkiOrigin(decks[c.deck].Name)
+		fc.Deck = decks[c.deck].Name
+		fc.Tags = strings.Fields(c.noteTag)
+		c.schedule(&fc, time.Unix(created, 0))
+		flashcards = append(flashcards, fc)
+	}
+	return flashcards, nil
+}
+
+// readAnkiCards returns every card of the collection with its note, in note order
+func readAnkiCards(db *sql.DB) ([]ankiCard, error) {
+	// Cards in filtered decks keep their home deck and due date in odid and odue
+	rows, err := db.Query(`SELECT CASE WHEN c.odid != 0 THEN c.odid ELSE c.did END,
+			c.ord, c.type, CASE WHEN c.odid != 0 THEN c.odue ELSE c.due END,
+			c.ivl, c.factor, c.reps, c.lapses, c.data, n.mid, n.flds, n.tags
+		FROM cards c JOIN notes n ON n.id = c.nid ORDER BY n.id, c.ord`)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read cards: %w", ErrUnsupportedPackage, err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var cards []ankiCard
+	for rows.Next() {
+		var c ankiCard
+		var fields string
+		if err := rows.Scan(&c.deck, &c.ord, &c.typ, &c.due, &c.ivl, &c.factor, &c.reps, &c.lapses, &c.data, &c.model, &fields, &c.noteTag); err != nil {
+			return nil, fmt.Errorf("failed to scan Anki card: %w", err)
+		}
+		c.fields = strings.Split(fields, ankiFieldSep)
+		cards = append(cards, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating Anki cards: %w", err)
+	}
+	return cards, nil
+}
+
+// flashcard maps a card's note fields onto a flashcard
+// It reports false for cards catv cannot show, such as the reverse cards of
+// standard notes and notes with an empty front
+func (c ankiCard) flashcard(isCloze bool) (store.Flashcard, bool) {
+	question := htmlToMarkdown(c.fields[0])
+	if question == "" {
+		return store.Flashcard{}, false
+	}
+	var extra []string
+	for _, f := range c.fields[1:] {
+		if f = htmlToMarkdown(f); f != "" {
+			extra = append(extra, f)
+		}
+	}
+	answer := strings.Join(extra, "\n\n")
+
+	if isCloze {
+		return store.Flashcard{Question: question, Answer: answer, CardType: store.CardCloze, ClozeIndex: c.ord + 1}, true
+	}
+	if c.ord != 0 || answer == "" {
+		return store.Flashcard{}, false
+	}
+	return store.Flashcard{Question: question, Answer: answer, CardType: store.CardBasic}, true
+}
+
+// schedule copies the card's scheduling onto fc; new cards stay due immediately
+// Review due dates count days from the collection's creation, learning due
+// dates are timestamps
+func (c ankiCard) schedule(fc *store.Flashcard, created time.Time) {
+	if c.typ == ankiNewCard {
+		return
+	}
+	if c.due > ankiTimestamped {
+		fc.DueAt = time.Unix(c.due, 0)
+	} else {
+		fc.DueAt = store.DueAfter(created, int(c.due))
+	}
+	fc.Lapses = c.lapses
+	if c.factor > 0 {
+		fc.Ease = float64(c.factor) / 1000
+	}
+	if c.typ == ankiReviewCard && c.ivl > 0 {
+		fc.IntervalDays = c.ivl
+		fc.LastReviewedAt = fc.DueAt.AddDate(0, 0, -c.ivl)
+		// Graduated cards grow from their interval on the next review
+		fc.Repetitions = max(c.reps-c.lapses, 2)
+	}
+	// Collections using FSRS keep the memory state in the card data
+	var memory struct {
+		Stability  float64 `json:"s"`
+		Difficulty float64 `json:"d"`
+	}
+	if c.data != "" && json.Unmarshal([]byte(c.data), &memory) == nil {
+		fc.Stability, fc.Difficulty = memory.Stability, memory.Difficulty
+	}
+}
+
+// extractCollection copies the SQLite collection out of a package into a
+// temporary file and returns its path; the caller removes it
+func extractCollection(path string) (string, error) {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open Anki package: %w", err)
+	}
+	defer func() {
+		_ = r.Close()
+	}()
+
+	files := make(map[string]*zip.File, len(r.File))
+	for _, f := range r.File {
+		files[f.Name] = f
+	}
+	// Newer packages hold a compressed collection next to a placeholder one
+	// asking to upgrade Anki; only the legacy formats can be read
+	f := files["collection.anki21"]
+	if f == nil {
+		f = files["collection.anki2"]
+	}
+	if f == nil || files["collection.anki21b"] != nil && files["collection.anki21"] == nil {
+		return "", fmt.Errorf("%w: export the deck from Anki with \"Support older Anki versions\" checked", ErrUnsupportedPackage)
+	}
+
+	src, err := f.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to read Anki collection: %w", err)
+	}
+	defer func() {
+		_ = src.Close()
+	}()
+	dst, err := os.CreateTemp("", "catv-import-*.anki2")
+	if err != nil {
+		return "", fmt.Errorf("failed to extract Anki collection: %w", err)
+	}
+	n, err := io.Copy(dst, io.LimitReader(src, maxCollectionSize+1))
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && n > maxCollectionSize {
+		err = errors.New("collection is too large")
+	}
+	if err != nil {
+		_ = os.Remove(dst.Name())
+		return "", fmt.Errorf("failed to extract Anki collection: %w", err)
+	}
+	return dst.Name(), nil
+}
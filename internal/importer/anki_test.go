@@ -0,0 +1,122 @@
+package importer
+
+import (
+	"archive/zip"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"catv/internal/store"
+)
+
+// ankiFixtureSchema is the part of Anki's legacy collection schema ReadAnki uses
+var ankiFixtureSchema = []string{
+	`CREATE TABLE col (id integer primary key, crt integer not null, models text not null, decks text not null)`,
+	`CREATE TABLE notes (id integer primary key, mid integer not null, tags text not null, flds text not null)`,
+	`CREATE TABLE cards (id integer primary key, nid integer not null, did integer not null, ord integer not null,
+		type integer not null, queue integer not null, due integer not null, ivl integer not null, factor integer not null,
+		reps integer not null, lapses integer not null, odue integer not null, odid integer not null, data text not null)`,
+}
+
+// writeAnkiPackage builds an .apkg holding a collection created by running
+// statements after the schema, stored under the given name in the archive
+func writeAnkiPackage(t *testing.T, name string, statements ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	collection := filepath.Join(dir, "collection")
+	db, err := sql.Open("sqlite3", collection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stmt := range append(ankiFixtureSchema, statements...) {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	_ = db.Close()
+
+	data, err := os.ReadFile(collection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "deck.apkg")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	w, err := zw.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	_ = f.Close()
+	return path
+}
+
+func TestReadAnki(t *testing.T) {
+	created := time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local)
+	path := writeAnkiPackage(t, "collection.anki2",
+		`INSERT INTO col VALUES (1, `+strconv.FormatInt(created.Unix(), 10)+`,
+			'{"1": {"type": 0}, "2": {"type": 1}}',
+			'{"10": {"name": "Spanish::Verbs"}, "11": {"name": "Filtered"}}')`,
+		// A basic note with a reverse card, an empty note and a cloze note
+		`INSERT INTO notes VALUES (100, 1, ' verbs spanish ', 'comer'||char(31)||'to <b>eat</b><br>e.g. <i>como</i>')`,
+		`INSERT INTO notes VALUES (101, 1, '', ''||char(31)||'orphan')`,
+		`INSERT INTO notes VALUES (102, 2, 'grammar', '{{c1::Yo}} {{c2::como}}'||char(31)||'')`,
+		// In review, due 30 days after the collection was created
+		`INSERT INTO cards VALUES (1, 100, 10, 0, 2, 2, 30, 12, 2300, 7, 1, 0, 0, '{"s": 14.5, "d": 6.2}')`,
+		`INSERT INTO cards VALUES (2, 100, 10, 1, 0, 0, 1, 0, 0, 0, 0, 0, 0, '')`,
+		`INSERT INTO cards VALUES (3, 101, 10, 0, 0, 0, 2, 0, 0, 0, 0, 0, 0, '')`,
+		// New, and learning in a filtered deck with its home deck kept in odid
+		`INSERT INTO cards VALUES (4, 102, 10, 0, 0, 0, 3, 0, 0, 0, 0, 0, 0, '')`,
+		`INSERT INTO cards VALUES (5, 102, 11, 1, 1, 1, 0, 0, 2500, 1, 0, 1735689600, 10, '')`,
+	)
+
+	cards, err := ReadAnki(path)
+	if err != nil {
+		t.Fatalf("ReadAnki() error = %v", err)
+	}
+	if len(cards) != 3 {
+		t.Fatalf("Expected 3 flashcards, got %d: %+v", len(cards), cards)
+	}
+
+	basic := cards[0]
+	if basic.File != "anki:Spanish::Verbs" || basic.Question != "comer" || basic.Answer != "to **eat**\ne.g. *como*" || basic.CardType != store.CardBasic {
+		t.Errorf("Unexpected basic flashcard %+v", basic)
+	}
+	if !basic.DueAt.Equal(store.DueAfter(created, 30)) || basic.IntervalDays != 12 || basic.Ease != 2.3 || basic.Lapses != 1 || basic.Repetitions != 6 {
+		t.Errorf("Expected the review scheduling to be kept, got %+v", basic)
+	}
+	if basic.Stability != 14.5 || basic.Difficulty != 6.2 || len(basic.Tags) != 2 {
+		t.Errorf("Expected the FSRS state and tags to be kept, got %+v", basic)
+	}
+
+	first, second := cards[1], cards[2]
+	if !first.IsCloze() || first.ClozeIndex != 1 || !first.DueAt.IsZero() {
+		t.Errorf("Expected a new cloze flashcard for deletion 1, got %+v", first)
+	}
+	if !second.IsCloze() || second.ClozeIndex != 2 || second.File != "anki:Spanish::Verbs" || !second.DueAt.Equal(time.Unix(1735689600, 0)) {
+		t.Errorf("Expected a learning cloze flashcard in its home deck, got %+v", second)
+	}
+}
+
+func TestReadAnki_Unsupported(t *testing.T) {
+	path := writeAnkiPackage(t, "collection.anki21b")
+	if _, err := ReadAnki(path); !errors.Is(err, ErrUnsupportedPackage) {
+		t.Errorf("Expected ErrUnsupportedPackage for a zstd collection, got %v", err)
+	}
+
+	if _, err := ReadAnki(filepath.Join(t.TempDir(), "missing.apkg")); err == nil {
+		t.Error("Expected an error for a missing package")
+	}
+}
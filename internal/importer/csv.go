@@ -0,0 +1,156 @@
+package importer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"catv/internal/store"
+)
+
+// CSVOptions describes the layout of a delimited file
+// Columns are 1-based numbers, or header names when the file has a header row
+type CSVOptions struct {
+	Comma    rune   // Field delimiter, ',' or '\t'
+	Header   bool   // The first row names the columns instead of holding a flashcard
+	Question string // Column of the question
+	Answer   string // Column of the answer
+	Tags     string // Optional column of space or comma separated tags
+}
+
+// ReadCSV reads one flashcard per row of a CSV or TSV file, recording origin
+// as each flashcard's file
+// Questions with cloze deletions become one cloze flashcard per deletion. The
+// "#separator:" and "#html:" lines that start Anki's text exports are
+// understood, and HTML fields are converted to markdown.
+func ReadCSV(r io.Reader, origin string, opts CSVOptions) ([]store.Flashcard, error) {
+	br := bufio.NewReader(r)
+	isHTML, err := readAnkiHeaders(br, &opts)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(br)
+	reader.Comma = opts.Comma
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	var header []string
+	if opts.Header {
+		if header, err = reader.Read(); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("failed to read header: %w", err)
+		}
+	}
+	question, err := columnIndex(opts.Question, header)
+	if err != nil {
+		return nil, fmt.Errorf("question column: %w", err)
+	}
+	answer, err := columnIndex(opts.Answer, header)
+	if err != nil {
+		return nil, fmt.Errorf("answer column: %w", err)
+	}
+	tags := -1
+	if opts.Tags != "" {
+		if tags, err = columnIndex(opts.Tags, header); err != nil {
+			return nil, fmt.Errorf("tags column: %w", err)
+		}
+	}
+
+	field := func(record []string, i int) string {
+		if i < 0 || i >= len(record) {
+			return ""
+		}
+		if isHTML {
+			return htmlToMarkdown(record[i])
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var flashcards []store.Flashcard
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read row: %w", err)
+		}
+		fc := store.Flashcard{File: origin, Question: field(record, question), Answer: field(record, answer)}
+		if fc.Question == "" {
+			continue
+		}
+		if t := field(record, tags); t != "" {
+			fc.Tags = strings.FieldsFunc(t, func(r rune) bool { return r == ' ' || r == ',' })
+		}
+		flashcards = append(flashcards, expandCloze(fc)...)
+	}
+	return flashcards, nil
+}
+
+// readAnkiHeaders consumes the "#key:value" lines at the start of an Anki text
+// export, applying the separator and reporting whether fields hold HTML
+func readAnkiHeaders(br *bufio.Reader, opts *CSVOptions) (bool, error) {
+	isHTML := false
+	for {
+		peek, err := br.Peek(1)
+		if err != nil || peek[0] != '#' {
+			return isHTML, nil
+		}
+		line, err := br.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return false, fmt.Errorf("failed to read header: %w", err)
+		}
+		key, value, ok := strings.Cut(strings.TrimSpace(strings.TrimPrefix(line, "#")), ":")
+		if !ok {
+			return false, fmt.Errorf("unexpected line %q before the first row", strings.TrimSpace(line))
+		}
+		value = strings.TrimSpace(value)
+		switch key {
+		case "separator":
+			if sep, ok := ankiSeparators[strings.ToLower(value)]; ok {
+				opts.Comma = sep
+			}
+		case "html":
+			isHTML = value == "true"
+		case "tags column":
+			if opts.Tags == "" {
+				opts.Tags = value
+			}
+		}
+	}
+}
+
+// ankiSeparators maps the separator names of Anki text exports to delimiters
+var ankiSeparators = map[string]rune{
+	"tab":       '\t',
+	"comma":     ',',
+	"semicolon": ';',
+	"pipe":      '|',
+	"space":     ' ',
+}
+
+// columnIndex resolves a 1-based column number or a header name to an index
+func columnIndex(column string, header []string) (int, error) {
+	if n, err := strconv.Atoi(column); err == nil {
+		if n < 1 {
+			return 0, fmt.Errorf("column numbers start at 1, got %d", n)
+		}
+		return n - 1, nil
+	}
+	if header == nil {
+		return 0, fmt.Errorf("%q is not a column number; use --header to refer to columns by name", column)
+	}
+	for i, name := range header {
+		if strings.EqualFold(strings.TrimSpace(name), column) {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("no column named %q", column)
+}
@@ -0,0 +1,87 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	"catv/internal/store"
+)
+
+func TestReadCSV(t *testing.T) {
+	input := `Front,Back,Labels
+"What does ""defer"" do?","Runs a call when
+the function returns","go, keywords"
+,skipped row without a question,
+Go was released in {{c1::2009}} by {{c2::Google}},,history
+`
+	opts := CSVOptions{Comma: ',', Header: true, Question: "front", Answer: "2", Tags: "Labels"}
+	cards, err := ReadCSV(strings.NewReader(input), "/notes/go.csv", opts)
+	if err != nil {
+		t.Fatalf("ReadCSV() error = %v", err)
+	}
+	if len(cards) != 3 {
+		t.Fatalf("Expected 3 flashcards, got %d: %+v", len(cards), cards)
+	}
+	if cards[0].Question != `What does "defer" do?` || cards[0].Answer != "Runs a call when\nthe function returns" || cards[0].File != "/notes/go.csv" {
+		t.Errorf("Unexpected flashcard %+v", cards[0])
+	}
+	if len(cards[0].Tags) != 2 || cards[0].Tags[1] != "keywords" {
+		t.Errorf("Expected two tags, got %q", cards[0].Tags)
+	}
+	if !cards[1].IsCloze() || cards[1].ClozeIndex != 1 || cards[2].ClozeIndex != 2 {
+		t.Errorf("Expected one cloze flashcard per deletion, got %+v", cards[1:])
+	}
+}
+
+func TestReadCSV_AnkiExport(t *testing.T) {
+	input := "#separator:tab\n#html:true\n#tags column:3\nperro\tthe <b>dog</b>&nbsp;\tanimals\n"
+	cards, err := ReadCSV(strings.NewReader(input), "/exports/spanish.txt", CSVOptions{Comma: ',', Question: "1", Answer: "2"})
+	if err != nil {
+		t.Fatalf("ReadCSV() error = %v", err)
+	}
+	if len(cards) != 1 || cards[0].Answer != "the **dog**" || len(cards[0].Tags) != 1 || cards[0].CardType != store.CardBasic {
+		t.Errorf("Expected the export headers to be applied, got %+v", cards)
+	}
+}
+
+func TestReadCSV_Columns(t *testing.T) {
+	for _, opts := range []CSVOptions{
+		{Comma: ',', Question: "front", Answer: "2"},               // Names need a header
+		{Comma: ',', Header: true, Question: "1", Answer: "Reply"}, // Unknown name
+		{Comma: ',', Question: "0", Answer: "1"},                   // Columns start at 1
+	} {
+		if _, err := ReadCSV(strings.NewReader("Front,Back\nQ,A\n"), "x.csv", opts); err == nil {
+			t.Errorf("Expected an error for columns %q/%q", opts.Question, opts.Answer)
+		}
+	}
+}
+
+func TestHTMLToMarkdown(t *testing.T) {
+	tests := map[string]string{
+		"plain":                                    "plain",
+		"<div>one</div><div>two</div>":             "one\ntwo",
+		"a &lt; b &amp;&amp; <code>x</code>":       "a < b && `x`",
+		`<img src="cat.png">Cat[sound:meow.mp3]`:   "Cat",
+		"<p>first</p><br><br><br><p>second</p>":    "first\n\nsecond",
+		`<span style="color: red">red</span> text`: "red text",
+	}
+	for input, want := range tests {
+		if got := htmlToMarkdown(input); got != want {
+			t.Errorf("htmlToMarkdown(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestDeduplicate(t *testing.T) {
+	existing := []store.Flashcard{{File: "a.csv", Question: "Q1"}}
+	cards := []store.Flashcard{
+		{File: "a.csv", Question: "Q1"},
+		{File: "b.csv", Question: "Q1"},
+		{File: "a.csv", Question: "Q2"},
+		{File: "a.csv", Question: "Q2"},
+	}
+	fresh := Deduplicate(cards, existing)
+	if len(fresh) != 2 || fresh[0].File != "b.csv" || fresh[1].Question != "Q2" {
+		t.Errorf("Unexpected flashcards after deduplication: %+v", fresh)
+	}
+}
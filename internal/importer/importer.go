@@ -0,0 +1,86 @@
+// Package importer reads flashcards exported by other tools, such as Anki
+// packages and CSV/TSV files, into store flashcards
+package importer
+
+import (
+	"html"
+	"regexp"
+	"strings"
+
+	"catv/internal/cloze"
+	"catv/internal/store"
+)
+
+var (
+	// lineBreakTags end a line of text in Anki's HTML fields
+	lineBreakTags = regexp.MustCompile(`(?i)<br\s*/?>|</div>|</p>|</li>`)
+	boldTags      = regexp.MustCompile(`(?i)</?(?:b|strong)>`)
+	italicTags    = regexp.MustCompile(`(?i)</?(?:i|em)>`)
+	codeTags      = regexp.MustCompile(`(?i)</?code>`)
+	anyTag        = regexp.MustCompile(`<[^>]*>`)
+	// soundTags are Anki's audio references, which cannot be reviewed in a terminal
+	soundTags  = regexp.MustCompile(`\[sound:[^\]]*\]`)
+	blankLines = regexp.MustCompile(`\n{3,}`)
+)
+
+// htmlToMarkdown converts the HTML of an imported field to markdown text,
+// keeping line breaks and bold, italic and code formatting and dropping
+// everything else, such as images and styling
+func htmlToMarkdown(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = lineBreakTags.ReplaceAllString(s, "\n")
+	s = boldTags.ReplaceAllString(s, "**")
+	s = italicTags.ReplaceAllString(s, "*")
+	s = codeTags.ReplaceAllString(s, "`")
+	s = anyTag.ReplaceAllString(s, "")
+	s = soundTags.ReplaceAllString(s, "")
+	s = strings.ReplaceAll(html.UnescapeString(s), "\u00a0", " ")
+
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return strings.TrimSpace(blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
+
+// expandCloze returns one flashcard per deletion number when the question
+// holds cloze deletions, and the flashcard itself otherwise
+func expandCloze(fc store.Flashcard) []store.Flashcard {
+	indices := cloze.Indices(fc.Question)
+	if len(indices) == 0 {
+		fc.CardType = store.CardBasic
+		return []store.Flashcard{fc}
+	}
+	cards := make([]store.Flashcard, 0, len(indices))
+	for _, n := range indices {
+		card := fc
+		card.CardType = store.CardCloze
+		card.ClozeIndex = n
+		cards = append(cards, card)
+	}
+	return cards
+}
+
+// Deduplicate drops the flashcards that are already in existing, comparing
+// their origin, question and cloze deletion, so importing a file again only
+// adds new cards
+func Deduplicate(flashcards, existing []store.Flashcard) []store.Flashcard {
+	type key struct {
+		file, question string
+		clozeIndex     int
+	}
+	seen := make(map[key]bool, len(existing))
+	for _, fc := range existing {
+		seen[key{fc.File, fc.Question, fc.ClozeIndex}] = true
+	}
+	fresh := make([]store.Flashcard, 0, len(flashcards))
+	for _, fc := range flashcards {
+		k := key{fc.File, fc.Question, fc.ClozeIndex}
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		fresh = append(fresh, fc)
+	}
+	return fresh
+}
@@ -0,0 +1,69 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Fake is a deterministic provider that answers without a server
+// Respond, if set, produces each response; otherwise every request gets one
+// flashcard about the last line of the prompt, as JSON when a schema or JSON
+// is requested and as a Q:/A: pair otherwise
+// Responses are passed to OnToken a word at a time
+// It records the requests it receives and is safe for concurrent use
+type Fake struct {
+	Respond func(req Request) (string, error)
+
+	mu       sync.Mutex
+	requests []Request
+}
+
+// Name returns the identifier of the provider
+func (f *Fake) Name() string {
+	return NameFake
+}
+
+// Generate records req and returns the canned response
+func (f *Fake) Generate(ctx context.Context, req Request) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	f.mu.Lock()
+	f.requests = append(f.requests, req)
+	f.mu.Unlock()
+	respond := f.Respond
+	if respond == nil {
+		respond = defaultResponse
+	}
+	resp, err := respond(req)
+	if err == nil && req.OnToken != nil {
+		// Streamed a word at a time, like a model would
+		for _, token := range strings.SplitAfter(resp, " ") {
+			req.OnToken(token)
+		}
+	}
+	return resp, err
+}
+
+// defaultResponse is one flashcard about the last line of the prompt
+func defaultResponse(req Request) (string, error) {
+	lines := strings.Split(strings.TrimSpace(req.Prompt), "\n")
+	answer := strings.TrimSpace(lines[len(lines)-1])
+	question := "What does the note say?"
+	if req.Schema == nil && !req.JSON {
+		return fmt.Sprintf("Q: %s\nA: %s", question, answer), nil
+	}
+	card := map[string]string{"question": question, "answer": answer}
+	data, err := json.Marshal(map[string]any{"flashcards": []map[string]string{card}})
+	return string(data), err
+}
+
+// Requests returns the requests received so far
+func (f *Fake) Requests() []Request {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Request(nil), f.requests...)
+}
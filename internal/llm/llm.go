@@ -0,0 +1,114 @@
+// Package llm sends prompts to language models through interchangeable
+// providers: Ollama, OpenAI-compatible servers such as LM Studio, vLLM,
+// llama.cpp and LocalAI, and a deterministic fake for tests
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Request is a prompt for a model
+type Request struct {
+	Model  string
+	Prompt string
+	Schema json.RawMessage // JSON schema the response must follow (nil for free text)
+	JSON   bool            // Ask for any JSON object, for servers that reject schemas
+
+	// OnToken, if set, is called with each piece of the response as it arrives,
+	// before Generate returns the complete response
+	OnToken func(token string)
+}
+
+// LLMProvider sends requests to a model server
+type LLMProvider interface {
+	// Name returns the identifier used to select the provider in configuration
+	Name() string
+	// Generate returns the model's complete response to req
+	// It returns ErrStructuredUnsupported if the server rejects req.Schema
+	Generate(ctx context.Context, req Request) (string, error)
+}
+
+// ErrStructuredUnsupported is returned when the server or model rejects a JSON
+// schema, so the caller can fall back to plain text output
+var ErrStructuredUnsupported = errors.New("structured output is not supported by this model server")
+
+// Provider names accepted by New
+const (
+	NameOllama     = "ollama"      // Ollama's /api/generate
+	NameOllamaChat = "ollama-chat" // Ollama's /api/chat
+	NameOpenAI     = "openai"      // OpenAI-compatible /v1/chat/completions
+	NameFake       = "fake"        // Canned responses without a server
+)
+
+var registry = map[string]func(url, apiKey string) LLMProvider{
+	NameOllama:     func(url, _ string) LLMProvider { return NewOllama(url) },
+	NameOllamaChat: func(url, _ string) LLMProvider { return NewOllamaChat(url) },
+	NameOpenAI:     func(url, apiKey string) LLMProvider { return NewOpenAI(url, apiKey) },
+	NameFake:       func(_, _ string) LLMProvider { return &Fake{} },
+}
+
+// New returns the provider registered under name, talking to the server at url
+// The API key is only sent by providers that use one
+func New(name, url, apiKey string) (LLMProvider, error) {
+	factory, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown provider %q (available: %v)", name, Names())
+	}
+	return factory(url, apiKey), nil
+}
+
+// Names returns the sorted list of available provider names
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// baseURL strips the endpoint paths providers add themselves, so a server can
+// be configured either by its address or by the full endpoint
+func baseURL(url string) string {
+	url = strings.TrimRight(url, "/")
+	for _, suffix := range []string{"/api/generate", "/api/chat", "/v1/chat/completions", "/chat/completions", "/v1"} {
+		url = strings.TrimSuffix(url, suffix)
+	}
+	return url
+}
+
+// statusError describes a failed response, with the server's error message if any
+func statusError(status int, message string) error {
+	if message != "" {
+		return fmt.Errorf("unexpected status code: %d: %s", status, message)
+	}
+	return fmt.Errorf("unexpected status code: %d", status)
+}
+
+// errorMessage extracts the error reported in an error response body, either
+// as {"error": "..."} (Ollama) or {"error": {"message": "..."}} (OpenAI)
+func errorMessage(body io.Reader) string {
+	var payload struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if err := json.NewDecoder(io.LimitReader(body, 64*1024)).Decode(&payload); err != nil {
+		return ""
+	}
+	var message string
+	if json.Unmarshal(payload.Error, &message) == nil {
+		return message
+	}
+	var detail struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(payload.Error, &detail) == nil {
+		return detail.Message
+	}
+	return ""
+}
@@ -0,0 +1,69 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	for _, name := range Names() {
+		p, err := New(name, "http://localhost:11434", "")
+		if err != nil || p.Name() != name {
+			t.Errorf("New(%q) = %v, %v", name, p, err)
+		}
+	}
+	if _, err := New("gpt", "", ""); err == nil || !strings.Contains(err.Error(), "openai") {
+		t.Errorf("Expected an unknown provider to list the available ones, got %v", err)
+	}
+}
+
+func TestBaseURL(t *testing.T) {
+	tests := map[string]string{
+		"http://localhost:11434/api/generate":       "http://localhost:11434",
+		"http://localhost:11434/api/chat":           "http://localhost:11434",
+		"http://localhost:1234/v1/":                 "http://localhost:1234",
+		"http://localhost:8080/v1/chat/completions": "http://localhost:8080",
+		"http://localhost:8080":                     "http://localhost:8080",
+	}
+	for url, want := range tests {
+		if got := baseURL(url); got != want {
+			t.Errorf("baseURL(%q) = %q, want %q", url, got, want)
+		}
+	}
+}
+
+func TestFake(t *testing.T) {
+	fake := &Fake{}
+	lines, _ := fake.Generate(context.Background(), Request{Prompt: "Make cards\nMarkdown:\nGo has goroutines"})
+	structured, _ := fake.Generate(context.Background(), Request{Prompt: "Markdown:\nGo has goroutines", Schema: []byte(`{}`)})
+	if lines != "Q: What does the note say?\nA: Go has goroutines" {
+		t.Errorf("Unexpected line format response %q", lines)
+	}
+	if structured != `{"flashcards":[{"answer":"Go has goroutines","question":"What does the note say?"}]}` {
+		t.Errorf("Unexpected structured response %q", structured)
+	}
+
+	var tokens []string
+	streamed, _ := fake.Generate(context.Background(), Request{Prompt: "Go has goroutines", OnToken: func(token string) {
+		tokens = append(tokens, token)
+	}})
+	if len(tokens) < 2 || strings.Join(tokens, "") != streamed {
+		t.Errorf("Expected the response to be streamed in pieces, got %q", tokens)
+	}
+
+	fake.Respond = func(req Request) (string, error) { return "", errors.New("boom") }
+	if _, err := fake.Generate(context.Background(), Request{}); err == nil {
+		t.Error("Expected the canned error")
+	}
+	if n := len(fake.Requests()); n != 4 {
+		t.Errorf("Expected 4 recorded requests, got %d", n)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := fake.Generate(ctx, Request{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the cancellation to be reported, got %v", err)
+	}
+}
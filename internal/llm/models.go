@@ -0,0 +1,184 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Model is a model a server can run
+type Model struct {
+	Name          string    // Name to request the model by, e.g. "llama3.1:latest"
+	Size          int64     // Size on disk in bytes, 0 if unknown
+	ParameterSize string    // e.g. "8.0B", "" if unknown
+	Quantization  string    // e.g. "Q4_K_M", "" if unknown
+	ModifiedAt    time.Time // When the model was pulled, zero if unknown
+}
+
+// ModelLister is implemented by providers that can list the models of their server
+type ModelLister interface {
+	Models(ctx context.Context) ([]Model, error)
+}
+
+// HasModel reports whether name is one of models
+// As with Ollama, a name without a tag refers to the "latest" tag
+func HasModel(models []Model, name string) bool {
+	for _, m := range models {
+		if m.Name == name || (!strings.Contains(name, ":") && m.Name == name+":latest") {
+			return true
+		}
+	}
+	return false
+}
+
+// ModelNames returns the names of models
+func ModelNames(models []Model) []string {
+	names := make([]string, len(models))
+	for i, m := range models {
+		names[i] = m.Name
+	}
+	return names
+}
+
+// ollamaTags is the response of /api/tags
+type ollamaTags struct {
+	Models []struct {
+		Name       string    `json:"name"`
+		Size       int64     `json:"size"`
+		ModifiedAt time.Time `json:"modified_at"`
+		Details    struct {
+			ParameterSize     string `json:"parameter_size"`
+			QuantizationLevel string `json:"quantization_level"`
+		} `json:"details"`
+	} `json:"models"`
+}
+
+// Models returns the models installed on the server, from /api/tags
+func (o *Ollama) Models(ctx context.Context) ([]Model, error) {
+	var tags ollamaTags
+	if err := getJSON(ctx, baseURL(o.URL)+"/api/tags", "", &tags); err != nil {
+		return nil, err
+	}
+	models := make([]Model, len(tags.Models))
+	for i, m := range tags.Models {
+		models[i] = Model{
+			Name:          m.Name,
+			Size:          m.Size,
+			ParameterSize: m.Details.ParameterSize,
+			Quantization:  m.Details.QuantizationLevel,
+			ModifiedAt:    m.ModifiedAt,
+		}
+	}
+	return models, nil
+}
+
+// Models returns the models served, from /v1/models
+func (o *OpenAI) Models(ctx context.Context) ([]Model, error) {
+	var list struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := getJSON(ctx, baseURL(o.URL)+"/v1/models", o.APIKey, &list); err != nil {
+		return nil, err
+	}
+	models := make([]Model, len(list.Data))
+	for i, m := range list.Data {
+		models[i] = Model{Name: m.ID}
+	}
+	return models, nil
+}
+
+// PullProgress is a status update while a model is downloaded
+type PullProgress struct {
+	Status    string `json:"status"`    // e.g. "pulling manifest", "downloading", "success"
+	Digest    string `json:"digest"`    // Layer being downloaded, "" between layers
+	Total     int64  `json:"total"`     // Size of the layer in bytes, 0 if unknown
+	Completed int64  `json:"completed"` // Bytes of the layer downloaded so far
+}
+
+// Percent returns the share of the layer downloaded so far, 0 if unknown
+func (p PullProgress) Percent() float64 {
+	if p.Total <= 0 {
+		return 0
+	}
+	return float64(p.Completed) / float64(p.Total)
+}
+
+// Pull downloads a model through /api/pull, calling progress with every
+// status update the server streams
+func (o *Ollama) Pull(ctx context.Context, name string, progress func(PullProgress)) error {
+	data, err := json.Marshal(map[string]string{"model": name})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", baseURL(o.URL)+"/api/pull", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req) // #nosec G107 - URL is from config, validated by caller
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return statusError(resp.StatusCode, errorMessage(resp.Body))
+	}
+
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var update struct {
+			PullProgress
+			Error string `json:"error"`
+		}
+		if err := dec.Decode(&update); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return errors.New("pull stopped before the model was downloaded")
+		}
+		if update.Error != "" {
+			return errors.New(update.Error)
+		}
+		if progress != nil {
+			progress(update.PullProgress)
+		}
+		if update.Status == "success" {
+			return nil
+		}
+	}
+}
+
+// getJSON decodes the response of a GET request into v
+func getJSON(ctx context.Context, url, apiKey string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+	resp, err := http.DefaultClient.Do(req) // #nosec G107 - URL is from config, validated by caller
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return statusError(resp.StatusCode, errorMessage(resp.Body))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
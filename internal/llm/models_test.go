@@ -0,0 +1,159 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOllamaModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/api/tags" {
+			t.Errorf("Expected GET /api/tags, got %s %s", r.Method, r.URL.Path)
+		}
+		_, _ = w.Write([]byte(`{"models":[
+			{"name":"llama3.1:latest","size":4920753328,"modified_at":"2024-08-01T10:00:00Z",
+			 "details":{"parameter_size":"8.0B","quantization_level":"Q4_0"}},
+			{"name":"qwen2.5:7b","size":4683087332}
+		]}`))
+	}))
+	defer server.Close()
+
+	models, err := NewOllama(server.URL + "/api/generate").Models(context.Background())
+	if err != nil {
+		t.Fatalf("Models() error = %v", err)
+	}
+	if len(models) != 2 {
+		t.Fatalf("Models() returned %d models, expected 2", len(models))
+	}
+	m := models[0]
+	if m.Name != "llama3.1:latest" || m.Size != 4920753328 || m.ParameterSize != "8.0B" || m.Quantization != "Q4_0" || m.ModifiedAt.Year() != 2024 {
+		t.Errorf("Unexpected model %+v", m)
+	}
+	if got := strings.Join(ModelNames(models), ","); got != "llama3.1:latest,qwen2.5:7b" {
+		t.Errorf("ModelNames() = %q", got)
+	}
+}
+
+func TestOpenAIModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/models" {
+			t.Errorf("Expected /v1/models, got %s", r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer secret" {
+			t.Errorf("Authorization = %q", auth)
+		}
+		_, _ = w.Write([]byte(`{"object":"list","data":[{"id":"qwen2.5-7b-instruct","object":"model"}]}`))
+	}))
+	defer server.Close()
+
+	models, err := NewOpenAI(server.URL+"/v1", "secret").Models(context.Background())
+	if err != nil {
+		t.Fatalf("Models() error = %v", err)
+	}
+	if len(models) != 1 || models[0].Name != "qwen2.5-7b-instruct" {
+		t.Errorf("Models() = %+v", models)
+	}
+}
+
+func TestModelsError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"error":"boom"}`))
+	}))
+	defer server.Close()
+
+	_, err := NewOllama(server.URL).Models(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "500: boom") {
+		t.Errorf("Models() error = %v, expected the status and message", err)
+	}
+}
+
+func TestHasModel(t *testing.T) {
+	models := []Model{{Name: "llama3.1:latest"}, {Name: "qwen2.5:7b"}}
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"llama3.1:latest", true},
+		{"llama3.1", true},
+		{"qwen2.5:7b", true},
+		{"qwen2.5", false},
+		{"llama3.1:8b", false},
+		{"mistral", false},
+	}
+	for _, tt := range tests {
+		if got := HasModel(models, tt.name); got != tt.want {
+			t.Errorf("HasModel(%q) = %v, expected %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestOllamaPull(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/api/pull" {
+			t.Errorf("Expected POST /api/pull, got %s %s", r.Method, r.URL.Path)
+		}
+		var req map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req["model"] != "qwen2.5:7b" {
+			t.Errorf("Unexpected request %v (%v)", req, err)
+		}
+		for _, line := range []string{
+			`{"status":"pulling manifest"}`,
+			`{"status":"pulling abc","digest":"sha256:abc","total":100,"completed":40}`,
+			`{"status":"pulling abc","digest":"sha256:abc","total":100,"completed":100}`,
+			`{"status":"success"}`,
+		} {
+			_, _ = w.Write([]byte(line + "\n"))
+			w.(http.Flusher).Flush()
+		}
+	}))
+	defer server.Close()
+
+	var updates []PullProgress
+	err := NewOllama(server.URL).Pull(context.Background(), "qwen2.5:7b", func(p PullProgress) {
+		updates = append(updates, p)
+	})
+	if err != nil {
+		t.Fatalf("Pull() error = %v", err)
+	}
+	if len(updates) != 4 {
+		t.Fatalf("Got %d updates, expected 4", len(updates))
+	}
+	if updates[1].Digest != "sha256:abc" || updates[1].Percent() != 0.4 {
+		t.Errorf("Unexpected update %+v", updates[1])
+	}
+	if updates[0].Percent() != 0 || updates[3].Status != "success" {
+		t.Errorf("Unexpected updates %+v", updates)
+	}
+}
+
+func TestOllamaPullErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{"streamed error", http.StatusOK, `{"status":"pulling manifest"}` + "\n" + `{"error":"pull model manifest: file does not exist"}`, "file does not exist"},
+		{"status code", http.StatusNotFound, `{"error":"not found"}`, "404: not found"},
+		{"stream cut short", http.StatusOK, `{"status":"pulling manifest"}`, "pull stopped"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			err := NewOllama(server.URL).Pull(context.Background(), "missing", nil)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Pull() error = %v, expected %q", err, tt.wantErr)
+			}
+		})
+	}
+}
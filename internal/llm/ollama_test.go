@@ -0,0 +1,250 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestOllamaGenerate(t *testing.T) {
+	// Create a test server that mocks Ollama API
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/api/generate" {
+			t.Errorf("Expected POST /api/generate, got %s %s", r.Method, r.URL.Path)
+		}
+		if contentType := r.Header.Get("Content-Type"); contentType != "application/json" {
+			t.Errorf("Expected Content-Type application/json, got %s", contentType)
+		}
+
+		var req ollamaRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+		}
+		if req.Model != "test-model" || req.Prompt != "Generate flashcards" || req.Format != nil {
+			t.Errorf("Unexpected request %+v", req)
+		}
+
+		// Send a streaming response in several chunks
+		chunks := []map[string]interface{}{
+			{"response": "Q: What is Go?\nA: ", "done": false},
+			{"response": "A programming language", "done": false},
+			{"response": "\nQ: What is Python?\nA: ", "done": false},
+			{"response": "Another language", "done": true},
+		}
+		for _, chunk := range chunks {
+			if err := json.NewEncoder(w).Encode(chunk); err != nil {
+				t.Errorf("Failed to encode response: %v", err)
+				return
+			}
+			w.(http.Flusher).Flush()
+		}
+	}))
+	defer server.Close()
+
+	// The configured URL may be the full endpoint
+	result, err := NewOllama(server.URL+"/api/generate").Generate(context.Background(), Request{Model: "test-model", Prompt: "Generate flashcards"})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	expected := "Q: What is Go?\nA: A programming language\nQ: What is Python?\nA: Another language"
+	if result != expected {
+		t.Errorf("Generate() = %q, expected %q", result, expected)
+	}
+}
+
+func TestOllamaStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, line := range []string{`{"response":"Q: Go?"}`, `{"response":""}`, `{"response":"\nA: Yes","done":true}`} {
+			_, _ = w.Write([]byte(line + "\n"))
+			w.(http.Flusher).Flush()
+		}
+	}))
+	defer server.Close()
+
+	var tokens []string
+	result, err := NewOllama(server.URL).Generate(context.Background(), Request{Model: "m", OnToken: func(token string) {
+		tokens = append(tokens, token)
+	}})
+	if err != nil || result != "Q: Go?\nA: Yes" {
+		t.Errorf("Generate() = %q, %v", result, err)
+	}
+	// Empty chunks are not passed on
+	if len(tokens) != 2 || tokens[1] != "\nA: Yes" {
+		t.Errorf("Unexpected tokens %q", tokens)
+	}
+}
+
+func TestOllamaChat(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			t.Errorf("Expected /api/chat, got %s", r.URL.Path)
+		}
+		var req ollamaRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if len(req.Messages) != 1 || req.Messages[0].Role != "user" || req.Messages[0].Content != "prompt" || req.Prompt != "" {
+			t.Errorf("Expected the prompt as a user message, got %+v", req)
+		}
+		if string(req.Format) != `"json"` {
+			t.Errorf("Expected JSON mode, got %s", req.Format)
+		}
+		for _, content := range []string{`{"grade": `, `3}`} {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"message": map[string]string{"role": "assistant", "content": content}})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"done": true})
+	}))
+	defer server.Close()
+
+	result, err := NewOllamaChat(server.URL).Generate(context.Background(), Request{Model: "m", Prompt: "prompt", JSON: true})
+	if err != nil || result != `{"grade": 3}` {
+		t.Errorf("Generate() = %q, %v", result, err)
+	}
+}
+
+func TestOllamaStructured(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ollamaRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if len(req.Format) == 0 || req.Format[0] != '{' {
+			t.Errorf("Expected a JSON schema in the format option, got %s", req.Format)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"response": `{"flashcards": []}`, "done": true})
+	}))
+	defer server.Close()
+
+	result, err := NewOllama(server.URL).Generate(context.Background(), Request{Model: "m", Prompt: "p", Schema: json.RawMessage(`{"type": "object"}`)})
+	if err != nil || result != `{"flashcards": []}` {
+		t.Errorf("Generate() = %q, %v", result, err)
+	}
+}
+
+func TestOllamaStructuredUnsupported(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":"json: cannot unmarshal object into Go struct field GenerateRequest.format of type string"}`))
+	}))
+	defer server.Close()
+
+	provider := NewOllama(server.URL)
+	_, err := provider.Generate(context.Background(), Request{Model: "m", Prompt: "p", Schema: json.RawMessage(`{}`)})
+	if !errors.Is(err, ErrStructuredUnsupported) {
+		t.Errorf("Expected ErrStructuredUnsupported, got %v", err)
+	}
+
+	// Plain requests report the server error as is
+	_, err = provider.Generate(context.Background(), Request{Model: "m", Prompt: "p"})
+	if err == nil || errors.Is(err, ErrStructuredUnsupported) {
+		t.Errorf("Expected a plain status error, got %v", err)
+	}
+}
+
+func TestOllamaErrorCases(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "server error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			wantErr: true,
+		},
+		{
+			name: "model not found",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"error":"model \"nope\" not found, try pulling it first"}`))
+			},
+			want:    `unexpected status code: 404: model "nope" not found, try pulling it first`,
+			wantErr: true,
+		},
+		{
+			name: "invalid JSON response",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte("invalid json"))
+			},
+			want:    "stream ended before the response was done",
+			wantErr: true,
+		},
+		{
+			name:    "empty response",
+			handler: func(w http.ResponseWriter, r *http.Request) {},
+			want:    "stream ended before the response was done",
+			wantErr: true,
+		},
+		{
+			name: "stream cut short",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(`{"response":"Q: What is Go?"}` + "\n"))
+			},
+			want:    "stream ended before the response was done",
+			wantErr: true,
+		},
+		{
+			name: "streamed error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(`{"response":"Q: "}` + "\n" + `{"error":"model runner has unexpectedly stopped"}` + "\n"))
+			},
+			want:    "model runner has unexpectedly stopped",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			_, err := NewOllama(server.URL).Generate(context.Background(), Request{Model: "test-model", Prompt: "test prompt"})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Generate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.want != "" && (err == nil || err.Error() != tt.want) {
+				t.Errorf("Generate() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestOllamaTimeout(t *testing.T) {
+	// Create a server that delays response
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(2 * time.Second) // Delay longer than context timeout
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	if _, err := NewOllama(server.URL).Generate(ctx, Request{Model: "m", Prompt: "p"}); err == nil {
+		t.Error("Generate() should return error on timeout")
+	}
+}
+
+func TestOllamaCancelledStream(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"response":"Q: "}` + "\n"))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	// Cancelling after the first token must not return the partial response
+	result, err := NewOllama(server.URL).Generate(ctx, Request{Model: "m", Prompt: "p", OnToken: func(string) { cancel() }})
+	if !errors.Is(err, context.Canceled) || result != "" {
+		t.Errorf("Generate() = %q, %v, expected context.Canceled", result, err)
+	}
+}
+
+func TestOllamaInvalidURL(t *testing.T) {
+	if _, err := NewOllama("http://invalid-url-that-does-not-exist:12345").Generate(context.Background(), Request{Model: "m", Prompt: "p"}); err == nil {
+		t.Error("Generate() should return error for invalid URL")
+	}
+}
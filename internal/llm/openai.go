@@ -0,0 +1,159 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// OpenAI talks to a server with an OpenAI-compatible /v1/chat/completions
+// endpoint, such as LM Studio, vLLM, the llama.cpp server or LocalAI
+type OpenAI struct {
+	URL    string // Server address, e.g. http://localhost:1234/v1
+	APIKey string // Sent as a bearer token if set
+}
+
+// NewOpenAI returns a provider for the OpenAI-compatible server at url
+func NewOpenAI(url, apiKey string) *OpenAI {
+	return &OpenAI{URL: url, APIKey: apiKey}
+}
+
+// Name returns the identifier of the provider
+func (o *OpenAI) Name() string {
+	return NameOpenAI
+}
+
+// openAIRequest is the body of a chat completion request
+type openAIRequest struct {
+	Model          string          `json:"model"`
+	Messages       []chatMessage   `json:"messages"`
+	ResponseFormat *responseFormat `json:"response_format,omitempty"`
+	Stream         bool            `json:"stream"`
+}
+
+// responseFormat asks for JSON, following a schema if one is given
+type responseFormat struct {
+	Type       string      `json:"type"` // "json_schema" or "json_object"
+	JSONSchema *jsonSchema `json:"json_schema,omitempty"`
+}
+
+type jsonSchema struct {
+	Name   string          `json:"name"`
+	Schema json.RawMessage `json:"schema"`
+}
+
+// openAIResponse is the part of a chat completion catv reads
+type openAIResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+// openAIChunk is one server-sent event of a streamed chat completion
+type openAIChunk struct {
+	Choices []struct {
+		Delta chatMessage `json:"delta"`
+	} `json:"choices"`
+}
+
+// Generate sends req as a single user message and returns the first choice
+// The response is streamed when req.OnToken is set
+func (o *OpenAI) Generate(ctx context.Context, req Request) (string, error) {
+	body := openAIRequest{
+		Model:    req.Model,
+		Messages: []chatMessage{{Role: "user", Content: req.Prompt}},
+		Stream:   req.OnToken != nil,
+	}
+	switch {
+	case req.Schema != nil:
+		body.ResponseFormat = &responseFormat{Type: "json_schema", JSONSchema: &jsonSchema{Name: "response", Schema: req.Schema}}
+	case req.JSON:
+		body.ResponseFormat = &responseFormat{Type: "json_object"}
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", baseURL(o.URL)+"/v1/chat/completions", bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if o.APIKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+o.APIKey)
+	}
+
+	resp, err := http.DefaultClient.Do(httpReq) // #nosec G107 - URL is from config, validated by caller
+	if err != nil {
+		return "", fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		message := errorMessage(resp.Body)
+		// Servers without structured outputs reject the response format
+		if body.ResponseFormat != nil && resp.StatusCode == http.StatusBadRequest && (strings.Contains(message, "response_format") || strings.Contains(message, "json_schema")) {
+			return "", fmt.Errorf("%w: %s", ErrStructuredUnsupported, message)
+		}
+		return "", statusError(resp.StatusCode, message)
+	}
+
+	if body.Stream {
+		return readStream(resp.Body, req.OnToken)
+	}
+
+	var completion openAIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&completion); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(completion.Choices) == 0 {
+		return "", fmt.Errorf("response has no choices")
+	}
+	return completion.Choices[0].Message.Content, nil
+}
+
+// readStream concatenates the first choice of the server-sent events of a
+// streamed completion, passing each piece to onToken
+func readStream(body io.Reader, onToken func(string)) (string, error) {
+	var response strings.Builder
+	received := false
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			break
+		}
+		var chunk openAIChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return "", fmt.Errorf("failed to decode response: %w", err)
+		}
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+		received = true
+		if token := chunk.Choices[0].Delta.Content; token != "" {
+			response.WriteString(token)
+			onToken(token)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+	if !received {
+		return "", fmt.Errorf("response has no choices")
+	}
+	return response.String(), nil
+}
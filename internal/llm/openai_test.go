@@ -0,0 +1,140 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOpenAIGenerate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("Expected POST /v1/chat/completions, got %s %s", r.Method, r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer secret" {
+			t.Errorf("Expected the API key as a bearer token, got %q", auth)
+		}
+		var req openAIRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+		}
+		if req.Model != "qwen2.5-7b" || req.Stream || len(req.Messages) != 1 || req.Messages[0].Content != "prompt" {
+			t.Errorf("Unexpected request %+v", req)
+		}
+		if f := req.ResponseFormat; f == nil || f.Type != "json_schema" || f.JSONSchema == nil || string(f.JSONSchema.Schema) != `{"type":"object"}` {
+			t.Errorf("Expected the schema as the response format, got %+v", f)
+		}
+		_, _ = w.Write([]byte(`{"choices": [{"index": 0, "message": {"role": "assistant", "content": "{\"flashcards\": []}"}}]}`))
+	}))
+	defer server.Close()
+
+	// LM Studio and llama.cpp document their address with the /v1 prefix
+	provider := NewOpenAI(server.URL+"/v1", "secret")
+	result, err := provider.Generate(context.Background(), Request{Model: "qwen2.5-7b", Prompt: "prompt", Schema: json.RawMessage(`{"type":"object"}`)})
+	if err != nil || result != `{"flashcards": []}` {
+		t.Errorf("Generate() = %q, %v", result, err)
+	}
+}
+
+func TestOpenAIStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req openAIRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || !req.Stream {
+			t.Errorf("Expected a streamed request, got %+v (%v)", req, err)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, event := range []string{
+			`{"choices":[{"index":0,"delta":{"role":"assistant"}}]}`,
+			`{"choices":[{"index":0,"delta":{"content":"Q: What is Go?"}}]}`,
+			`{"choices":[{"index":0,"delta":{"content":"\nA: A language"}}]}`,
+			`[DONE]`,
+		} {
+			_, _ = w.Write([]byte("data: " + event + "\n\n"))
+			w.(http.Flusher).Flush()
+		}
+	}))
+	defer server.Close()
+
+	var tokens []string
+	result, err := NewOpenAI(server.URL, "").Generate(context.Background(), Request{Model: "m", Prompt: "prompt", OnToken: func(token string) {
+		tokens = append(tokens, token)
+	}})
+	if err != nil || result != "Q: What is Go?\nA: A language" {
+		t.Errorf("Generate() = %q, %v", result, err)
+	}
+	if len(tokens) != 2 || tokens[0] != "Q: What is Go?" {
+		t.Errorf("Unexpected tokens %q", tokens)
+	}
+
+	empty := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("data: [DONE]\n\n"))
+	}))
+	defer empty.Close()
+	if _, err := NewOpenAI(empty.URL, "").Generate(context.Background(), Request{OnToken: func(string) {}}); err == nil {
+		t.Error("Expected an error for a stream without choices")
+	}
+}
+
+func TestOpenAIResponseFormats(t *testing.T) {
+	var formats []*responseFormat
+	var auth []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req openAIRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		formats = append(formats, req.ResponseFormat)
+		auth = append(auth, r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{"choices": [{"message": {"content": "Q: a\nA: b"}}]}`))
+	}))
+	defer server.Close()
+
+	provider := NewOpenAI(server.URL, "")
+	for _, req := range []Request{{Model: "m", Prompt: "p"}, {Model: "m", Prompt: "p", JSON: true}} {
+		if _, err := provider.Generate(context.Background(), req); err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+	}
+	if formats[0] != nil || formats[1] == nil || formats[1].Type != "json_object" {
+		t.Errorf("Expected no response format, then JSON mode, got %+v, %+v", formats[0], formats[1])
+	}
+	if auth[0] != "" {
+		t.Errorf("Expected no Authorization header without an API key, got %q", auth[0])
+	}
+}
+
+func TestOpenAIErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		unsupported bool
+		want        string
+	}{
+		{"schema rejected", http.StatusBadRequest, `{"error": {"message": "response_format type json_schema is not supported"}}`, true, ""},
+		{"unauthorized", http.StatusUnauthorized, `{"error": {"message": "Invalid API key"}}`, false, "unexpected status code: 401: Invalid API key"},
+		{"no choices", http.StatusOK, `{"choices": []}`, false, "response has no choices"},
+		{"not JSON", http.StatusOK, `<html>`, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			_, err := NewOpenAI(server.URL, "").Generate(context.Background(), Request{Model: "m", Prompt: "p", Schema: json.RawMessage(`{}`)})
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+			if errors.Is(err, ErrStructuredUnsupported) != tt.unsupported {
+				t.Errorf("Unexpected error %v", err)
+			}
+			if tt.want != "" && err.Error() != tt.want {
+				t.Errorf("Generate() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
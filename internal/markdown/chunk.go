@@ -0,0 +1,166 @@
+package markdown
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// charsPerToken approximates how many characters a model token covers
+const charsPerToken = 4
+
+// EstimateTokens roughly estimates the number of model tokens in text
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + charsPerToken - 1) / charsPerToken
+}
+
+// Chunk splits content into pieces of at most maxTokens estimated tokens
+// Paragraphs and fenced code blocks are kept whole when they fit; larger ones
+// are split by line, and single lines that are still too long by characters
+// A maxTokens of 0 or less returns the content as a single chunk
+func Chunk(content string, maxTokens int) []string {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return nil
+	}
+	if maxTokens <= 0 || EstimateTokens(content) <= maxTokens {
+		return []string{content}
+	}
+
+	var chunks []string
+	var current []string
+	size := 0
+	emit := func() {
+		if len(current) > 0 {
+			chunks = append(chunks, strings.Join(current, "\n\n"))
+			current, size = nil, 0
+		}
+	}
+
+	for _, block := range Blocks(content) {
+		tokens := EstimateTokens(block)
+		if tokens > maxTokens {
+			emit()
+			chunks = append(chunks, splitLines(block, maxTokens)...)
+			continue
+		}
+		// Account for the blank line joining blocks
+		if len(current) > 0 && size+tokens+1 > maxTokens {
+			emit()
+		}
+		current = append(current, block)
+		size += tokens + 1
+	}
+	emit()
+	return chunks
+}
+
+// Blocks splits markdown into paragraphs separated by blank lines, keeping
+// fenced code blocks together even when they contain blank lines
+func Blocks(content string) []string {
+	var result []string
+	var block []string
+	inFence := false
+	fence := ""
+
+	for _, line := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if marker := fenceMarker(trimmed); marker != "" {
+			switch {
+			case !inFence:
+				inFence, fence = true, marker
+			case strings.HasPrefix(trimmed, fence):
+				inFence = false
+			}
+		}
+		if trimmed == "" && !inFence {
+			if len(block) > 0 {
+				result = append(result, strings.Join(block, "\n"))
+				block = nil
+			}
+			continue
+		}
+		block = append(block, line)
+	}
+	if len(block) > 0 {
+		result = append(result, strings.Join(block, "\n"))
+	}
+	return result
+}
+
+// splitLines packs the lines of an oversized block into chunks within maxTokens
+// A cut inside a fenced code block closes the fence at the end of the chunk and
+// opens it again, with the same info string, at the start of the next one
+func splitLines(block string, maxTokens int) []string {
+	var chunks []string
+	var current []string
+	size := 0
+	carried := 0          // Lines of current that only reopen the fence
+	open, fence := "", "" // Opening line and marker of the fence being split
+	emit := func() {
+		if open != "" {
+			current = append(current, fence)
+		}
+		chunks = append(chunks, strings.Join(current, "\n"))
+		current, size, carried = nil, 0, 0
+		if open != "" {
+			current, size, carried = []string{open}, EstimateTokens(open), 1
+		}
+	}
+	for _, line := range strings.Split(block, "\n") {
+		tokens := EstimateTokens(line)
+		closing := 0
+		if open != "" {
+			closing = EstimateTokens(fence)
+		}
+		if len(current) > carried && size+tokens+closing > maxTokens {
+			emit()
+		}
+		if tokens > maxTokens {
+			for _, piece := range splitRunes(line, maxTokens*charsPerToken) {
+				if open != "" {
+					piece = open + "\n" + piece + "\n" + fence
+				}
+				chunks = append(chunks, piece)
+			}
+			continue
+		}
+		current = append(current, line)
+		size += tokens
+
+		trimmed := strings.TrimSpace(line)
+		if marker := fenceMarker(trimmed); marker != "" {
+			switch {
+			case open == "":
+				open, fence = trimmed, marker
+			case strings.HasPrefix(trimmed, fence):
+				open, fence = "", ""
+			}
+		}
+	}
+	if len(current) > carried {
+		chunks = append(chunks, strings.Join(current, "\n"))
+	}
+	return chunks
+}
+
+// splitRunes cuts a line into pieces of at most n characters, preferring to
+// break at spaces
+func splitRunes(line string, n int) []string {
+	var pieces []string
+	runes := []rune(line)
+	for len(runes) > n {
+		cut := n
+		for i := n; i > n/2; i-- {
+			if runes[i] == ' ' {
+				cut = i
+				break
+			}
+		}
+		pieces = append(pieces, strings.TrimSpace(string(runes[:cut])))
+		runes = runes[cut:]
+	}
+	if rest := strings.TrimSpace(string(runes)); rest != "" {
+		pieces = append(pieces, rest)
+	}
+	return pieces
+}
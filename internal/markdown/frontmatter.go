@@ -0,0 +1,141 @@
+package markdown
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FrontMatter is the metadata catv reads from a note's YAML front matter
+type FrontMatter struct {
+	Deck string   // Deck for the note's flashcards ("" to use the file name)
+	Tags []string // Tags for every flashcard of the note
+
+	// Prompt settings for generating the note's flashcards ("" or 0 for the defaults)
+	Template   string // Built-in prompt template name or path relative to the note
+	Language   string // Language to write the flashcards in
+	Difficulty string // Level to pitch the flashcards at
+	Cards      int    // Desired number of flashcards per chunk
+}
+
+// ParseFrontMatter separates the YAML front matter delimited by "---" lines at
+// the start of a note from its body
+// Only the deck, tags, template (or prompt_template), language, difficulty and
+// cards keys are read; tags may be a list, a flow sequence such as
+// [go, concurrency] or a comma separated string. A note without front matter
+// is returned unchanged.
+func ParseFrontMatter(content string) (FrontMatter, string) {
+	var fm FrontMatter
+	normalized := strings.ReplaceAll(content, "\r\n", "\n")
+	if !strings.HasPrefix(normalized, "---\n") {
+		return fm, content
+	}
+	lines := strings.Split(normalized, "\n")
+	end := -1
+	for i := 1; i < len(lines); i++ {
+		if l := strings.TrimRight(lines[i], " \t"); l == "---" || l == "..." {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return fm, content
+	}
+
+	key := ""
+	for _, line := range lines[1:end] {
+		trimmed := strings.TrimSpace(line)
+		if item, ok := strings.CutPrefix(trimmed, "- "); ok && line != trimmed {
+			// Item of a block sequence under the previous key
+			if key == "tags" || key == "tag" {
+				fm.Tags = append(fm.Tags, unquote(item))
+			}
+			continue
+		}
+		k, value, ok := strings.Cut(trimmed, ":")
+		if !ok || line != trimmed {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(k))
+		value = strings.TrimSpace(value)
+		switch key {
+		case "deck":
+			fm.Deck = unquote(value)
+		case "tags", "tag":
+			fm.Tags = append(fm.Tags, splitList(value)...)
+		case "template", "prompt_template":
+			fm.Template = unquote(value)
+		case "language":
+			fm.Language = unquote(value)
+		case "difficulty":
+			fm.Difficulty = unquote(value)
+		case "cards":
+			if n, err := strconv.Atoi(unquote(value)); err == nil && n > 0 {
+				fm.Cards = n
+			}
+		}
+	}
+	return fm, strings.Join(lines[end+1:], "\n")
+}
+
+// splitList splits an inline YAML sequence or comma separated value
+func splitList(value string) []string {
+	value = strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = unquote(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// unquote trims spaces and the quotes around a YAML scalar
+func unquote(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// hashtag matches #tag at the start of a line or after a space or bracket;
+// tags are letters, digits, '_', '-' and '/' and must contain a letter
+var hashtag = regexp.MustCompile(`(?:^|[\s(\[])#([\p{L}\p{N}_/-]*\p{L}[\p{L}\p{N}_/-]*)`)
+
+// inlineCode matches code spans, which are skipped when looking for hashtags
+var inlineCode = regexp.MustCompile("`[^`\n]*`")
+
+// Hashtags returns the distinct #tags written in content, in order of
+// appearance, ignoring headings, code blocks and code spans
+func Hashtags(content string) []string {
+	var tags []string
+	seen := make(map[string]bool)
+	inFence := false
+	fence := ""
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if marker := fenceMarker(trimmed); marker != "" {
+			switch {
+			case !inFence:
+				inFence, fence = true, marker
+			case strings.HasPrefix(trimmed, fence):
+				inFence = false
+			}
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if _, _, ok := parseHeading(line); ok {
+			continue
+		}
+		for _, m := range hashtag.FindAllStringSubmatch(inlineCode.ReplaceAllString(line, ""), -1) {
+			if tag := m[1]; !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags
+}
@@ -0,0 +1,70 @@
+package markdown
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFrontMatter(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    FrontMatter
+		body    string
+	}{
+		{
+			name:    "block list",
+			content: "---\ntitle: Channels\ndeck: Go Interview\ntags:\n  - go\n  - \"concurrency\"\n---\n# Channels\nBody",
+			want:    FrontMatter{Deck: "Go Interview", Tags: []string{"go", "concurrency"}},
+			body:    "# Channels\nBody",
+		},
+		{
+			name:    "flow sequence",
+			content: "---\r\ntags: [networking, 'tcp']\r\n---\r\nText",
+			want:    FrontMatter{Tags: []string{"networking", "tcp"}},
+			body:    "Text",
+		},
+		{
+			name:    "comma separated",
+			content: "---\ndeck: 'Rust'\ntag: ownership, borrowing\n...\nText",
+			want:    FrontMatter{Deck: "Rust", Tags: []string{"ownership", "borrowing"}},
+			body:    "Text",
+		},
+		{
+			name:    "prompt settings",
+			content: "---\ntemplate: vocabulary\nlanguage: \"German\"\ndifficulty: beginner\ncards: 5\n---\nText",
+			want:    FrontMatter{Template: "vocabulary", Language: "German", Difficulty: "beginner", Cards: 5},
+			body:    "Text",
+		},
+		{
+			name:    "no front matter",
+			content: "# Title\n---\ndeck: x\n---",
+			body:    "# Title\n---\ndeck: x\n---",
+		},
+		{
+			name:    "unterminated",
+			content: "---\ndeck: x\nText",
+			body:    "---\ndeck: x\nText",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fm, body := ParseFrontMatter(tt.content)
+			if !reflect.DeepEqual(fm, tt.want) || body != tt.body {
+				t.Errorf("ParseFrontMatter() = %+v, %q, want %+v, %q", fm, body, tt.want, tt.body)
+			}
+		})
+	}
+}
+
+func TestHashtags(t *testing.T) {
+	content := "# Heading #notatag\n" +
+		"Goroutines are cheap #go #concurrency, see (#go) and #go/runtime.\n" +
+		"Issue #42, C# and https://example.com/#anchor are not tags; `#code` neither.\n" +
+		"```sh\n# comment #shell\n```\n" +
+		"#review"
+	want := []string{"go", "concurrency", "go/runtime", "review"}
+	if got := Hashtags(content); !reflect.DeepEqual(got, want) {
+		t.Errorf("Hashtags() = %q, want %q", got, want)
+	}
+}
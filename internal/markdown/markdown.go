@@ -0,0 +1,141 @@
+// Package markdown splits notes into heading sections and fingerprints their
+// content so unchanged parts of a note can be recognized between runs
+package markdown
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// HeadingSeparator joins the headings of a section's breadcrumb
+const HeadingSeparator = " > "
+
+// Section is the content under a single heading
+type Section struct {
+	Heading string // Breadcrumb of the enclosing headings, e.g. "Go > Channels" ("" before the first heading)
+	Content string // Markdown under the heading, including the heading line itself
+	Hash    string // Fingerprint of Content
+}
+
+// Split divides markdown into sections at ATX headings (# to ######)
+// Headings inside fenced code blocks are ignored, blank sections are dropped
+// and repeated breadcrumbs are numbered so every section has a unique heading
+func Split(content string) []Section {
+	var sections []Section
+	var trail []string // Heading text per level, index 0 is level 1
+	heading := ""
+	var body []string
+	inFence := false
+	fence := ""
+	seen := make(map[string]int)
+
+	flush := func() {
+		text := strings.TrimSpace(strings.Join(body, "\n"))
+		body = body[:0]
+		if text == "" || isOnlyHeading(text) {
+			return
+		}
+		key := heading
+		seen[key]++
+		if n := seen[key]; n > 1 {
+			key = fmt.Sprintf("%s (%d)", key, n)
+		}
+		sections = append(sections, Section{Heading: key, Content: text, Hash: Hash(text)})
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if marker := fenceMarker(trimmed); marker != "" {
+			switch {
+			case !inFence:
+				inFence, fence = true, marker
+			case strings.HasPrefix(trimmed, fence):
+				inFence = false
+			}
+		}
+		if !inFence {
+			if level, text, ok := parseHeading(line); ok {
+				flush()
+				if level > len(trail) {
+					for len(trail) < level-1 {
+						trail = append(trail, "")
+					}
+					trail = append(trail, text)
+				} else {
+					trail = append(trail[:level-1], text)
+				}
+				heading = breadcrumb(trail)
+			}
+		}
+		body = append(body, line)
+	}
+	flush()
+	return sections
+}
+
+// Hash returns a hex SHA-256 fingerprint of content, ignoring trailing
+// whitespace so editor reformatting does not count as a change
+func Hash(content string) string {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(l, " \t")
+	}
+	sum := sha256.Sum256([]byte(strings.TrimSpace(strings.Join(lines, "\n"))))
+	return hex.EncodeToString(sum[:])
+}
+
+// parseHeading recognizes an ATX heading line and returns its level and text
+func parseHeading(line string) (int, string, bool) {
+	// Up to three spaces of indentation are allowed
+	trimmed := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 {
+		return 0, "", false
+	}
+	level := 0
+	for level < len(trimmed) && trimmed[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 {
+		return 0, "", false
+	}
+	rest := trimmed[level:]
+	if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		return 0, "", false
+	}
+	// Drop the optional closing sequence of #s
+	text := strings.TrimSpace(rest)
+	text = strings.TrimSpace(strings.TrimRight(text, "#"))
+	return level, text, true
+}
+
+// fenceMarker returns the fence characters opening or closing a code block, if any
+func fenceMarker(trimmed string) string {
+	for _, marker := range []string{"```", "~~~"} {
+		if strings.HasPrefix(trimmed, marker) {
+			return marker
+		}
+	}
+	return ""
+}
+
+// breadcrumb joins the non-empty headings of a trail
+func breadcrumb(trail []string) string {
+	parts := make([]string, 0, len(trail))
+	for _, h := range trail {
+		if h != "" {
+			parts = append(parts, h)
+		}
+	}
+	return strings.Join(parts, HeadingSeparator)
+}
+
+// isOnlyHeading reports whether a section consists of nothing but its heading line
+func isOnlyHeading(text string) bool {
+	if strings.Contains(text, "\n") {
+		return false
+	}
+	_, _, ok := parseHeading(text)
+	return ok
+}
@@ -0,0 +1,174 @@
+package markdown
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	content := `Intro paragraph.
+
+# Go
+
+Go is a language.
+
+## Channels
+
+Channels connect goroutines.
+
+` + "```go\n# not a heading\nch := make(chan int)\n```" + `
+
+## Empty
+
+# Rust
+Ownership.
+
+# Rust
+Borrowing.
+`
+
+	sections := Split(content)
+	expected := []string{"", "Go", "Go > Channels", "Rust", "Rust (2)"}
+	if len(sections) != len(expected) {
+		t.Fatalf("Expected %d sections, got %d: %+v", len(expected), len(sections), sections)
+	}
+	for i, heading := range expected {
+		if sections[i].Heading != heading {
+			t.Errorf("Section %d: expected heading %q, got %q", i, heading, sections[i].Heading)
+		}
+		if sections[i].Hash != Hash(sections[i].Content) {
+			t.Errorf("Section %d: hash does not match content", i)
+		}
+	}
+
+	if sections[0].Content != "Intro paragraph." {
+		t.Errorf("Unexpected preamble content: %q", sections[0].Content)
+	}
+	channels := sections[2].Content
+	if !strings.HasPrefix(channels, "## Channels") {
+		t.Errorf("Section content should start with its heading line, got %q", channels)
+	}
+	for _, want := range []string{"# not a heading", "ch := make"} {
+		if !strings.Contains(channels, want) {
+			t.Errorf("Code block should stay in the Channels section, missing %q", want)
+		}
+	}
+}
+
+func TestSplit_SkippedLevels(t *testing.T) {
+	sections := Split("# A\ntext\n### C\nmore\n## B\nlast")
+	expected := []string{"A", "A > C", "A > B"}
+	for i, heading := range expected {
+		if sections[i].Heading != heading {
+			t.Errorf("Section %d: expected heading %q, got %q", i, heading, sections[i].Heading)
+		}
+	}
+}
+
+func TestParseHeading(t *testing.T) {
+	tests := []struct {
+		line  string
+		level int
+		text  string
+		ok    bool
+	}{
+		{"# Title", 1, "Title", true},
+		{"### Closed ###", 3, "Closed", true},
+		{"   ## Indented", 2, "Indented", true},
+		{"    # Code", 0, "", false},
+		{"#hashtag", 0, "", false},
+		{"####### Too deep", 0, "", false},
+		{"#", 1, "", true},
+	}
+
+	for _, tt := range tests {
+		level, text, ok := parseHeading(tt.line)
+		if level != tt.level || text != tt.text || ok != tt.ok {
+			t.Errorf("parseHeading(%q) = %d, %q, %v, expected %d, %q, %v", tt.line, level, text, ok, tt.level, tt.text, tt.ok)
+		}
+	}
+}
+
+func TestHash(t *testing.T) {
+	if Hash("a\nb") != Hash("a  \nb\n\n") {
+		t.Error("Trailing whitespace should not change the hash")
+	}
+	if Hash("a\nb") == Hash("a\nc") {
+		t.Error("Different content should have different hashes")
+	}
+}
+
+func TestChunk(t *testing.T) {
+	if chunks := Chunk("# Short\nfits", 100); len(chunks) != 1 || chunks[0] != "# Short\nfits" {
+		t.Errorf("Expected a short section to stay whole, got %q", chunks)
+	}
+	if chunks := Chunk(strings.Repeat("word ", 500), 0); len(chunks) != 1 {
+		t.Errorf("Expected no budget to keep one chunk, got %d", len(chunks))
+	}
+
+	para := strings.TrimSpace(strings.Repeat("word ", 30)) // ~38 tokens
+	code := "```\nline one\n\nline two\n```"
+	content := "# Title\n\n" + para + "\n\n" + code + "\n\n" + para + "\n\n" + para
+	chunks := Chunk(content, 50)
+	if len(chunks) < 3 {
+		t.Fatalf("Expected the section to be split, got %d chunks", len(chunks))
+	}
+	for i, c := range chunks {
+		if EstimateTokens(c) > 50 {
+			t.Errorf("Chunk %d exceeds the budget: %d tokens", i, EstimateTokens(c))
+		}
+	}
+	found := false
+	for _, c := range chunks {
+		if strings.Contains(c, code) {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected the code block to stay in one chunk, got %q", chunks)
+	}
+}
+
+func TestChunk_OversizedCodeBlock(t *testing.T) {
+	var lines []string
+	for i := range 40 {
+		lines = append(lines, fmt.Sprintf("\tfmt.Println(%d) // step %d", i, i))
+	}
+	code := "```go\nfunc main() {\n" + strings.Join(lines, "\n") + "\n}\n```"
+	chunks := Chunk("Intro paragraph.\n\n"+code, 50)
+	if len(chunks) < 3 {
+		t.Fatalf("Expected the code block to be split, got %d chunks", len(chunks))
+	}
+
+	var kept []string
+	for i, c := range chunks[1:] {
+		// Every piece of the block is a complete fence with the info string
+		if !strings.HasPrefix(c, "```go\n") || !strings.HasSuffix(c, "\n```") || strings.Count(c, "```") != 2 {
+			t.Errorf("Chunk %d is not a closed go fence: %q", i+1, c)
+		}
+		body := strings.TrimSuffix(strings.TrimPrefix(c, "```go\n"), "\n```")
+		kept = append(kept, strings.Split(body, "\n")...)
+	}
+	if got := "```go\n" + strings.Join(kept, "\n") + "\n```"; got != code {
+		t.Errorf("Expected the code lines to be kept in order, got %q", got)
+	}
+}
+
+func TestChunk_OversizedParagraph(t *testing.T) {
+	long := strings.TrimSpace(strings.Repeat("lorem ipsum ", 100))
+	chunks := Chunk(long, 20)
+	if len(chunks) < 2 {
+		t.Fatalf("Expected a long line to be split, got %d chunks", len(chunks))
+	}
+	var words int
+	for i, c := range chunks {
+		if EstimateTokens(c) > 20 {
+			t.Errorf("Chunk %d exceeds the budget: %d tokens", i, EstimateTokens(c))
+		}
+		words += len(strings.Fields(c))
+	}
+	if words != 200 {
+		t.Errorf("Expected every word to be kept, got %d", words)
+	}
+}
@@ -0,0 +1,78 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"catv/internal/llm"
+)
+
+// Judgement is a model's verdict on a typed answer
+type Judgement struct {
+	Grade       int    `json:"grade"`       // 1 (Again) to 4 (Easy)
+	Explanation string `json:"explanation"` // One line explaining the grade
+}
+
+// judgementSchema is the JSON schema the model's verdict must follow
+var judgementSchema = json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "grade": {"type": "integer", "minimum": 1, "maximum": 4},
+    "explanation": {"type": "string"}
+  },
+  "required": ["grade", "explanation"]
+}`)
+
+// JudgeAnswer asks the model to grade a typed answer against the stored one
+// Servers without structured outputs are asked for plain JSON instead
+func JudgeAnswer(ctx context.Context, provider llm.LLMProvider, model, question, expected, typed string) (Judgement, error) {
+	request := llm.Request{Model: model, Prompt: judgePrompt(question, expected, typed), Schema: judgementSchema}
+	resp, err := provider.Generate(ctx, request)
+	if errors.Is(err, llm.ErrStructuredUnsupported) {
+		request.Schema, request.JSON = nil, true
+		resp, err = provider.Generate(ctx, request)
+	}
+	if err != nil {
+		return Judgement{}, err
+	}
+	return ParseJudgement(resp)
+}
+
+// ParseJudgement decodes and validates a judgement returned by the model
+func ParseJudgement(response string) (Judgement, error) {
+	var j Judgement
+	if err := json.Unmarshal([]byte(strings.TrimSpace(response)), &j); err != nil {
+		return Judgement{}, fmt.Errorf("%w: %w", ErrMalformedResponse, err)
+	}
+	if j.Grade < 1 || j.Grade > 4 {
+		return Judgement{}, fmt.Errorf("%w: grade %d is not between 1 and 4", ErrMalformedResponse, j.Grade)
+	}
+	// Keep the explanation on a single line
+	j.Explanation = strings.Join(strings.Fields(j.Explanation), " ")
+	return j, nil
+}
+
+// judgePrompt asks the model to compare a typed answer with the stored answer
+func judgePrompt(question, expected, typed string) string {
+	return fmt.Sprintf(`You are grading a flashcard review. Compare the student's answer with the reference answer and judge whether the student recalled the same knowledge. Ignore spelling, wording and formatting differences that do not change the meaning.
+
+Grades:
+1 = Again: wrong, missing or mostly incomplete
+2 = Hard: partially correct or with a notable mistake
+3 = Good: correct
+4 = Easy: correct, complete and precise
+
+Respond with a JSON object with "grade" (1-4) and "explanation" (one short sentence addressed to the student).
+
+Question:
+%s
+
+Reference answer:
+%s
+
+Student answer:
+%s`, question, expected, typed)
+}
@@ -0,0 +1,56 @@
+package ollama
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"catv/internal/llm"
+)
+
+func TestJudgeAnswer(t *testing.T) {
+	fake := &llm.Fake{Respond: func(req llm.Request) (string, error) {
+		if !strings.Contains(req.Prompt, "git push --force-with-lease") || !strings.Contains(req.Prompt, "git push -f") {
+			t.Errorf("Expected both answers in the prompt, got %q", req.Prompt)
+		}
+		if req.Model != "test-model" || len(req.Schema) == 0 {
+			t.Errorf("Expected a schema for the test model, got %+v", req)
+		}
+		return `{"grade": 2, "explanation": "Right idea, but -f can\noverwrite others' work."}`, nil
+	}}
+
+	j, err := JudgeAnswer(context.Background(), fake, "test-model", "Force push safely?", "git push --force-with-lease", "git push -f")
+	if err != nil {
+		t.Fatalf("JudgeAnswer() error = %v", err)
+	}
+	if j.Grade != 2 || j.Explanation != "Right idea, but -f can overwrite others' work." {
+		t.Errorf("Unexpected judgement %+v", j)
+	}
+}
+
+func TestJudgeAnswer_JSONModeFallback(t *testing.T) {
+	fake := &llm.Fake{Respond: func(req llm.Request) (string, error) {
+		if req.Schema != nil {
+			return "", llm.ErrStructuredUnsupported
+		}
+		return `{"grade": 3, "explanation": "Correct."}`, nil
+	}}
+
+	j, err := JudgeAnswer(context.Background(), fake, "test-model", "Q", "A", "A")
+	if err != nil {
+		t.Fatalf("JudgeAnswer() error = %v", err)
+	}
+	requests := fake.Requests()
+	if j.Grade != 3 || len(requests) != 2 || !requests[1].JSON {
+		t.Errorf("Expected a JSON mode retry, got %+v after requests %+v", j, requests)
+	}
+}
+
+func TestParseJudgement(t *testing.T) {
+	for _, input := range []string{`{"grade": 0, "explanation": "x"}`, `{"grade": 5}`, "Good job!"} {
+		if _, err := ParseJudgement(input); !errors.Is(err, ErrMalformedResponse) {
+			t.Errorf("ParseJudgement(%q) error = %v, expected ErrMalformedResponse", input, err)
+		}
+	}
+}
@@ -1,89 +1,70 @@
 package ollama
 
 import (
-	"bytes"
-	"context"
-	"encoding/json"
-	"fmt"
-	"net/http"
 	"strings"
-	"time"
 )
 
-// OllamaRequest represents the request to the Ollama API
-type OllamaRequest struct {
-	Model  string `json:"model"`
-	Prompt string `json:"prompt"`
-}
-
-// GenerateQA sends a prompt to the Ollama API and returns the response
-func GenerateQA(ctx context.Context, model, url, prompt string) (string, error) {
-	body, err := json.Marshal(OllamaRequest{Model: model, Prompt: prompt})
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	// Create HTTP client with timeout
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-	}
-
-	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(body))
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := client.Do(req) // #nosec G107 - URL is from config, validated by caller
-	if err != nil {
-		return "", fmt.Errorf("failed to send request: %w", err)
-	}
-	defer func() {
-		_ = resp.Body.Close()
-	}()
+// ParseFlashcards parses the model response and returns a list of questions and answers
+// Lines after a "Q:" or "A:" marker belong to that question or answer until the
+// next marker, so answers can hold lists, code blocks and several paragraphs;
+// markers inside fenced code blocks are ignored
+func ParseFlashcards(response string) ([]map[string]string, error) {
+	qas := []map[string]string{}
+	var q, a []string
+	inAnswer := false
+	inFence := false
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	emit := func() {
+		question := strings.TrimSpace(strings.Join(q, "\n"))
+		answer := strings.TrimSpace(strings.Join(a, "\n"))
+		if question != "" && answer != "" {
+			qas = append(qas, map[string]string{"question": question, "answer": answer})
+		}
+		q, a = nil, nil
 	}
 
-	var response bytes.Buffer
-	dec := json.NewDecoder(resp.Body)
-	for {
-		var chunk map[string]interface{}
-		if err := dec.Decode(&chunk); err != nil {
-			break
+	for _, line := range strings.Split(strings.ReplaceAll(response, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
 		}
-		if r, ok := chunk["response"].(string); ok {
-			response.WriteString(r)
+		marker, text := "", ""
+		if !inFence {
+			marker, text = parseMarker(trimmed)
 		}
-		if done, ok := chunk["done"].(bool); ok && done {
-			break
+		switch {
+		case marker == "Q:":
+			if inAnswer {
+				emit()
+			}
+			// A question without an answer is replaced by the next one
+			q, a = []string{text}, nil
+			inAnswer = false
+		case marker == "A:" && len(q) > 0:
+			a = []string{text}
+			inAnswer = true
+		case inAnswer:
+			a = append(a, strings.TrimRight(line, " \t"))
+		case len(q) > 0 && trimmed != "":
+			q = append(q, trimmed)
 		}
 	}
-	return response.String(), nil
+	if inAnswer {
+		emit()
+	}
+	return qas, nil
 }
 
-// ParseFlashcards parses the Ollama response and returns a list of questions and answers
-func ParseFlashcards(response string) ([]map[string]string, error) {
-	var qas []map[string]string
-	lines := strings.Split(response, "\n")
-	var q, a string
-	for _, line := range lines {
-		l := strings.TrimSpace(line)
-		l = strings.Trim(l, "*: ")
-		if len(l) < 2 {
-			continue
-		}
-		switch l[:2] {
-		case "Q:":
-			q = strings.TrimSpace(strings.Trim(l[2:], "*: "))
-		case "A:":
-			a = strings.TrimSpace(strings.Trim(l[2:], "*: "))
-			if q != "" && a != "" {
-				qas = append(qas, map[string]string{"question": q, "answer": a})
-				q = "" // Reset for next Q/A pair
-			}
-		}
+// parseMarker recognizes a "Q:" or "A:" line, also when the model wraps the
+// marker in markdown emphasis such as "**Q:**", and returns the text after it
+func parseMarker(line string) (string, string) {
+	l := strings.TrimLeft(line, "* ")
+	if len(l) < 2 {
+		return "", ""
 	}
-	return qas, nil
+	switch l[:2] {
+	case "Q:", "A:":
+		return l[:2], strings.TrimSpace(strings.TrimLeft(l[2:], "*: "))
+	}
+	return "", ""
 }
@@ -0,0 +1,56 @@
+package ollama
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// PartialCards returns the flashcards already complete in a response that is
+// still being generated, in the format it was requested in, so they can be
+// previewed before the response ends
+// The last Q:/A: pair is left out, as more of its answer may follow
+func PartialCards(response string, structured bool) []GeneratedCard {
+	if structured {
+		return partialStructured(response)
+	}
+	// Pairs before the last question are complete
+	end, offset := 0, 0
+	for _, line := range strings.SplitAfter(response, "\n") {
+		if marker, _ := parseMarker(strings.TrimSpace(line)); marker == "Q:" {
+			end = offset
+		}
+		offset += len(line)
+	}
+	qas, _ := ParseFlashcards(response[:end])
+	cards := make([]GeneratedCard, len(qas))
+	for i, qa := range qas {
+		cards[i] = GeneratedCard{Type: BasicCard, Question: qa["question"], Answer: qa["answer"]}
+	}
+	return cards
+}
+
+// partialStructured decodes the cards of the flashcards array up to the first
+// one that is not complete yet
+func partialStructured(response string) []GeneratedCard {
+	start := strings.Index(response, `"flashcards"`)
+	if start < 0 {
+		return nil
+	}
+	open := strings.IndexByte(response[start:], '[')
+	if open < 0 {
+		return nil
+	}
+	dec := json.NewDecoder(strings.NewReader(response[start+open:]))
+	if _, err := dec.Token(); err != nil {
+		return nil
+	}
+	var cards []GeneratedCard
+	for dec.More() {
+		var c GeneratedCard
+		if err := dec.Decode(&c); err != nil {
+			break
+		}
+		cards = append(cards, c)
+	}
+	return cards
+}
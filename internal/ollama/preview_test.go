@@ -0,0 +1,33 @@
+package ollama
+
+import "testing"
+
+func TestPartialCards(t *testing.T) {
+	tests := []struct {
+		name       string
+		response   string
+		structured bool
+		want       []string // Questions of the complete cards
+	}{
+		{"empty", "", true, nil},
+		{"before the array", `{"flash`, true, nil},
+		{"card in progress", `{"flashcards": [{"question": "What is Go?", "ans`, true, nil},
+		{"one complete card", `{"flashcards": [{"question": "What is Go?", "answer": "A language"}, {"quest`, true, []string{"What is Go?"}},
+		{"complete response", `{"flashcards": [{"question": "A?", "answer": "a"}, {"question": "B?", "answer": "b"}]}`, true, []string{"A?", "B?"}},
+		{"lines, answer in progress", "Q: What is Go?\nA: A lang", false, nil},
+		{"lines, next question started", "Q: What is Go?\nA: A language\nQ: Who made", false, []string{"What is Go?"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cards := PartialCards(tt.response, tt.structured)
+			if len(cards) != len(tt.want) {
+				t.Fatalf("PartialCards() returned %d cards, expected %d: %+v", len(cards), len(tt.want), cards)
+			}
+			for i, c := range cards {
+				if c.Question != tt.want[i] {
+					t.Errorf("Card %d question = %q, expected %q", i, c.Question, tt.want[i])
+				}
+			}
+		})
+	}
+}
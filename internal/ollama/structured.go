@@ -0,0 +1,92 @@
+package ollama
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"catv/internal/cloze"
+)
+
+// ErrMalformedResponse is returned when a structured response cannot be decoded
+// or contains no usable flashcards
+var ErrMalformedResponse = errors.New("malformed flashcard response")
+
+// GeneratedCard is a flashcard produced by the model in structured output mode
+// Cloze cards carry their text with {{c1::...}} deletions in Question
+type GeneratedCard struct {
+	Type          string   `json:"type,omitempty"` // "basic" (default) or "cloze"
+	Question      string   `json:"question"`
+	Answer        string   `json:"answer"`
+	Tags          []string `json:"tags,omitempty"`
+	SourceHeading string   `json:"source_heading,omitempty"` // Heading the model took the card from
+}
+
+// Card types a model can generate
+const (
+	BasicCard = "basic"
+	ClozeCard = "cloze"
+)
+
+// FlashcardSchema is the JSON schema generated flashcards must follow
+var FlashcardSchema = json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "flashcards": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "properties": {
+          "type": {"type": "string", "enum": ["basic", "cloze"]},
+          "question": {"type": "string"},
+          "answer": {"type": "string"},
+          "tags": {"type": "array", "items": {"type": "string"}},
+          "source_heading": {"type": "string"}
+        },
+        "required": ["question", "answer"]
+      }
+    }
+  },
+  "required": ["flashcards"]
+}`)
+
+// ParseGeneratedCards decodes a response produced with FlashcardSchema
+// Cards without a question or answer are dropped, except cloze cards which only
+// need deletions in their text; a card whose text has deletions is a cloze card
+// whatever its declared type, and a declared cloze without any is dropped
+// A response that is not valid
+// JSON, or whose cards are all invalid, returns ErrMalformedResponse
+// An empty flashcards list is valid and means the content had nothing to learn
+func ParseGeneratedCards(response string) ([]GeneratedCard, error) {
+	var payload struct {
+		Flashcards *[]GeneratedCard `json:"flashcards"`
+	}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(response)), &payload); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMalformedResponse, err)
+	}
+	if payload.Flashcards == nil {
+		return nil, fmt.Errorf("%w: missing flashcards field", ErrMalformedResponse)
+	}
+
+	raw := *payload.Flashcards
+	cards := make([]GeneratedCard, 0, len(raw))
+	for _, c := range raw {
+		c.Question = strings.TrimSpace(c.Question)
+		c.Answer = strings.TrimSpace(c.Answer)
+		c.SourceHeading = strings.TrimSpace(c.SourceHeading)
+		switch {
+		case cloze.Has(c.Question):
+			c.Type = ClozeCard
+		case c.Type != ClozeCard && c.Question != "" && c.Answer != "":
+			c.Type = BasicCard
+		default:
+			continue
+		}
+		cards = append(cards, c)
+	}
+	if len(raw) > 0 && len(cards) == 0 {
+		return nil, fmt.Errorf("%w: no flashcard has both a question and an answer", ErrMalformedResponse)
+	}
+	return cards, nil
+}
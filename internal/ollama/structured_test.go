@@ -0,0 +1,45 @@
+package ollama
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseGeneratedCards(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		count   int
+		wantErr bool
+	}{
+		{"valid", `{"flashcards": [{"question": "Q1", "answer": "line 1\nline 2", "tags": ["go"]}, {"question": "Q2", "answer": "A2"}]}`, 2, false},
+		{"empty list", `{"flashcards": []}`, 0, false},
+		{"invalid cards dropped", `{"flashcards": [{"question": "Q1", "answer": " "}, {"question": "Q2", "answer": "A2"}]}`, 1, false},
+		{"cloze without answer", `{"flashcards": [{"type": "cloze", "question": "Go has {{c1::goroutines}}", "answer": ""}]}`, 1, false},
+		{"cloze without deletions", `{"flashcards": [{"type": "cloze", "question": "Go has goroutines", "answer": "x"}]}`, 0, true},
+		{"all cards invalid", `{"flashcards": [{"question": "Q1"}]}`, 0, true},
+		{"missing field", `{"cards": []}`, 0, true},
+		{"not json", "Q: What?\nA: That", 0, true},
+		{"truncated", `{"flashcards": [{"question": "Q1"`, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cards, err := ParseGeneratedCards(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseGeneratedCards() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrMalformedResponse) {
+				t.Errorf("Expected ErrMalformedResponse, got %v", err)
+			}
+			if len(cards) != tt.count {
+				t.Errorf("Expected %d cards, got %d", tt.count, len(cards))
+			}
+			for _, c := range cards {
+				if c.Type != BasicCard && c.Type != ClozeCard {
+					t.Errorf("Expected a card type to be set, got %q", c.Type)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+// Package prompt renders the prompts that ask the model for flashcards from
+// text/template files, either built in or written by the user
+package prompt
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+//go:embed templates/*.tmpl
+var builtin embed.FS
+
+// partials defines the blocks every template can use: "format" describes the
+// response format the flashcards are parsed from, "context" the section the
+// note comes from, "options" the card count, language, difficulty and extra
+// instructions, and "note" the markdown itself
+const partials = "partials"
+
+// DefaultTemplate is used when neither the command line, the configuration
+// nor the note chooses a template
+const DefaultTemplate = "default"
+
+// Data holds the variables available to prompt templates
+type Data struct {
+	Content      string // Markdown of the note chunk
+	File         string // Base name of the note file
+	Heading      string // Heading path of the section, e.g. "Go > Channels"
+	Cards        int    // Desired number of flashcards, 0 to let the model decide
+	Language     string // Language to write the flashcards in, "" for the note's language
+	Difficulty   string // Level to pitch the flashcards at, e.g. "beginner", "" for any
+	Instructions string // Extra instructions from the configuration
+	Structured   bool   // Whether the model is asked for JSON rather than Q:/A: pairs
+}
+
+// Template is a parsed prompt template
+type Template struct {
+	name string
+	tmpl *template.Template
+}
+
+// Names returns the sorted names of the built-in templates
+func Names() []string {
+	entries, _ := builtin.ReadDir("templates")
+	var names []string
+	for _, e := range entries {
+		if name := strings.TrimSuffix(e.Name(), ".tmpl"); name != partials {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Load returns the built-in template called name, or else parses the template
+// file at that path
+// An empty name loads the default template
+func Load(name string) (*Template, error) {
+	if name == "" {
+		name = DefaultTemplate
+	}
+	text, err := builtin.ReadFile("templates/" + name + ".tmpl")
+	if err != nil || name == partials {
+		text, err = os.ReadFile(filepath.Clean(name))
+		if err != nil {
+			return nil, fmt.Errorf("prompt template %q is neither built in (%s) nor a readable file: %w",
+				name, strings.Join(Names(), ", "), err)
+		}
+	}
+	return parse(name, string(text))
+}
+
+// Default returns the default template
+func Default() *Template {
+	t, err := Load(DefaultTemplate)
+	if err != nil {
+		panic(err) // the built-in templates are checked by the tests
+	}
+	return t
+}
+
+// parse parses a template on top of the shared partials
+func parse(name, text string) (*Template, error) {
+	if !strings.Contains(text, `template "format"`) {
+		return nil, fmt.Errorf(`prompt template %q must include {{template "format" .}} so the flashcards can be read`, name)
+	}
+	shared, err := builtin.ReadFile("templates/" + partials + ".tmpl")
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := template.New(name).
+		Funcs(template.FuncMap{"trim": strings.TrimSpace}).
+		Option("missingkey=error").
+		Parse(string(shared))
+	if err != nil {
+		return nil, err
+	}
+	if tmpl, err = tmpl.Parse(text); err != nil {
+		return nil, fmt.Errorf("prompt template %q: %w", name, err)
+	}
+	return &Template{name: name, tmpl: tmpl}, nil
+}
+
+// Name returns the built-in name or path the template was loaded from
+func (t *Template) Name() string {
+	return t.name
+}
+
+// Render returns the prompt for the given variables
+func (t *Template) Render(data Data) (string, error) {
+	var s strings.Builder
+	if err := t.tmpl.Execute(&s, data); err != nil {
+		return "", fmt.Errorf("prompt template %q: %w", t.name, err)
+	}
+	return strings.TrimSpace(s.String()), nil
+}
@@ -0,0 +1,97 @@
+package prompt
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuiltinTemplates(t *testing.T) {
+	names := Names()
+	if strings.Join(names, ",") != "code,concepts,default,definitions,vocabulary" {
+		t.Errorf("Unexpected built-in templates %v", names)
+	}
+	for _, name := range names {
+		tmpl, err := Load(name)
+		if err != nil {
+			t.Fatalf("Load(%q) error = %v", name, err)
+		}
+		for _, structured := range []bool{true, false} {
+			p, err := tmpl.Render(Data{Content: "Channels connect goroutines.", File: "go.md", Heading: "Go > Channels", Structured: structured})
+			if err != nil {
+				t.Fatalf("Render(%q) error = %v", name, err)
+			}
+			format := "Q: <question>"
+			if structured {
+				format = `"flashcards" array`
+			}
+			for _, want := range []string{format, `"Go > Channels"`, "Markdown:\nChannels connect goroutines."} {
+				if !strings.Contains(p, want) {
+					t.Errorf("Template %q (structured %v) is missing %q:\n%s", name, structured, want, p)
+				}
+			}
+		}
+	}
+}
+
+func TestDefaultTemplate(t *testing.T) {
+	p, err := Default().Render(Data{Content: "Content", Structured: true})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if !strings.HasPrefix(p, "You are an expert flashcard generator.") || strings.Contains(p, "larger note") {
+		t.Errorf("Unexpected prompt without a heading:\n%s", p)
+	}
+	if !strings.Contains(p, "{{c1::term}}") || !strings.HasSuffix(p, "empty \"flashcards\" array.\n\nMarkdown:\nContent") {
+		t.Errorf("Unexpected structured prompt:\n%s", p)
+	}
+
+	p, _ = Default().Render(Data{Content: "Content", Cards: 5, Language: "German", Difficulty: "beginner", Instructions: " Keep answers short.\n"})
+	want := "Write about 5 flashcards.\nWrite the questions and answers in German.\nPitch the flashcards at a beginner level.\n\nKeep answers short.\n\nMarkdown:\nContent"
+	if !strings.HasSuffix(p, want) {
+		t.Errorf("Expected the options before the note, got:\n%s", p)
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+	custom := filepath.Join(dir, "custom.tmpl")
+	if err := os.WriteFile(custom, []byte(`Cards for {{.File}}{{if .Heading}} ({{.Heading}}){{end}}.
+{{template "format" .}}{{template "note" .}}`), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	tmpl, err := Load(custom)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	p, err := tmpl.Render(Data{File: "go.md", Content: "Content"})
+	if err != nil || !strings.HasPrefix(p, "Cards for go.md.\nStrictly output ONLY pairs") || tmpl.Name() != custom {
+		t.Errorf("Unexpected custom prompt %q (%v)", p, err)
+	}
+
+	broken := filepath.Join(dir, "broken.tmpl")
+	for text, want := range map[string]string{
+		"Cards for {{.File}}":                 `must include {{template "format" .}}`,
+		`{{template "format" .}}{{.Missing}}`: "Missing",
+		`{{template "format" .}}{{if}}`:       "missing value for if",
+	} {
+		if err := os.WriteFile(broken, []byte(text), 0600); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+		tmpl, err := Load(broken)
+		if err == nil {
+			_, err = tmpl.Render(Data{})
+		}
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected an error mentioning %q for %q, got %v", want, text, err)
+		}
+	}
+
+	if _, err := Load("trivia"); err == nil || !strings.Contains(err.Error(), "definitions") {
+		t.Errorf("Expected an unknown template to list the built-in ones, got %v", err)
+	}
+	if _, err := Load("partials"); err == nil {
+		t.Error("Expected the partials not to load as a template")
+	}
+}
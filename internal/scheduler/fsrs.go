@@ -0,0 +1,137 @@
+package scheduler
+
+import (
+	"math"
+	"time"
+)
+
+// FSRS constants for the power forgetting curve R(t, S) = (1 + factor*t/S)^decay
+const (
+	fsrsDecay  = -0.5
+	fsrsFactor = 19.0 / 81.0
+)
+
+// DefaultFSRSWeights are the FSRS-4.5 default model parameters
+var DefaultFSRSWeights = [17]float64{
+	0.4872, 1.4003, 3.7145, 13.8206, 5.1618, 1.2298, 0.8975, 0.031,
+	1.6474, 0.1367, 1.0461, 2.1072, 0.0793, 0.3246, 1.587, 0.2272, 2.8755,
+}
+
+// FSRS implements the Free Spaced Repetition Scheduler (version 4.5), which
+// models each card's memory stability and difficulty
+type FSRS struct {
+	Weights          [17]float64 // Model parameters
+	RequestRetention float64     // Probability of recall targeted when the card comes due
+	MaximumInterval  int         // Longest interval in days
+}
+
+// NewFSRS returns an FSRS scheduler using the default weights and 90% retention
+func NewFSRS() *FSRS {
+	return &FSRS{
+		Weights:          DefaultFSRSWeights,
+		RequestRetention: 0.9,
+		MaximumInterval:  36500,
+	}
+}
+
+// Name returns the identifier of the algorithm
+func (f *FSRS) Name() string {
+	return NameFSRS
+}
+
+// Next returns the state after reviewing a card with the given grade
+func (f *FSRS) Next(state State, grade Grade, now time.Time) State {
+	next := state
+	next.LastReview = now
+
+	stability := func(g Grade) float64 {
+		if state.Stability <= 0 {
+			return f.initStability(g)
+		}
+		r := f.retrievability(float64(elapsedDays(state.LastReview, now)), state.Stability)
+		if g == Again {
+			return f.forgetStability(state.Difficulty, state.Stability, r)
+		}
+		return f.recallStability(state.Difficulty, state.Stability, r, g)
+	}
+
+	if state.Stability <= 0 {
+		next.Difficulty = f.initDifficulty(grade)
+	} else {
+		next.Difficulty = f.nextDifficulty(state.Difficulty, grade)
+	}
+	next.Stability = stability(grade)
+
+	if grade == Again {
+		if state.Repetitions > 0 || !state.LastReview.IsZero() {
+			next.Lapses++
+		}
+		next.Repetitions = 0
+		next.Interval = 1
+		return next
+	}
+
+	hard, good, easy := orderIntervals(
+		f.interval(stability(Hard)),
+		f.interval(stability(Good)),
+		f.interval(stability(Easy)),
+	)
+	next.Repetitions = state.Repetitions + 1
+	switch grade {
+	case Hard:
+		next.Interval = hard
+	case Easy:
+		next.Interval = easy
+	default:
+		next.Interval = good
+	}
+	next.Interval = min(next.Interval, f.MaximumInterval)
+	return next
+}
+
+// retrievability returns the probability of recall after elapsed days
+func (f *FSRS) retrievability(elapsed, stability float64) float64 {
+	return math.Pow(1+fsrsFactor*elapsed/stability, fsrsDecay)
+}
+
+// interval returns the days until recall probability drops to the requested retention
+func (f *FSRS) interval(stability float64) int {
+	days := stability / fsrsFactor * (math.Pow(f.RequestRetention, 1/fsrsDecay) - 1)
+	return int(math.Max(1, math.Round(days)))
+}
+
+func (f *FSRS) initStability(g Grade) float64 {
+	return math.Max(f.Weights[g-1], 0.1)
+}
+
+func (f *FSRS) initDifficulty(g Grade) float64 {
+	return clampDifficulty(f.Weights[4] - float64(g-3)*f.Weights[5])
+}
+
+func (f *FSRS) nextDifficulty(d float64, g Grade) float64 {
+	next := d - f.Weights[6]*float64(g-3)
+	// Mean reversion towards the difficulty of a new card graded Easy, D0(4)
+	// in FSRS-4.5
+	return clampDifficulty(f.Weights[7]*f.initDifficulty(Easy) + (1-f.Weights[7])*next)
+}
+
+func (f *FSRS) recallStability(d, s, r float64, g Grade) float64 {
+	w := f.Weights
+	hardPenalty, easyBonus := 1.0, 1.0
+	if g == Hard {
+		hardPenalty = w[15]
+	}
+	if g == Easy {
+		easyBonus = w[16]
+	}
+	return s * (1 + math.Exp(w[8])*(11-d)*math.Pow(s, -w[9])*(math.Exp((1-r)*w[10])-1)*hardPenalty*easyBonus)
+}
+
+func (f *FSRS) forgetStability(d, s, r float64) float64 {
+	w := f.Weights
+	return w[11] * math.Pow(d, -w[12]) * (math.Pow(s+1, w[13]) - 1) * math.Exp((1-r)*w[14])
+}
+
+func clampDifficulty(d float64) float64 {
+	return math.Min(math.Max(d, 1), 10)
+}
@@ -0,0 +1,71 @@
+package scheduler
+
+import (
+	"sort"
+
+	"catv/internal/store"
+)
+
+// Limits caps how many flashcards are studied per day
+type Limits struct {
+	NewCards int // Flashcards studied for the first time
+	Reviews  int // Reviews of flashcards studied before
+}
+
+// Queue holds the due flashcards of a session, split into the ones never
+// reviewed and the ones studied before
+type Queue struct {
+	New    []store.Flashcard // In the order they were given
+	Review []store.Flashcard // Most overdue first
+}
+
+// NewQueue splits due flashcards into new and review queues
+func NewQueue(flashcards []store.Flashcard) Queue {
+	var q Queue
+	for _, fc := range flashcards {
+		if fc.IsNew() {
+			q.New = append(q.New, fc)
+		} else {
+			q.Review = append(q.Review, fc)
+		}
+	}
+	sort.SliceStable(q.Review, func(i, j int) bool {
+		return q.Review[i].DueAt.Before(q.Review[j].DueAt)
+	})
+	return q
+}
+
+// Limit drops the flashcards beyond what is left of the daily limits after
+// the ones already studied that day
+func (q Queue) Limit(limits Limits, studied store.StudyDay) Queue {
+	return Queue{
+		New:    head(q.New, limits.NewCards-studied.NewCards),
+		Review: head(q.Review, limits.Reviews-studied.Reviews),
+	}
+}
+
+// head returns the first n flashcards, or none if n is negative
+func head(flashcards []store.Flashcard, n int) []store.Flashcard {
+	if n < 0 {
+		n = 0
+	}
+	if n < len(flashcards) {
+		return flashcards[:n]
+	}
+	return flashcards
+}
+
+// Interleave spreads the new flashcards evenly between the reviews, so a
+// session mixes both from start to end
+func (q Queue) Interleave() []store.Flashcard {
+	flashcards := make([]store.Flashcard, 0, len(q.New)+len(q.Review))
+	reviewed, gaps := 0, len(q.New)+1
+	for i, fc := range q.New {
+		// Reviews before the new flashcard, rounded to the nearest whole gap
+		upto := (2*(i+1)*len(q.Review) + gaps) / (2 * gaps)
+		flashcards = append(flashcards, q.Review[reviewed:upto]...)
+		flashcards = append(flashcards, fc)
+		reviewed = upto
+	}
+	return append(flashcards, q.Review[reviewed:]...)
+}
@@ -0,0 +1,67 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"catv/internal/store"
+)
+
+func queueIDs(flashcards []store.Flashcard) []int {
+	ids := make([]int, len(flashcards))
+	for i, fc := range flashcards {
+		ids[i] = fc.ID
+	}
+	return ids
+}
+
+func equalIDs(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestQueue(t *testing.T) {
+	now := time.Now()
+	reviewed := func(id, overdue int) store.Flashcard {
+		return store.Flashcard{ID: id, LastReviewedAt: now.AddDate(0, 0, -10), Repetitions: 1, DueAt: now.AddDate(0, 0, -overdue)}
+	}
+	cards := []store.Flashcard{
+		{ID: 1}, reviewed(2, 1), reviewed(3, 5), {ID: 4}, reviewed(5, 3), reviewed(6, 2), {ID: 7}, reviewed(8, 4),
+	}
+
+	q := NewQueue(cards)
+	if got := queueIDs(q.New); !equalIDs(got, []int{1, 4, 7}) {
+		t.Errorf("New queue = %v, want [1 4 7]", got)
+	}
+	if got := queueIDs(q.Review); !equalIDs(got, []int{3, 8, 5, 6, 2}) {
+		t.Errorf("Review queue = %v, want the most overdue first", got)
+	}
+
+	tests := []struct {
+		name    string
+		limits  Limits
+		studied store.StudyDay
+		want    []int
+	}{
+		{"no limits reached", Limits{NewCards: 20, Reviews: 200}, store.StudyDay{}, []int{3, 1, 8, 5, 4, 6, 7, 2}},
+		{"new limit", Limits{NewCards: 1, Reviews: 200}, store.StudyDay{}, []int{3, 8, 5, 1, 6, 2}},
+		{"studied earlier today", Limits{NewCards: 2, Reviews: 4}, store.StudyDay{NewCards: 1, Reviews: 2}, []int{3, 1, 8}},
+		{"limits exceeded", Limits{NewCards: 2, Reviews: 4}, store.StudyDay{NewCards: 5, Reviews: 5}, []int{}},
+		{"no new cards", Limits{NewCards: 0, Reviews: 2}, store.StudyDay{}, []int{3, 8}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := queueIDs(q.Limit(tt.limits, tt.studied).Interleave())
+			if !equalIDs(got, tt.want) {
+				t.Errorf("Interleave() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
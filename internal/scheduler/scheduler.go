@@ -0,0 +1,162 @@
+// Package scheduler implements spaced repetition algorithms that decide when
+// a flashcard should be reviewed next based on how well it was remembered
+package scheduler
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"catv/internal/store"
+)
+
+// Grade is the self-assessed recall quality of a review
+type Grade int
+
+// Grades offered after revealing an answer, from worst to best
+const (
+	Again Grade = iota + 1 // Forgot the answer
+	Hard                   // Recalled with serious difficulty
+	Good                   // Recalled after some hesitation
+	Easy                   // Recalled effortlessly
+)
+
+// Grades lists every grade in display order
+var Grades = []Grade{Again, Hard, Good, Easy}
+
+// String returns the button label for the grade
+func (g Grade) String() string {
+	switch g {
+	case Again:
+		return "Again"
+	case Hard:
+		return "Hard"
+	case Good:
+		return "Good"
+	case Easy:
+		return "Easy"
+	default:
+		return "Ungraded"
+	}
+}
+
+// State holds the per-card memory state tracked across reviews
+type State struct {
+	Ease        float64   // SM-2 ease factor
+	Repetitions int       // Consecutive successful reviews
+	Lapses      int       // Number of times the card was forgotten
+	Stability   float64   // FSRS memory stability in days
+	Difficulty  float64   // FSRS difficulty between 1 and 10
+	Interval    int       // Days between the last review and the due date
+	LastReview  time.Time // When the card was last reviewed (zero if never)
+}
+
+// Scheduler computes the next memory state of a card after it is graded
+type Scheduler interface {
+	// Name returns the identifier used to select the algorithm in configuration
+	Name() string
+	// Next returns the state after reviewing a card with the given grade at now
+	Next(state State, grade Grade, now time.Time) State
+}
+
+// Algorithm names accepted by New
+const (
+	NameSM2  = "sm2"
+	NameFSRS = "fsrs"
+)
+
+var registry = map[string]func() Scheduler{
+	NameSM2:  func() Scheduler { return NewSM2() },
+	NameFSRS: func() Scheduler { return NewFSRS() },
+}
+
+// New returns the scheduler registered under name
+func New(name string) (Scheduler, error) {
+	factory, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown scheduler %q (available: %v)", name, Names())
+	}
+	return factory(), nil
+}
+
+// Names returns the sorted list of available scheduler names
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Preview returns the interval in days that each grade would produce
+func Preview(s Scheduler, state State, now time.Time) map[Grade]int {
+	intervals := make(map[Grade]int, len(Grades))
+	for _, g := range Grades {
+		intervals[g] = s.Next(state, g, now).Interval
+	}
+	return intervals
+}
+
+// StateOf extracts the scheduling state stored on a flashcard
+func StateOf(fc store.Flashcard) State {
+	return State{
+		Ease:        fc.Ease,
+		Repetitions: fc.Repetitions,
+		Lapses:      fc.Lapses,
+		Stability:   fc.Stability,
+		Difficulty:  fc.Difficulty,
+		Interval:    fc.IntervalDays,
+		LastReview:  fc.LastReviewedAt,
+	}
+}
+
+// Apply stores the state on a flashcard reviewed at now and sets its next due date
+func Apply(fc *store.Flashcard, state State, now time.Time) {
+	fc.Ease = state.Ease
+	fc.Repetitions = state.Repetitions
+	fc.Lapses = state.Lapses
+	fc.Stability = state.Stability
+	fc.Difficulty = state.Difficulty
+	fc.IntervalDays = state.Interval
+	fc.LastReviewedAt = now
+	fc.DueAt = store.DueAfter(now, state.Interval)
+}
+
+// FormatInterval renders a number of days compactly, e.g. "3d", "1.5mo" or "2y"
+func FormatInterval(days int) string {
+	switch {
+	case days < 30:
+		return fmt.Sprintf("%dd", days)
+	case days < 365:
+		return trimZero(float64(days)/30) + "mo"
+	default:
+		return trimZero(float64(days)/365) + "y"
+	}
+}
+
+// trimZero formats v with one decimal, dropping a trailing ".0"
+func trimZero(v float64) string {
+	v = math.Round(v*10) / 10
+	if v == math.Trunc(v) {
+		return fmt.Sprintf("%.0f", v)
+	}
+	return fmt.Sprintf("%.1f", v)
+}
+
+// elapsedDays returns the whole days between the last review and now
+func elapsedDays(last, now time.Time) int {
+	if last.IsZero() || now.Before(last) {
+		return 0
+	}
+	return int(now.Sub(last).Hours() / 24)
+}
+
+// orderIntervals makes sure harder grades never produce longer intervals than easier ones
+func orderIntervals(hard, good, easy int) (int, int, int) {
+	hard = max(hard, 1)
+	good = max(good, hard+1)
+	easy = max(easy, good+1)
+	return hard, good, easy
+}
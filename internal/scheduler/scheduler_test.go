@@ -0,0 +1,186 @@
+package scheduler
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"catv/internal/store"
+)
+
+func TestNew(t *testing.T) {
+	for _, name := range Names() {
+		s, err := New(name)
+		if err != nil {
+			t.Fatalf("New(%q) returned error: %v", name, err)
+		}
+		if s.Name() != name {
+			t.Errorf("Expected scheduler %q, got %q", name, s.Name())
+		}
+	}
+
+	if _, err := New("leitner"); err == nil {
+		t.Error("Expected error for unknown scheduler")
+	}
+}
+
+func TestFormatInterval(t *testing.T) {
+	tests := []struct {
+		days     int
+		expected string
+	}{
+		{1, "1d"},
+		{29, "29d"},
+		{30, "1mo"},
+		{45, "1.5mo"},
+		{365, "1y"},
+		{730, "2y"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatInterval(tt.days); got != tt.expected {
+			t.Errorf("FormatInterval(%d) = %q, expected %q", tt.days, got, tt.expected)
+		}
+	}
+}
+
+func TestPreviewIsOrdered(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	states := []State{
+		{},
+		{Ease: 2.5, Repetitions: 1, Interval: 1, Stability: 3, Difficulty: 5, LastReview: now.AddDate(0, 0, -1)},
+		{Ease: 1.3, Repetitions: 4, Interval: 30, Stability: 40, Difficulty: 9, LastReview: now.AddDate(0, 0, -30)},
+	}
+
+	for _, s := range []Scheduler{NewSM2(), NewFSRS()} {
+		for _, state := range states {
+			p := Preview(s, state, now)
+			if p[Again] != 1 {
+				t.Errorf("%s: expected Again interval 1, got %d", s.Name(), p[Again])
+			}
+			if !(p[Hard] >= 1 && p[Hard] < p[Good] && p[Good] < p[Easy]) {
+				t.Errorf("%s: intervals not ordered for %+v: %v", s.Name(), state, p)
+			}
+		}
+	}
+}
+
+func TestSM2Next(t *testing.T) {
+	s := NewSM2()
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	state := s.Next(State{}, Good, now)
+	if state.Repetitions != 1 || state.Interval != 2 {
+		t.Errorf("Expected first Good review to give 1 repetition and 2 days, got %+v", state)
+	}
+	if state.Ease != DefaultEase {
+		t.Errorf("Expected Good to keep ease %.2f, got %.2f", DefaultEase, state.Ease)
+	}
+
+	state = s.Next(state, Good, now.AddDate(0, 0, 2))
+	if state.Interval != 6 {
+		t.Errorf("Expected second Good review to give 6 days, got %d", state.Interval)
+	}
+
+	state = s.Next(state, Good, now.AddDate(0, 0, 8))
+	if state.Interval != 15 {
+		t.Errorf("Expected third Good review to give 15 days, got %d", state.Interval)
+	}
+
+	state = s.Next(state, Again, now.AddDate(0, 0, 23))
+	if state.Repetitions != 0 || state.Lapses != 1 || state.Interval != 1 {
+		t.Errorf("Expected Again to reset repetitions and record a lapse, got %+v", state)
+	}
+	if state.Ease >= DefaultEase {
+		t.Errorf("Expected Again to lower the ease, got %.2f", state.Ease)
+	}
+}
+
+func TestSM2EaseFloor(t *testing.T) {
+	s := NewSM2()
+	state := State{Ease: MinEase}
+	for range 5 {
+		state = s.Next(state, Again, time.Now())
+	}
+	if state.Ease != MinEase {
+		t.Errorf("Expected ease to stop at %.2f, got %.2f", MinEase, state.Ease)
+	}
+}
+
+func TestFSRSNext(t *testing.T) {
+	f := NewFSRS()
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	state := f.Next(State{}, Good, now)
+	if state.Stability != DefaultFSRSWeights[2] {
+		t.Errorf("Expected initial stability %.4f, got %.4f", DefaultFSRSWeights[2], state.Stability)
+	}
+	if state.Difficulty < 1 || state.Difficulty > 10 {
+		t.Errorf("Difficulty out of range: %.2f", state.Difficulty)
+	}
+
+	due := now.AddDate(0, 0, state.Interval)
+	reviewed := f.Next(state, Good, due)
+	if reviewed.Stability <= state.Stability {
+		t.Errorf("Expected stability to grow after Good, got %.2f -> %.2f", state.Stability, reviewed.Stability)
+	}
+	if reviewed.Interval <= state.Interval {
+		t.Errorf("Expected interval to grow after Good, got %d -> %d", state.Interval, reviewed.Interval)
+	}
+
+	forgotten := f.Next(reviewed, Again, due.AddDate(0, 0, reviewed.Interval))
+	if forgotten.Stability >= reviewed.Stability {
+		t.Errorf("Expected stability to drop after Again, got %.2f -> %.2f", reviewed.Stability, forgotten.Stability)
+	}
+	if forgotten.Lapses != 1 || forgotten.Interval != 1 {
+		t.Errorf("Expected Again to record a lapse with a 1 day interval, got %+v", forgotten)
+	}
+}
+
+func TestFSRSDifficulty(t *testing.T) {
+	f := NewFSRS()
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	// Reference values of FSRS-4.5 with the default weights: a card graded Good
+	// starts at D0(3) = w4, and difficulty reverts towards D0(4) = w4 - w5
+	tests := []struct {
+		grade Grade
+		want  float64
+	}{
+		{Again, 6.8630},
+		{Hard, 5.9934},
+		{Good, 5.1237},
+		{Easy, 4.2540},
+	}
+	state := f.Next(State{}, Good, now)
+	if math.Abs(state.Difficulty-5.1618) > 1e-4 {
+		t.Fatalf("Expected initial difficulty 5.1618, got %.4f", state.Difficulty)
+	}
+	for _, tt := range tests {
+		got := f.Next(state, tt.grade, now.AddDate(0, 0, state.Interval)).Difficulty
+		if math.Abs(got-tt.want) > 1e-4 {
+			t.Errorf("Difficulty after %s = %.4f, expected %.4f", tt.grade, got, tt.want)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.Local)
+	fc := store.Flashcard{ID: 1}
+
+	state := NewSM2().Next(StateOf(fc), Easy, now)
+	Apply(&fc, state, now)
+
+	if fc.IntervalDays != 4 || fc.Repetitions != 1 {
+		t.Errorf("Expected Easy on a new card to give 4 days, got %+v", fc)
+	}
+	if !fc.LastReviewedAt.Equal(now) {
+		t.Errorf("Expected LastReviewedAt %v, got %v", now, fc.LastReviewedAt)
+	}
+	if expected := store.DueAfter(now, 4); !fc.DueAt.Equal(expected) {
+		t.Errorf("Expected DueAt %v, got %v", expected, fc.DueAt)
+	}
+	if StateOf(fc).Interval != state.Interval {
+		t.Error("StateOf should read back the applied state")
+	}
+}
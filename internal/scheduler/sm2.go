@@ -0,0 +1,82 @@
+package scheduler
+
+import (
+	"math"
+	"time"
+)
+
+// SM-2 defaults as described by the original SuperMemo algorithm
+const (
+	DefaultEase = 2.5 // Ease factor assigned to new cards
+	MinEase     = 1.3 // Lowest ease factor a card can reach
+)
+
+// SM2 implements the SuperMemo-2 algorithm with Anki-style Hard and Easy modifiers
+type SM2 struct {
+	HardFactor float64 // Multiplier applied to the previous interval on Hard
+	EasyBonus  float64 // Extra multiplier applied on top of the Good interval on Easy
+}
+
+// NewSM2 returns an SM-2 scheduler with the usual modifiers
+func NewSM2() *SM2 {
+	return &SM2{HardFactor: 1.2, EasyBonus: 1.3}
+}
+
+// Name returns the identifier of the algorithm
+func (s *SM2) Name() string {
+	return NameSM2
+}
+
+// Next returns the state after reviewing a card with the given grade
+func (s *SM2) Next(state State, grade Grade, now time.Time) State {
+	next := state
+	next.LastReview = now
+
+	ease := state.Ease
+	if ease < MinEase {
+		ease = DefaultEase
+	}
+	next.Ease = math.Max(MinEase, ease+easeDelta(grade))
+
+	if grade == Again {
+		if state.Repetitions > 0 || !state.LastReview.IsZero() {
+			next.Lapses++
+		}
+		next.Repetitions = 0
+		next.Interval = 1
+		return next
+	}
+
+	var hard, good, easy int
+	switch state.Repetitions {
+	case 0:
+		hard, good, easy = 1, 2, 4
+	case 1:
+		hard, good = 3, 6
+		easy = int(math.Round(float64(good) * s.EasyBonus))
+	default:
+		prev := float64(max(state.Interval, 1))
+		hard = int(math.Round(prev * s.HardFactor))
+		good = int(math.Round(prev * next.Ease))
+		easy = int(math.Round(prev * next.Ease * s.EasyBonus))
+	}
+	hard, good, easy = orderIntervals(hard, good, easy)
+
+	next.Repetitions = state.Repetitions + 1
+	switch grade {
+	case Hard:
+		next.Interval = hard
+	case Easy:
+		next.Interval = easy
+	default:
+		next.Interval = good
+	}
+	return next
+}
+
+// easeDelta maps a grade onto the SM-2 ease factor adjustment, using the
+// quality scores 2 (Again) to 5 (Easy) of the original formula
+func easeDelta(grade Grade) float64 {
+	q := float64(grade) + 1
+	return 0.1 - (5-q)*(0.08+(5-q)*0.02)
+}
@@ -0,0 +1,170 @@
+// Package stats summarizes flashcards and their review history into
+// collection, retention and workload statistics
+package stats
+
+import (
+	"sort"
+	"time"
+
+	"catv/internal/store"
+)
+
+// dayFormat is the layout used for day keys in the summary
+const dayFormat = "2006-01-02"
+
+// againGrade is the grade recorded when a flashcard was forgotten
+const againGrade = 1
+
+// ForecastDays is how many days ahead the due forecast covers
+const ForecastDays = 7
+
+// FileCount is the number of flashcards generated from a source file
+type FileCount struct {
+	File  string `json:"file"`
+	Cards int    `json:"cards"`
+}
+
+// DayCount is a number of flashcards for a calendar day (YYYY-MM-DD, local time)
+type DayCount struct {
+	Date  string `json:"date"`
+	Count int    `json:"count"`
+}
+
+// Summary is a snapshot of the collection and review activity
+type Summary struct {
+	GeneratedAt   time.Time   `json:"generated_at"`
+	TotalCards    int         `json:"total_cards"`
+	NewCards      int         `json:"new_cards"`      // Cards that were never reviewed
+	DueToday      int         `json:"due_today"`      // Cards due before the end of today, including overdue ones
+	DueThisWeek   int         `json:"due_this_week"`  // Cards due within the next 7 days, including today
+	Files         []FileCount `json:"files"`          // Cards per source file, largest first
+	TotalReviews  int         `json:"total_reviews"`  // Reviews ever logged, not counting relearning steps
+	Accuracy      float64     `json:"accuracy"`       // Share of reviews not graded Again (0-1)
+	CurrentStreak int         `json:"current_streak"` // Consecutive days with at least one review
+	LongestStreak int         `json:"longest_streak"` // Longest run of consecutive review days
+	ReviewsPerDay []DayCount  `json:"reviews_per_day"`
+	Forecast      []DayCount  `json:"forecast"` // Cards coming due on each of the next ForecastDays days
+}
+
+// Compute builds a summary at now, including per-day review counts for the
+// last historyDays days
+// Reviews logged for relearning steps are left out, so a forgotten card counts
+// once however many steps it took to learn it again
+func Compute(cards []store.Flashcard, reviews []store.Review, now time.Time, historyDays int) Summary {
+	today := startOfDay(now)
+	s := Summary{
+		GeneratedAt:   now,
+		TotalCards:    len(cards),
+		Files:         countFiles(cards),
+		ReviewsPerDay: make([]DayCount, historyDays),
+		Forecast:      make([]DayCount, ForecastDays),
+	}
+
+	// Forecast buckets; anything overdue counts towards today
+	for i := range s.Forecast {
+		s.Forecast[i].Date = today.AddDate(0, 0, i).Format(dayFormat)
+	}
+	for _, fc := range cards {
+		if fc.IsNew() {
+			s.NewCards++
+		}
+		day := fc.DaysUntilDue(now)
+		if day < 0 {
+			day = 0
+		}
+		if day < ForecastDays {
+			s.Forecast[day].Count++
+			s.DueThisWeek++
+		}
+		if day == 0 {
+			s.DueToday++
+		}
+	}
+
+	// Review history
+	perDay := make(map[string]int)
+	correct := 0
+	for _, r := range reviews {
+		if r.Relearning {
+			continue
+		}
+		s.TotalReviews++
+		perDay[r.ReviewedAt.In(now.Location()).Format(dayFormat)]++
+		if r.Grade != againGrade {
+			correct++
+		}
+	}
+	if s.TotalReviews > 0 {
+		s.Accuracy = float64(correct) / float64(s.TotalReviews)
+	}
+	for i := range s.ReviewsPerDay {
+		date := today.AddDate(0, 0, i-historyDays+1).Format(dayFormat)
+		s.ReviewsPerDay[i] = DayCount{Date: date, Count: perDay[date]}
+	}
+	s.CurrentStreak, s.LongestStreak = streaks(perDay, today)
+
+	return s
+}
+
+// countFiles returns the number of cards per source file, largest first
+func countFiles(cards []store.Flashcard) []FileCount {
+	counts := make(map[string]int)
+	for _, fc := range cards {
+		counts[fc.File]++
+	}
+	files := make([]FileCount, 0, len(counts))
+	for file, n := range counts {
+		files = append(files, FileCount{File: file, Cards: n})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].Cards != files[j].Cards {
+			return files[i].Cards > files[j].Cards
+		}
+		return files[i].File < files[j].File
+	})
+	return files
+}
+
+// streaks returns the current and longest runs of consecutive review days
+// The current streak is kept alive until today ends, so it counts back from
+// yesterday when nothing has been reviewed yet today
+func streaks(perDay map[string]int, today time.Time) (current, longest int) {
+	if len(perDay) == 0 {
+		return 0, 0
+	}
+
+	day := today
+	if perDay[day.Format(dayFormat)] == 0 {
+		day = day.AddDate(0, 0, -1)
+	}
+	for perDay[day.Format(dayFormat)] > 0 {
+		current++
+		day = day.AddDate(0, 0, -1)
+	}
+
+	dates := make([]time.Time, 0, len(perDay))
+	for date := range perDay {
+		t, err := time.ParseInLocation(dayFormat, date, today.Location())
+		if err == nil {
+			dates = append(dates, t)
+		}
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+
+	run := 0
+	for i, d := range dates {
+		if i > 0 && startOfDay(dates[i-1].AddDate(0, 0, 1)).Equal(d) {
+			run++
+		} else {
+			run = 1
+		}
+		longest = max(longest, run)
+	}
+	return current, longest
+}
+
+// startOfDay truncates t to midnight in its own location
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,105 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"catv/internal/store"
+)
+
+func TestCompute(t *testing.T) {
+	now := time.Date(2025, 3, 10, 15, 0, 0, 0, time.Local)
+	cards := []store.Flashcard{
+		{ID: 1, File: "/notes/a.md"}, // new, due now
+		{ID: 2, File: "/notes/a.md", DueAt: now.AddDate(0, 0, -2), LastReviewedAt: now.AddDate(0, 0, -5)}, // overdue
+		{ID: 3, File: "/notes/b.md", DueAt: store.DueAfter(now, 3), LastReviewedAt: now},                  // later this week
+		{ID: 4, File: "/notes/b.md", DueAt: store.DueAfter(now, 30), LastReviewedAt: now},                 // next month
+		{ID: 5, File: "/notes/a.md", DueAt: store.DueAfter(now, 1), LastReviewedAt: now},                  // tomorrow
+		{ID: 6, File: "/notes/c.md", DueAt: store.DueAfter(now, 30), Repetitions: 2},                      // imported with its schedule
+	}
+	reviews := []store.Review{
+		{FlashcardID: 2, ReviewedAt: now.AddDate(0, 0, -5), Grade: 3},
+		{FlashcardID: 3, ReviewedAt: now.AddDate(0, 0, -1), Grade: 1},
+		// Relearning steps leave accuracy and review counts alone
+		{FlashcardID: 3, ReviewedAt: now.AddDate(0, 0, -1), Grade: 1, Relearning: true},
+		{FlashcardID: 3, ReviewedAt: now.AddDate(0, 0, -1), Grade: 3, Relearning: true},
+		{FlashcardID: 3, ReviewedAt: now, Grade: 3},
+		{FlashcardID: 4, ReviewedAt: now, Grade: 4},
+	}
+
+	s := Compute(cards, reviews, now, 7)
+
+	if s.TotalCards != 6 || s.NewCards != 1 {
+		t.Errorf("Expected 6 cards with 1 new, got %d/%d", s.TotalCards, s.NewCards)
+	}
+	if s.DueToday != 2 {
+		t.Errorf("Expected 2 cards due today, got %d", s.DueToday)
+	}
+	if s.DueThisWeek != 4 {
+		t.Errorf("Expected 4 cards due this week, got %d", s.DueThisWeek)
+	}
+	if s.Forecast[0].Count != 2 || s.Forecast[1].Count != 1 || s.Forecast[3].Count != 1 {
+		t.Errorf("Unexpected forecast: %+v", s.Forecast)
+	}
+	if len(s.Files) != 3 || s.Files[0] != (FileCount{File: "/notes/a.md", Cards: 3}) {
+		t.Errorf("Unexpected files: %+v", s.Files)
+	}
+	if s.TotalReviews != 4 || s.Accuracy != 0.75 {
+		t.Errorf("Expected 4 reviews at 75%% accuracy, got %d at %.2f", s.TotalReviews, s.Accuracy)
+	}
+	if len(s.ReviewsPerDay) != 7 {
+		t.Fatalf("Expected 7 days of history, got %d", len(s.ReviewsPerDay))
+	}
+	if last := s.ReviewsPerDay[6]; last.Date != "2025-03-10" || last.Count != 2 {
+		t.Errorf("Expected 2 reviews today, got %+v", last)
+	}
+	if yesterday := s.ReviewsPerDay[5]; yesterday.Count != 1 {
+		t.Errorf("Expected 1 review yesterday, got %+v", yesterday)
+	}
+	if first := s.ReviewsPerDay[0]; first.Date != "2025-03-04" {
+		t.Errorf("Expected history to start on 2025-03-04, got %s", first.Date)
+	}
+	if s.CurrentStreak != 2 || s.LongestStreak != 2 {
+		t.Errorf("Expected current and longest streak of 2, got %d/%d", s.CurrentStreak, s.LongestStreak)
+	}
+}
+
+func TestStreaks(t *testing.T) {
+	today := time.Date(2025, 3, 10, 0, 0, 0, 0, time.Local)
+	tests := []struct {
+		name            string
+		days            []string
+		current, longer int
+	}{
+		{name: "no reviews", days: nil, current: 0, longer: 0},
+		{name: "reviewed today", days: []string{"2025-03-08", "2025-03-09", "2025-03-10"}, current: 3, longer: 3},
+		{name: "not yet today", days: []string{"2025-03-08", "2025-03-09"}, current: 2, longer: 2},
+		{name: "broken streak", days: []string{"2025-03-01", "2025-03-02", "2025-03-03", "2025-03-08"}, current: 0, longer: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			perDay := make(map[string]int)
+			for _, d := range tt.days {
+				perDay[d] = 1
+			}
+			current, longest := streaks(perDay, today)
+			if current != tt.current || longest != tt.longer {
+				t.Errorf("streaks() = %d, %d, expected %d, %d", current, longest, tt.current, tt.longer)
+			}
+		})
+	}
+}
+
+func TestCompute_Empty(t *testing.T) {
+	s := Compute(nil, nil, time.Now(), 14)
+	if s.TotalCards != 0 || s.Accuracy != 0 || s.CurrentStreak != 0 {
+		t.Errorf("Expected an empty summary, got %+v", s)
+	}
+	if len(s.ReviewsPerDay) != 14 || len(s.Forecast) != ForecastDays {
+		t.Errorf("Expected %d history days and %d forecast days, got %d/%d", 14, ForecastDays, len(s.ReviewsPerDay), len(s.Forecast))
+	}
+	if s.Files == nil {
+		t.Error("Files should be an empty list, not nil, so JSON output is []")
+	}
+}
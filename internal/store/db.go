@@ -4,18 +4,40 @@ package store
 import (
 	"database/sql"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
 // Store manages the database connection and operations for flashcards
 type Store struct {
-	DB *sql.DB // SQLite database connection
+	DB  *sql.DB          // SQLite database connection
+	now func() time.Time // Clock used to decide which flashcards are due
 }
 
-// NewStore creates a new Store instance with the specified database file
-// It automatically creates the flashcards table if it doesn't exist
-func NewStore(dbName string) (*Store, error) {
+// timeFormat is the layout used for DATETIME columns; it matches SQLite's
+// CURRENT_TIMESTAMP and datetime() output so values compare correctly as text
+const timeFormat = "2006-01-02 15:04:05"
+
+// flashcardColumns lists the columns selected for every Flashcard query
+// Tags are aggregated into a single comma separated column
+const flashcardColumns = "id, file, heading, question, answer, card_type, cloze_index, due_at, last_reviewed_at, " +
+	"ease, repetitions, lapses, stability, difficulty, interval_days, archived_at, " +
+	"COALESCE((SELECT name FROM decks WHERE decks.id = flashcards.deck_id), '') AS deck, " +
+	"(SELECT GROUP_CONCAT(tags.name, ',') FROM flashcard_tags JOIN tags ON tags.id = flashcard_tags.tag_id " +
+	"WHERE flashcard_tags.flashcard_id = flashcards.id) AS tags"
+
+// activeFlashcards restricts a query to flashcards that have not been archived
+const activeFlashcards = "archived_at IS NULL"
+
+// defaultEase is the SM-2 ease factor given to flashcards that have never been reviewed
+const defaultEase = 2.5
+
+// OpenStore opens the database file without changing its schema
+// Most callers want NewStore, which also applies pending migrations
+func OpenStore(dbName string) (*Store, error) {
 	db, err := sql.Open("sqlite3", dbName)
 	if err != nil {
 		return nil, err
@@ -25,126 +47,87 @@ func NewStore(dbName string) (*Store, error) {
 	db.SetMaxOpenConns(25)
 	db.SetMaxIdleConns(5)
 
-	// Create flashcards table with proper schema
-	createTable := `CREATE TABLE IF NOT EXISTS flashcards (
-			  id INTEGER PRIMARY KEY AUTOINCREMENT,
-			  file TEXT NOT NULL,
-			  question TEXT NOT NULL,
-			  answer TEXT NOT NULL,
-			  revisitin INTEGER DEFAULT 0,
-			  created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			  updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
-		  );`
-	_, err = db.Exec(createTable)
+	return &Store{DB: db, now: time.Now}, nil
+}
+
+// NewStore creates a new Store instance with the specified database file
+// It automatically creates or upgrades the schema by applying pending migrations
+func NewStore(dbName string) (*Store, error) {
+	s, err := OpenStore(dbName)
 	if err != nil {
 		return nil, err
 	}
-
-	// Create indexes for frequently queried columns to improve performance
-	indexes := []string{
-		`CREATE INDEX IF NOT EXISTS idx_flashcards_revisitin ON flashcards(revisitin)`,
-		`CREATE INDEX IF NOT EXISTS idx_flashcards_file ON flashcards(file)`,
-		`CREATE INDEX IF NOT EXISTS idx_flashcards_file_revisitin ON flashcards(file, revisitin)`,
-	}
-	for _, idx := range indexes {
-		if _, err := db.Exec(idx); err != nil {
-			return nil, fmt.Errorf("failed to create index: %w", err)
-		}
+	if _, err := s.Migrate(); err != nil {
+		s.Close()
+		return nil, err
 	}
+	return s, nil
+}
 
-	return &Store{DB: db}, nil
+// formatTime converts t to the UTC text representation stored in DATETIME columns
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeFormat)
 }
 
-// GetFlashcardsForReview returns all flashcards that are due for review
-// A flashcard is due for review when RevisitIn <= 0 or when the revisit date has passed
-func (s *Store) GetFlashcardsForReview() ([]Flashcard, error) {
-	query := `SELECT id, file, question, answer, revisitin 
-			  FROM flashcards 
-			  WHERE revisitin <= 0 
-			  ORDER BY id ASC`
-	rows, err := s.DB.Query(query)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query flashcards for review: %w", err)
+// nullableTime returns nil for the zero time so it is stored as NULL
+func nullableTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
 	}
-	defer func() {
-		_ = rows.Close()
-	}()
+	return formatTime(t)
+}
 
+// scanFlashcards reads every row selected with flashcardColumns into a slice
+func scanFlashcards(rows *sql.Rows, capacity int) ([]Flashcard, error) {
 	// Pre-allocate slice with reasonable initial capacity to reduce allocations
-	flashcards := make([]Flashcard, 0, 100)
+	flashcards := make([]Flashcard, 0, capacity)
 	for rows.Next() {
 		var fc Flashcard
-		err := rows.Scan(&fc.ID, &fc.File, &fc.Question, &fc.Answer, &fc.RevisitIn)
+		var dueAt, lastReviewedAt, archivedAt sql.NullTime
+		var tags sql.NullString
+		err := rows.Scan(&fc.ID, &fc.File, &fc.Heading, &fc.Question, &fc.Answer, &fc.CardType, &fc.ClozeIndex, &dueAt, &lastReviewedAt,
+			&fc.Ease, &fc.Repetitions, &fc.Lapses, &fc.Stability, &fc.Difficulty, &fc.IntervalDays, &archivedAt, &fc.Deck, &tags)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan flashcard: %w", err)
 		}
+		fc.DueAt = dueAt.Time
+		fc.LastReviewedAt = lastReviewedAt.Time
+		fc.ArchivedAt = archivedAt.Time
+		if tags.String != "" {
+			fc.Tags = strings.Split(tags.String, ",")
+			sort.Strings(fc.Tags)
+		}
 		flashcards = append(flashcards, fc)
 	}
 
-	if err = rows.Err(); err != nil {
+	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("error iterating flashcards: %w", err)
 	}
 
 	return flashcards, nil
 }
 
-// GetFlashcardsForReviewByFiles returns flashcards due for review filtered by specific file paths
-func (s *Store) GetFlashcardsForReviewByFiles(files []string) ([]Flashcard, error) {
-	if len(files) == 0 {
-		return []Flashcard{}, nil
-	}
-
-	// Build placeholders for IN clause
-	placeholders := ""
-	for i := range files {
-		if i > 0 {
-			placeholders += ", "
-		}
-		placeholders += "?"
-	}
-
-	// nosemgrep: go.lang.security.audit.database.string-formatted-query.string-formatted-query
-	// #nosec G201 -- This is safe: we're only using fmt.Sprintf to build placeholders (?), not user data
-	query := fmt.Sprintf(`SELECT id, file, question, answer, revisitin 
+// GetFlashcardsForReview returns all flashcards that are due for review
+// A flashcard is due for review when its due date is at or before the current time
+func (s *Store) GetFlashcardsForReview() ([]Flashcard, error) {
+	query := `SELECT ` + flashcardColumns + `
 			  FROM flashcards 
-			  WHERE revisitin <= 0 AND file IN (%s)
-			  ORDER BY id ASC`, placeholders)
-
-	// Convert files to []interface{} for Query
-	args := make([]interface{}, len(files))
-	for i, f := range files {
-		args[i] = f
-	}
-
-	rows, err := s.DB.Query(query, args...)
+			  WHERE due_at <= ? AND ` + activeFlashcards + `
+			  ORDER BY id ASC`
+	rows, err := s.DB.Query(query, formatTime(s.now()))
 	if err != nil {
-		return nil, fmt.Errorf("failed to query flashcards for review by files: %w", err)
+		return nil, fmt.Errorf("failed to query flashcards for review: %w", err)
 	}
 	defer func() {
 		_ = rows.Close()
 	}()
 
-	// Pre-allocate slice with reasonable initial capacity to reduce allocations
-	flashcards := make([]Flashcard, 0, 50)
-	for rows.Next() {
-		var fc Flashcard
-		err := rows.Scan(&fc.ID, &fc.File, &fc.Question, &fc.Answer, &fc.RevisitIn)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan flashcard: %w", err)
-		}
-		flashcards = append(flashcards, fc)
-	}
-
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating flashcards: %w", err)
-	}
-
-	return flashcards, nil
+	return scanFlashcards(rows, 100)
 }
 
 // GetUniqueFiles returns all unique file paths that have flashcards in the database
 func (s *Store) GetUniqueFiles() ([]string, error) {
-	query := `SELECT DISTINCT file FROM flashcards ORDER BY file ASC`
+	query := `SELECT DISTINCT file FROM flashcards WHERE ` + activeFlashcards + ` ORDER BY file ASC`
 	rows, err := s.DB.Query(query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query unique files: %w", err)
@@ -171,42 +154,107 @@ func (s *Store) GetUniqueFiles() ([]string, error) {
 	return files, nil
 }
 
-// GetAllFlashcards returns all flashcards ordered by revisitin ascending
+// GetAllFlashcards returns all active flashcards ordered by due date ascending
 func (s *Store) GetAllFlashcards() ([]Flashcard, error) {
-	rows, err := s.DB.Query("SELECT id, file, question, answer, revisitin FROM flashcards ORDER BY revisitin ASC, id ASC")
+	rows, err := s.DB.Query("SELECT " + flashcardColumns + " FROM flashcards WHERE " + activeFlashcards + " ORDER BY due_at ASC, id ASC")
 	if err != nil {
 		return nil, err
 	}
 	defer func() {
 		_ = rows.Close()
 	}()
-	// Pre-allocate slice with reasonable initial capacity to reduce allocations
-	flashcards := make([]Flashcard, 0, 100)
-	for rows.Next() {
-		var fc Flashcard
-		if err := rows.Scan(&fc.ID, &fc.File, &fc.Question, &fc.Answer, &fc.RevisitIn); err != nil {
-			return nil, err
-		}
-		flashcards = append(flashcards, fc)
-	}
-	return flashcards, nil
+	return scanFlashcards(rows, 100)
 }
 
-// DeleteFlashcard deletes a flashcard by id
+// DeleteFlashcard deletes a flashcard, its tags and its review history by id
 func (s *Store) DeleteFlashcard(id int) error {
-	_, err := s.DB.Exec("DELETE FROM flashcards WHERE id=?", id)
-	return err
+	return s.inTx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec("DELETE FROM reviews WHERE flashcard_id=?", id); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("DELETE FROM flashcard_tags WHERE flashcard_id=?", id); err != nil {
+			return err
+		}
+		_, err := tx.Exec("DELETE FROM flashcards WHERE id=?", id)
+		return err
+	})
 }
 
 // UpdateFlashcardFull updates all editable fields of a flashcard
 func (s *Store) UpdateFlashcardFull(fc Flashcard) error {
-	_, err := s.DB.Exec("UPDATE flashcards SET file=?, question=?, answer=?, revisitin=? WHERE id=?", fc.File, fc.Question, fc.Answer, fc.RevisitIn, fc.ID)
+	_, err := s.DB.Exec("UPDATE flashcards SET file=?, question=?, answer=?, due_at=?, updated_at=CURRENT_TIMESTAMP WHERE id=?",
+		fc.File, fc.Question, fc.Answer, s.dueAt(fc), fc.ID)
 	return err
 }
 
-// UpdateFlashcard updates a flashcard's revisitin date
+// UpdateFlashcard updates a flashcard's due date, last review time and scheduler state
 func (s *Store) UpdateFlashcard(fc Flashcard) error {
-	_, err := s.DB.Exec("UPDATE flashcards SET revisitin=? WHERE id=?", fc.RevisitIn, fc.ID)
+	return s.updateFlashcard(s.DB, fc)
+}
+
+// RecordReview saves a graded flashcard and logs the review in a single
+// transaction, counting it towards the daily limits of the day it was made
+// It returns the id of the logged review
+func (s *Store) RecordReview(fc Flashcard, r Review) (int, error) {
+	if r.ReviewedAt.IsZero() {
+		r.ReviewedAt = s.now()
+	}
+	var id int
+	err := s.inTx(func(tx *sql.Tx) error {
+		isNew, err := wasNew(tx, fc.ID)
+		if err != nil {
+			return err
+		}
+		if err := s.updateFlashcard(tx, fc); err != nil {
+			return err
+		}
+		r.FlashcardID = fc.ID
+		if id, err = s.insertReview(tx, r); err != nil {
+			return err
+		}
+		return countStudied(tx, r.ReviewedAt, isNew)
+	})
+	return id, err
+}
+
+// UndoReview reverts a review logged by RecordReview: the flashcard gets the
+// scheduling it had before, and the review is deleted and no longer counts
+// towards the daily limits
+func (s *Store) UndoReview(previous Flashcard, reviewID int) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		rows, err := tx.Query("SELECT reviewed_at FROM reviews WHERE id = ? AND flashcard_id = ?", reviewID, previous.ID)
+		if err != nil {
+			return fmt.Errorf("failed to query review: %w", err)
+		}
+		var reviewedAt time.Time
+		found := rows.Next()
+		if found {
+			err = rows.Scan(&reviewedAt)
+		}
+		_ = rows.Close()
+		if err != nil {
+			return fmt.Errorf("failed to scan review: %w", err)
+		}
+		if !found {
+			return fmt.Errorf("review %d of flashcard %d not found", reviewID, previous.ID)
+		}
+
+		if err := s.updateFlashcard(tx, previous); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("DELETE FROM reviews WHERE id = ?", reviewID); err != nil {
+			return fmt.Errorf("failed to delete review: %w", err)
+		}
+		return uncountStudied(tx, reviewedAt, previous.IsNew())
+	})
+}
+
+func (s *Store) updateFlashcard(db queryExecer, fc Flashcard) error {
+	_, err := db.Exec(`UPDATE flashcards SET due_at=?, last_reviewed_at=?,
+			  ease=?, repetitions=?, lapses=?, stability=?, difficulty=?, interval_days=?,
+			  updated_at=CURRENT_TIMESTAMP WHERE id=?`,
+		s.dueAt(fc), nullableTime(fc.LastReviewedAt),
+		easeOrDefault(fc.Ease), fc.Repetitions, fc.Lapses, fc.Stability, fc.Difficulty, fc.IntervalDays, fc.ID)
 	return err
 }
 
@@ -221,10 +269,85 @@ func (s *Store) IsFileProcessed(filePath string) (bool, error) {
 	return count > 0, nil
 }
 
-// InsertFlashcard inserts a new flashcard into the database
+// InsertFlashcard inserts a new flashcard and its tags into the database
+// A flashcard without a due date is due immediately, and one without a deck
+// goes to the deck named after its source file
 func (s *Store) InsertFlashcard(fc Flashcard) error {
-	_, err := s.DB.Exec("INSERT INTO flashcards (file, question, answer, revisitin) VALUES (?, ?, ?, ?)", fc.File, fc.Question, fc.Answer, fc.RevisitIn)
-	return err
+	return s.inTx(func(tx *sql.Tx) error {
+		return s.insertFlashcard(tx, fc)
+	})
+}
+
+// InsertFlashcards inserts flashcards and their tags in a single transaction,
+// so either all of them are saved or none
+func (s *Store) InsertFlashcards(flashcards []Flashcard) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		for _, fc := range flashcards {
+			if err := s.insertFlashcard(tx, fc); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *Store) insertFlashcard(db queryExecer, fc Flashcard) error {
+	deckID, err := ensureDeck(db, deckOrDefault(fc))
+	if err != nil {
+		return err
+	}
+	res, err := db.Exec(`INSERT INTO flashcards (file, heading, question, answer, card_type, cloze_index, deck_id, due_at, last_reviewed_at,
+			  ease, repetitions, lapses, stability, difficulty, interval_days)
+			  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		fc.File, fc.Heading, fc.Question, fc.Answer, cardTypeOrDefault(fc.CardType), fc.ClozeIndex, deckID, s.dueAt(fc), nullableTime(fc.LastReviewedAt),
+		easeOrDefault(fc.Ease), fc.Repetitions, fc.Lapses, fc.Stability, fc.Difficulty, fc.IntervalDays)
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	return addTags(db, int(id), fc.Tags)
+}
+
+// cardTypeOrDefault treats flashcards without a type as question/answer cards
+func cardTypeOrDefault(cardType string) string {
+	if cardType == "" {
+		return CardBasic
+	}
+	return cardType
+}
+
+// easeOrDefault returns the default ease factor for flashcards that have none yet
+func easeOrDefault(ease float64) float64 {
+	if ease == 0 {
+		return defaultEase
+	}
+	return ease
+}
+
+// dueAt returns the stored representation of a flashcard's due date, defaulting to now
+func (s *Store) dueAt(fc Flashcard) string {
+	if fc.DueAt.IsZero() {
+		return formatTime(s.now())
+	}
+	return formatTime(fc.DueAt)
+}
+
+// inTx runs fn inside a transaction, committing only if it succeeds
+func (s *Store) inTx(fn func(tx *sql.Tx) error) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 // Close closes the database connection
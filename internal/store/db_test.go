@@ -1,9 +1,11 @@
 package store
 
 import (
+	"database/sql"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestNewStore(t *testing.T) {
@@ -28,10 +30,9 @@ func TestInsertFlashcard(t *testing.T) {
 	defer store.Close()
 
 	flashcard := Flashcard{
-		File:      "/test/file.md",
-		Question:  "What is 2+2?",
-		Answer:    "4",
-		RevisitIn: 0,
+		File:     "/test/file.md",
+		Question: "What is 2+2?",
+		Answer:   "4",
 	}
 
 	err := store.InsertFlashcard(flashcard)
@@ -52,6 +53,52 @@ func TestInsertFlashcard(t *testing.T) {
 	if cards[0].Question != flashcard.Question {
 		t.Errorf("Expected question '%s', got '%s'", flashcard.Question, cards[0].Question)
 	}
+	if cards[0].CardType != CardBasic {
+		t.Errorf("Expected card type '%s', got '%s'", CardBasic, cards[0].CardType)
+	}
+}
+
+func TestInsertFlashcard_Cloze(t *testing.T) {
+	store := setupTestDB(t)
+	defer store.Close()
+
+	flashcard := Flashcard{File: "/test/file.md", Question: "Go has {{c1::goroutines}} and {{c2::channels}}", CardType: CardCloze, ClozeIndex: 2}
+	if err := store.InsertFlashcard(flashcard); err != nil {
+		t.Fatalf("InsertFlashcard() error = %v", err)
+	}
+
+	cards, err := store.GetAllFlashcards()
+	if err != nil {
+		t.Fatalf("GetAllFlashcards() error = %v", err)
+	}
+	if len(cards) != 1 || !cards[0].IsCloze() || cards[0].ClozeIndex != 2 {
+		t.Errorf("Expected a cloze card for deletion 2, got %+v", cards)
+	}
+}
+
+func TestInsertFlashcards(t *testing.T) {
+	store := setupTestDB(t)
+	defer store.Close()
+
+	due := time.Date(2030, 1, 2, 0, 0, 0, 0, time.Local)
+	flashcards := []Flashcard{
+		{File: "anki:Spanish", Question: "perro", Answer: "dog", DueAt: due, Ease: 2.1, Repetitions: 4, IntervalDays: 12, Tags: []string{"Animals"}},
+		{File: "anki:Spanish", Question: "gato", Answer: "cat"},
+	}
+	if err := store.InsertFlashcards(flashcards); err != nil {
+		t.Fatalf("InsertFlashcards() error = %v", err)
+	}
+
+	cards, err := store.GetFlashcardsByFile("anki:Spanish")
+	if err != nil {
+		t.Fatalf("GetFlashcardsByFile() error = %v", err)
+	}
+	if len(cards) != 2 {
+		t.Fatalf("Expected 2 flashcards, got %d", len(cards))
+	}
+	if got := cards[0]; !got.DueAt.Equal(due) || got.Ease != 2.1 || got.Repetitions != 4 || got.IntervalDays != 12 || len(got.Tags) != 1 || got.Tags[0] != "animals" {
+		t.Errorf("Expected the scheduling and tags to be kept, got %+v", got)
+	}
 }
 
 func TestGetFlashcardsForReview(t *testing.T) {
@@ -60,9 +107,9 @@ func TestGetFlashcardsForReview(t *testing.T) {
 
 	// Insert test flashcards
 	flashcards := []Flashcard{
-		{File: "/test/1.md", Question: "Q1", Answer: "A1", RevisitIn: -1}, // Due
-		{File: "/test/2.md", Question: "Q2", Answer: "A2", RevisitIn: 5},  // Not due
-		{File: "/test/3.md", Question: "Q3", Answer: "A3", RevisitIn: 0},  // Due
+		{File: "/test/1.md", Question: "Q1", Answer: "A1", DueAt: time.Now().AddDate(0, 0, -1)}, // Due
+		{File: "/test/2.md", Question: "Q2", Answer: "A2", DueAt: time.Now().AddDate(0, 0, 5)},  // Not due
+		{File: "/test/3.md", Question: "Q3", Answer: "A3"},                                      // Due
 	}
 
 	for _, fc := range flashcards {
@@ -88,7 +135,7 @@ func TestUpdateFlashcard(t *testing.T) {
 	defer store.Close()
 
 	// Insert a flashcard
-	fc := Flashcard{File: "/test/1.md", Question: "Q1", Answer: "A1", RevisitIn: 0}
+	fc := Flashcard{File: "/test/1.md", Question: "Q1", Answer: "A1"}
 	err := store.InsertFlashcard(fc)
 	if err != nil {
 		t.Fatalf("InsertFlashcard() error = %v", err)
@@ -105,7 +152,15 @@ func TestUpdateFlashcard(t *testing.T) {
 	}
 
 	// Update the flashcard
-	cards[0].RevisitIn = 5
+	now := time.Now()
+	cards[0].DueAt = DueAfter(now, 5)
+	cards[0].LastReviewedAt = now
+	cards[0].Ease = 2.36
+	cards[0].Repetitions = 3
+	cards[0].Lapses = 1
+	cards[0].Stability = 12.5
+	cards[0].Difficulty = 4.2
+	cards[0].IntervalDays = 5
 	err = store.UpdateFlashcard(cards[0])
 	if err != nil {
 		t.Errorf("UpdateFlashcard() error = %v", err)
@@ -117,8 +172,19 @@ func TestUpdateFlashcard(t *testing.T) {
 		t.Fatalf("GetAllFlashcards() error = %v", err)
 	}
 
-	if updatedCards[0].RevisitIn != 5 {
-		t.Errorf("Expected RevisitIn 5, got %d", updatedCards[0].RevisitIn)
+	if days := updatedCards[0].DaysUntilDue(now); days != 5 {
+		t.Errorf("Expected due in 5 days, got %d", days)
+	}
+	if updatedCards[0].LastReviewedAt.IsZero() {
+		t.Error("Expected LastReviewedAt to be set")
+	}
+	if updatedCards[0].IsDue(now) {
+		t.Error("Expected flashcard not to be due after update")
+	}
+	got := updatedCards[0]
+	if got.Ease != 2.36 || got.Repetitions != 3 || got.Lapses != 1 ||
+		got.Stability != 12.5 || got.Difficulty != 4.2 || got.IntervalDays != 5 {
+		t.Errorf("Scheduling state not persisted: %+v", got)
 	}
 }
 
@@ -127,7 +193,7 @@ func TestDeleteFlashcard(t *testing.T) {
 	defer store.Close()
 
 	// Insert a flashcard
-	fc := Flashcard{File: "/test/1.md", Question: "Q1", Answer: "A1", RevisitIn: 0}
+	fc := Flashcard{File: "/test/1.md", Question: "Q1", Answer: "A1"}
 	err := store.InsertFlashcard(fc)
 	if err != nil {
 		t.Fatalf("InsertFlashcard() error = %v", err)
@@ -179,7 +245,7 @@ func TestIsFileProcessed(t *testing.T) {
 	}
 
 	// Insert a flashcard from the file
-	fc := Flashcard{File: filePath, Question: "Q1", Answer: "A1", RevisitIn: 0}
+	fc := Flashcard{File: filePath, Question: "Q1", Answer: "A1"}
 	err = store.InsertFlashcard(fc)
 	if err != nil {
 		t.Fatalf("InsertFlashcard() error = %v", err)
@@ -201,7 +267,7 @@ func TestUpdateFlashcardFull(t *testing.T) {
 	defer store.Close()
 
 	// Insert a flashcard
-	fc := Flashcard{File: "/test/1.md", Question: "Q1", Answer: "A1", RevisitIn: 0}
+	fc := Flashcard{File: "/test/1.md", Question: "Q1", Answer: "A1"}
 	err := store.InsertFlashcard(fc)
 	if err != nil {
 		t.Fatalf("InsertFlashcard() error = %v", err)
@@ -221,7 +287,8 @@ func TestUpdateFlashcardFull(t *testing.T) {
 	cards[0].Question = "Q1 Updated"
 	cards[0].Answer = "A1 Updated"
 	cards[0].File = "/test/updated.md"
-	cards[0].RevisitIn = 10
+	now := time.Now()
+	cards[0].DueAt = DueAfter(now, 10)
 	err = store.UpdateFlashcardFull(cards[0])
 	if err != nil {
 		t.Errorf("UpdateFlashcardFull() error = %v", err)
@@ -242,8 +309,8 @@ func TestUpdateFlashcardFull(t *testing.T) {
 	if updatedCards[0].File != "/test/updated.md" {
 		t.Errorf("Expected File '/test/updated.md', got '%s'", updatedCards[0].File)
 	}
-	if updatedCards[0].RevisitIn != 10 {
-		t.Errorf("Expected RevisitIn 10, got %d", updatedCards[0].RevisitIn)
+	if days := updatedCards[0].DaysUntilDue(now); days != 10 {
+		t.Errorf("Expected due in 10 days, got %d", days)
 	}
 }
 
@@ -253,10 +320,10 @@ func TestGetUniqueFiles(t *testing.T) {
 
 	// Insert flashcards from different files
 	flashcards := []Flashcard{
-		{File: "/test/file1.md", Question: "Q1", Answer: "A1", RevisitIn: 0},
-		{File: "/test/file2.md", Question: "Q2", Answer: "A2", RevisitIn: 0},
-		{File: "/test/file1.md", Question: "Q3", Answer: "A3", RevisitIn: 0}, // Duplicate file
-		{File: "/test/file3.md", Question: "Q4", Answer: "A4", RevisitIn: 0},
+		{File: "/test/file1.md", Question: "Q1", Answer: "A1"},
+		{File: "/test/file2.md", Question: "Q2", Answer: "A2"},
+		{File: "/test/file1.md", Question: "Q3", Answer: "A3"}, // Duplicate file
+		{File: "/test/file3.md", Question: "Q4", Answer: "A4"},
 	}
 
 	for _, fc := range flashcards {
@@ -290,86 +357,6 @@ func TestGetUniqueFiles(t *testing.T) {
 	}
 }
 
-func TestGetFlashcardsForReviewByFiles(t *testing.T) {
-	store := setupTestDB(t)
-	defer store.Close()
-
-	// Insert flashcards from different files with different review statuses
-	flashcards := []Flashcard{
-		{File: "/test/file1.md", Question: "Q1", Answer: "A1", RevisitIn: 0},  // Due, file1
-		{File: "/test/file1.md", Question: "Q2", Answer: "A2", RevisitIn: 5},  // Not due, file1
-		{File: "/test/file2.md", Question: "Q3", Answer: "A3", RevisitIn: -1}, // Due, file2
-		{File: "/test/file2.md", Question: "Q4", Answer: "A4", RevisitIn: 0},  // Due, file2
-		{File: "/test/file3.md", Question: "Q5", Answer: "A5", RevisitIn: 0},  // Due, file3
-	}
-
-	for _, fc := range flashcards {
-		err := store.InsertFlashcard(fc)
-		if err != nil {
-			t.Fatalf("InsertFlashcard() error = %v", err)
-		}
-	}
-
-	// Test 1: Get flashcards for a single file
-	selectedFiles := []string{"/test/file1.md"}
-	cards, err := store.GetFlashcardsForReviewByFiles(selectedFiles)
-	if err != nil {
-		t.Fatalf("GetFlashcardsForReviewByFiles() error = %v", err)
-	}
-
-	if len(cards) != 1 {
-		t.Errorf("Expected 1 flashcard for file1.md, got %d", len(cards))
-	}
-
-	if len(cards) > 0 && cards[0].Question != "Q1" {
-		t.Errorf("Expected Q1, got %s", cards[0].Question)
-	}
-
-	// Test 2: Get flashcards for multiple files
-	selectedFiles = []string{"/test/file1.md", "/test/file2.md"}
-	cards, err = store.GetFlashcardsForReviewByFiles(selectedFiles)
-	if err != nil {
-		t.Fatalf("GetFlashcardsForReviewByFiles() error = %v", err)
-	}
-
-	if len(cards) != 3 {
-		t.Errorf("Expected 3 flashcards for file1.md and file2.md, got %d", len(cards))
-	}
-
-	// Test 3: Get flashcards for all files
-	selectedFiles = []string{"/test/file1.md", "/test/file2.md", "/test/file3.md"}
-	cards, err = store.GetFlashcardsForReviewByFiles(selectedFiles)
-	if err != nil {
-		t.Fatalf("GetFlashcardsForReviewByFiles() error = %v", err)
-	}
-
-	if len(cards) != 4 {
-		t.Errorf("Expected 4 flashcards for all files, got %d", len(cards))
-	}
-
-	// Test 4: Get flashcards for empty file list
-	selectedFiles = []string{}
-	cards, err = store.GetFlashcardsForReviewByFiles(selectedFiles)
-	if err != nil {
-		t.Fatalf("GetFlashcardsForReviewByFiles() error = %v", err)
-	}
-
-	if len(cards) != 0 {
-		t.Errorf("Expected 0 flashcards for empty file list, got %d", len(cards))
-	}
-
-	// Test 5: Get flashcards for non-existent file
-	selectedFiles = []string{"/test/nonexistent.md"}
-	cards, err = store.GetFlashcardsForReviewByFiles(selectedFiles)
-	if err != nil {
-		t.Fatalf("GetFlashcardsForReviewByFiles() error = %v", err)
-	}
-
-	if len(cards) != 0 {
-		t.Errorf("Expected 0 flashcards for non-existent file, got %d", len(cards))
-	}
-}
-
 // setupTestDB creates a temporary database for testing
 func setupTestDB(t *testing.T) *Store {
 	tempDir := t.TempDir()
@@ -444,3 +431,101 @@ func TestGetAllFlashcards_EmptyDatabase(t *testing.T) {
 		t.Errorf("Expected 0 flashcards in empty database, got %d", len(cards))
 	}
 }
+
+func TestGetFlashcardsForReview_UsesClock(t *testing.T) {
+	store := setupTestDB(t)
+	defer store.Close()
+
+	now := time.Now()
+	fc := Flashcard{File: "/test/1.md", Question: "Q1", Answer: "A1", DueAt: DueAfter(now, 3)}
+	if err := store.InsertFlashcard(fc); err != nil {
+		t.Fatalf("InsertFlashcard() error = %v", err)
+	}
+
+	cards, err := store.GetFlashcardsForReview()
+	if err != nil {
+		t.Fatalf("GetFlashcardsForReview() error = %v", err)
+	}
+	if len(cards) != 0 {
+		t.Errorf("Expected 0 flashcards due today, got %d", len(cards))
+	}
+
+	// Advance the clock past the due date
+	store.now = func() time.Time { return now.AddDate(0, 0, 4) }
+	cards, err = store.GetFlashcardsForReview()
+	if err != nil {
+		t.Fatalf("GetFlashcardsForReview() error = %v", err)
+	}
+	if len(cards) != 1 {
+		t.Errorf("Expected 1 flashcard due in 4 days, got %d", len(cards))
+	}
+}
+
+func TestNewStore_MigratesRevisitIn(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "legacy.db")
+
+	// Create a database using the legacy revisitin schema
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	legacy := []string{
+		`CREATE TABLE flashcards (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			file TEXT NOT NULL,
+			question TEXT NOT NULL,
+			answer TEXT NOT NULL,
+			revisitin INTEGER DEFAULT 0,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX idx_flashcards_revisitin ON flashcards(revisitin)`,
+		`CREATE INDEX idx_flashcards_file_revisitin ON flashcards(file, revisitin)`,
+		`INSERT INTO flashcards (file, question, answer, revisitin, updated_at) VALUES ('/a.md', 'Q1', 'A1', 0, '2024-01-10 08:00:00')`,
+		`INSERT INTO flashcards (file, question, answer, revisitin, updated_at) VALUES ('/a.md', 'Q2', 'A2', 7, '2024-01-10 08:00:00')`,
+	}
+	for _, stmt := range legacy {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("legacy setup error = %v", err)
+		}
+	}
+	_ = db.Close()
+
+	store, err := NewStore(dbPath)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	defer store.Close()
+
+	cards, err := store.GetAllFlashcards()
+	if err != nil {
+		t.Fatalf("GetAllFlashcards() error = %v", err)
+	}
+	if len(cards) != 2 {
+		t.Fatalf("Expected 2 flashcards, got %d", len(cards))
+	}
+
+	want := map[string]time.Time{
+		"Q1": time.Date(2024, 1, 10, 8, 0, 0, 0, time.UTC),
+		"Q2": time.Date(2024, 1, 17, 8, 0, 0, 0, time.UTC),
+	}
+	for _, fc := range cards {
+		if !fc.DueAt.Equal(want[fc.Question]) {
+			t.Errorf("%s: expected due %v, got %v", fc.Question, want[fc.Question], fc.DueAt)
+		}
+	}
+	if !cards[0].LastReviewedAt.IsZero() {
+		t.Error("Expected unscheduled card to have no last review time")
+	}
+	if cards[1].LastReviewedAt.IsZero() {
+		t.Error("Expected scheduled card to keep its last review time")
+	}
+
+	hasRevisitIn, err := hasColumn(store.DB, "flashcards", "revisitin")
+	if err != nil {
+		t.Fatalf("hasColumn() error = %v", err)
+	}
+	if hasRevisitIn {
+		t.Error("Expected revisitin column to be dropped")
+	}
+}
@@ -0,0 +1,188 @@
+package store
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultDeck holds flashcards whose source gives no deck name
+const DefaultDeck = "Default"
+
+// GroupCount is the number of active flashcards in a deck or with a tag
+type GroupCount struct {
+	Name  string // Deck or tag name
+	New   int    // Due flashcards that were never reviewed
+	Due   int    // Due flashcards that were reviewed before
+	Total int    // All active flashcards
+}
+
+// newFlashcard matches flashcards that were never reviewed
+const newFlashcard = `(flashcards.last_reviewed_at IS NULL AND flashcards.repetitions = 0)`
+
+// groupCountColumns counts the flashcards of a group by queue
+const groupCountColumns = `COUNT(*),
+			  COALESCE(SUM(flashcards.due_at <= ? AND ` + newFlashcard + `), 0),
+			  COALESCE(SUM(flashcards.due_at <= ? AND NOT ` + newFlashcard + `), 0)`
+
+// DeckName returns the deck for flashcards from a source that sets none: the
+// name after the scheme of origins such as "anki:Spanish", or the file name
+// without its extension
+func DeckName(file string) string {
+	if !strings.ContainsAny(file, `/\`) {
+		if _, name, ok := strings.Cut(file, ":"); ok && name != "" {
+			return name
+		}
+	}
+	name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	if name == "" || name == "." || name == string(filepath.Separator) {
+		return DefaultDeck
+	}
+	return name
+}
+
+// deckOrDefault returns the deck a flashcard is saved in
+func deckOrDefault(fc Flashcard) string {
+	if name := strings.TrimSpace(fc.Deck); name != "" {
+		return name
+	}
+	return DeckName(fc.File)
+}
+
+// ensureDeck returns the id of the deck with the given name, creating it if needed
+func ensureDeck(db queryExecer, name string) (int64, error) {
+	if _, err := db.Exec("INSERT OR IGNORE INTO decks (name) VALUES (?)", name); err != nil {
+		return 0, fmt.Errorf("failed to save deck: %w", err)
+	}
+	rows, err := db.Query("SELECT id FROM decks WHERE name = ?", name)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query deck: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	var id int64
+	if rows.Next() {
+		err = rows.Scan(&id)
+	}
+	if err == nil {
+		err = rows.Err()
+	}
+	return id, err
+}
+
+// GetDeckCounts returns every deck holding active flashcards with how many new
+// and previously reviewed flashcards are due
+func (s *Store) GetDeckCounts() ([]GroupCount, error) {
+	return s.groupCounts(`SELECT decks.name, ` + groupCountColumns + `
+			  FROM flashcards JOIN decks ON decks.id = flashcards.deck_id
+			  WHERE ` + activeFlashcards + `
+			  GROUP BY decks.id ORDER BY decks.name ASC`)
+}
+
+// GetTagCounts returns every tag of active flashcards with how many new and
+// previously reviewed flashcards are due
+func (s *Store) GetTagCounts() ([]GroupCount, error) {
+	return s.groupCounts(`SELECT tags.name, ` + groupCountColumns + `
+			  FROM flashcards
+			  JOIN flashcard_tags ON flashcard_tags.flashcard_id = flashcards.id
+			  JOIN tags ON tags.id = flashcard_tags.tag_id
+			  WHERE ` + activeFlashcards + `
+			  GROUP BY tags.id ORDER BY tags.name ASC`)
+}
+
+func (s *Store) groupCounts(query string) ([]GroupCount, error) {
+	now := formatTime(s.now())
+	rows, err := s.DB.Query(query, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count flashcards: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	var counts []GroupCount
+	for rows.Next() {
+		var c GroupCount
+		if err := rows.Scan(&c.Name, &c.Total, &c.New, &c.Due); err != nil {
+			return nil, fmt.Errorf("failed to scan flashcard count: %w", err)
+		}
+		counts = append(counts, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating flashcard counts: %w", err)
+	}
+	return counts, nil
+}
+
+// Selection restricts the flashcards of a review session; an empty list does
+// not restrict it
+type Selection struct {
+	Decks []string // Deck names
+	Tags  []string // Tags, with or without their "#"
+	Files []string // Source files, or directories holding them
+}
+
+// GetFlashcardsForReviewBySelection returns the flashcards due for review that
+// are in any of the selected decks, have any of the selected tags and come from
+// any of the selected files
+func (s *Store) GetFlashcardsForReviewBySelection(sel Selection) ([]Flashcard, error) {
+	query := `SELECT ` + flashcardColumns + ` FROM flashcards WHERE due_at <= ? AND ` + activeFlashcards
+	args := []interface{}{formatTime(s.now())}
+	if len(sel.Decks) > 0 {
+		// #nosec G202 -- only placeholders (?) are concatenated, never user data
+		query += ` AND deck_id IN (SELECT id FROM decks WHERE name IN (` + placeholders(len(sel.Decks)) + `))`
+		for _, d := range sel.Decks {
+			args = append(args, d)
+		}
+	}
+	if tags := NormalizeTags(sel.Tags); len(tags) > 0 {
+		// #nosec G202 -- only placeholders (?) are concatenated, never user data
+		query += ` AND id IN (SELECT flashcard_tags.flashcard_id FROM flashcard_tags
+			  JOIN tags ON tags.id = flashcard_tags.tag_id WHERE tags.name IN (` + placeholders(len(tags)) + `))`
+		for _, t := range tags {
+			args = append(args, t)
+		}
+	}
+	if len(sel.Files) > 0 {
+		// A file matches itself and a directory every file below it
+		// #nosec G202 -- only placeholders (?) are concatenated, never user data
+		query += ` AND (` + strings.TrimSuffix(strings.Repeat(`file = ? OR substr(file, 1, length(?)) = ? OR `, len(sel.Files)), ` OR `) + `)`
+		for _, f := range sel.Files {
+			dir := strings.TrimSuffix(f, string(filepath.Separator)) + string(filepath.Separator)
+			args = append(args, f, dir, dir)
+		}
+	}
+	rows, err := s.DB.Query(query+` ORDER BY id ASC`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query flashcards for review by selection: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	return scanFlashcards(rows, 100)
+}
+
+// placeholders returns n comma separated query placeholders
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// queryStrings returns the single string column selected by query
+func queryStrings(db queryExecer, query string, args ...interface{}) ([]string, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	var values []string
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, rows.Err()
+}
@@ -0,0 +1,135 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDeckName(t *testing.T) {
+	tests := map[string]string{
+		"/notes/go/channels.md": "channels",
+		"notes.markdown":        "notes",
+		"anki:Spanish::Verbs":   "Spanish::Verbs",
+		"":                      DefaultDeck,
+	}
+	for file, want := range tests {
+		if got := DeckName(file); got != want {
+			t.Errorf("DeckName(%q) = %q, want %q", file, got, want)
+		}
+	}
+}
+
+func TestInsertFlashcard_Deck(t *testing.T) {
+	store := setupTestDB(t)
+	defer store.Close()
+
+	for _, fc := range []Flashcard{
+		{File: "/notes/go.md", Question: "Q1", Answer: "A1"},
+		{File: "/notes/go.md", Question: "Q2", Answer: "A2", Deck: "Interview Prep"},
+	} {
+		if err := store.InsertFlashcard(fc); err != nil {
+			t.Fatalf("InsertFlashcard() error = %v", err)
+		}
+	}
+	cards, err := store.GetFlashcardsByFile("/notes/go.md")
+	if err != nil {
+		t.Fatalf("GetFlashcardsByFile() error = %v", err)
+	}
+	if cards[0].Deck != "go" || cards[1].Deck != "Interview Prep" {
+		t.Errorf("Expected the file and explicit decks, got %q and %q", cards[0].Deck, cards[1].Deck)
+	}
+}
+
+func TestGroupCountsAndSelection(t *testing.T) {
+	store := setupTestDB(t)
+	defer store.Close()
+
+	later := time.Now().AddDate(0, 0, 3)
+	for _, fc := range []Flashcard{
+		{File: "/notes/go.md", Question: "Q1", Answer: "A1", Tags: []string{"concurrency"}},
+		{File: "/notes/go.md", Question: "Q2", Answer: "A2", Tags: []string{"concurrency", "basics"}, DueAt: later},
+		{File: "/notes/go.md", Question: "Q3", Answer: "A3", LastReviewedAt: time.Now().AddDate(0, 0, -2), Repetitions: 1},
+		{File: "/notes/rust.md", Question: "Q4", Answer: "A4", Tags: []string{"concurrency"}},
+	} {
+		if err := store.InsertFlashcard(fc); err != nil {
+			t.Fatalf("InsertFlashcard() error = %v", err)
+		}
+	}
+
+	decks, err := store.GetDeckCounts()
+	if err != nil {
+		t.Fatalf("GetDeckCounts() error = %v", err)
+	}
+	if len(decks) != 2 || decks[0] != (GroupCount{Name: "go", New: 1, Due: 1, Total: 3}) || decks[1] != (GroupCount{Name: "rust", New: 1, Total: 1}) {
+		t.Errorf("Unexpected deck counts %+v", decks)
+	}
+	tags, err := store.GetTagCounts()
+	if err != nil {
+		t.Fatalf("GetTagCounts() error = %v", err)
+	}
+	if len(tags) != 2 || tags[0] != (GroupCount{Name: "basics", Total: 1}) || tags[1] != (GroupCount{Name: "concurrency", New: 2, Total: 3}) {
+		t.Errorf("Unexpected tag counts %+v", tags)
+	}
+
+	tests := []struct {
+		name string
+		sel  Selection
+		want []string
+	}{
+		{"deck", Selection{Decks: []string{"go"}}, []string{"Q1", "Q3"}},
+		{"tag", Selection{Tags: []string{"#Concurrency"}}, []string{"Q1", "Q4"}},
+		{"deck and tag", Selection{Decks: []string{"rust"}, Tags: []string{"concurrency"}}, []string{"Q4"}},
+		{"file", Selection{Files: []string{"/notes/rust.md"}}, []string{"Q4"}},
+		{"directory", Selection{Files: []string{"/notes/"}, Tags: []string{"concurrency"}}, []string{"Q1", "Q4"}},
+		{"file prefix", Selection{Files: []string{"/notes/go"}}, nil},
+		{"everything", Selection{}, []string{"Q1", "Q3", "Q4"}},
+		{"unknown deck", Selection{Decks: []string{"python"}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cards, err := store.GetFlashcardsForReviewBySelection(tt.sel)
+			if err != nil {
+				t.Fatalf("GetFlashcardsForReviewBySelection() error = %v", err)
+			}
+			if len(cards) != len(tt.want) {
+				t.Fatalf("Expected %v, got %+v", tt.want, cards)
+			}
+			for i, fc := range cards {
+				if fc.Question != tt.want[i] {
+					t.Errorf("Expected %v, got %q at %d", tt.want, fc.Question, i)
+				}
+			}
+		})
+	}
+}
+
+func TestMigrateCreateDecks_Backfill(t *testing.T) {
+	original := migrations
+	defer func() { migrations = original }()
+
+	// A database from before decks existed
+	migrations = original[:7]
+	store, err := NewStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	defer store.Close()
+	for _, file := range []string{"/notes/go.md", "anki:Spanish"} {
+		if _, err := store.DB.Exec("INSERT INTO flashcards (file, question, answer, due_at) VALUES (?, 'Q', 'A', CURRENT_TIMESTAMP)", file); err != nil {
+			t.Fatalf("insert error = %v", err)
+		}
+	}
+
+	migrations = original
+	if _, err := store.Migrate(); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	cards, err := store.GetAllFlashcards()
+	if err != nil {
+		t.Fatalf("GetAllFlashcards() error = %v", err)
+	}
+	if len(cards) != 2 || cards[0].Deck != "go" || cards[1].Deck != "Spanish" {
+		t.Errorf("Expected existing flashcards in their file's deck, got %+v", cards)
+	}
+}
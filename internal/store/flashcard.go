@@ -1,11 +1,89 @@
 // Package store provides data persistence for flashcards using SQLite
 package store
 
+import "time"
+
+// Card types
+const (
+	CardBasic = "basic" // Question shown first, answer revealed
+	CardCloze = "cloze" // Question holds text with {{c1::...}} deletions, one flashcard per deletion number
+)
+
 // Flashcard represents a single flashcard with spaced repetition metadata
 type Flashcard struct {
-	ID        int    // Unique identifier for the flashcard
-	File      string // Source file path where the flashcard was generated from
-	Question  string // The question/text to be reviewed
-	Answer    string // The answer/explanation for the question
-	RevisitIn int    // Number of days until next review (<=0 means due for review)
+	ID             int       // Unique identifier for the flashcard
+	File           string    // Source file path where the flashcard was generated from
+	Heading        string    // Heading breadcrumb of the source section ("" if unknown)
+	Question       string    // The question/text to be reviewed (the cloze text for cloze cards)
+	Answer         string    // The answer/explanation for the question (optional extra notes for cloze cards)
+	CardType       string    // CardBasic or CardCloze
+	ClozeIndex     int       // Deletion number a cloze card tests, e.g. 2 for {{c2::...}} (0 for basic cards)
+	DueAt          time.Time // When the flashcard is next due for review (zero means due immediately)
+	LastReviewedAt time.Time // When the flashcard was last reviewed (zero if never reviewed)
+	Ease           float64   // SM-2 ease factor
+	Repetitions    int       // Consecutive successful reviews
+	Lapses         int       // Number of times the flashcard was forgotten
+	Stability      float64   // FSRS memory stability in days
+	Difficulty     float64   // FSRS difficulty between 1 and 10
+	IntervalDays   int       // Days between the last review and the due date
+	ArchivedAt     time.Time // When the flashcard was retired because its source changed (zero if active)
+	Deck           string    // Deck the flashcard belongs to
+	Tags           []string  // Normalized tags, sorted by name
+}
+
+// IsDue reports whether the flashcard is due for review at the given time
+func (fc Flashcard) IsDue(now time.Time) bool {
+	return !fc.DueAt.After(now)
+}
+
+// IsNew reports whether the flashcard has never been reviewed
+func (fc Flashcard) IsNew() bool {
+	return fc.LastReviewedAt.IsZero() && fc.Repetitions == 0
+}
+
+// IsCloze reports whether the flashcard is a cloze deletion
+func (fc Flashcard) IsCloze() bool {
+	return fc.CardType == CardCloze
+}
+
+// BurySiblings keeps only the first cloze card of each cloze text, so the
+// deletions of one sentence are not reviewed in the same session and reveal
+// each other; the buried siblings stay due for the next session
+func BurySiblings(flashcards []Flashcard) []Flashcard {
+	seen := make(map[[2]string]bool)
+	kept := make([]Flashcard, 0, len(flashcards))
+	for _, fc := range flashcards {
+		if fc.IsCloze() {
+			key := [2]string{fc.File, fc.Question}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		kept = append(kept, fc)
+	}
+	return kept
+}
+
+// DaysUntilDue returns the number of calendar days between now and the due date
+// A value <= 0 means the flashcard is due (negative values are overdue)
+func (fc Flashcard) DaysUntilDue(now time.Time) int {
+	if fc.DueAt.IsZero() {
+		return 0
+	}
+	due := startOfDay(fc.DueAt.In(now.Location()))
+	return int(due.Sub(startOfDay(now)).Round(24*time.Hour) / (24 * time.Hour))
+}
+
+// DueAfter returns the due date for a flashcard scheduled the given number of days from now
+// Due dates fall on the start of the local day, so a card scheduled in 1 day is
+// available for review any time tomorrow
+func DueAfter(now time.Time, days int) time.Time {
+	return startOfDay(now).AddDate(0, 0, days)
+}
+
+// startOfDay truncates t to midnight in its own location
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
 }
@@ -0,0 +1,80 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFlashcardDaysUntilDue(t *testing.T) {
+	now := time.Date(2024, 3, 10, 21, 30, 0, 0, time.Local)
+
+	tests := []struct {
+		name     string
+		dueAt    time.Time
+		expected int
+	}{
+		{name: "never scheduled", dueAt: time.Time{}, expected: 0},
+		{name: "due earlier today", dueAt: now.Add(-time.Hour), expected: 0},
+		{name: "due tomorrow", dueAt: DueAfter(now, 1), expected: 1},
+		{name: "due in a week", dueAt: DueAfter(now, 7), expected: 7},
+		{name: "overdue", dueAt: DueAfter(now, -2), expected: -2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fc := Flashcard{DueAt: tt.dueAt}
+			if got := fc.DaysUntilDue(now); got != tt.expected {
+				t.Errorf("DaysUntilDue() = %d, expected %d", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFlashcardIsDue(t *testing.T) {
+	now := time.Now()
+
+	if !(Flashcard{}).IsDue(now) {
+		t.Error("Flashcard without a due date should be due")
+	}
+	if !(Flashcard{DueAt: now.Add(-time.Minute)}).IsDue(now) {
+		t.Error("Flashcard due in the past should be due")
+	}
+	if (Flashcard{DueAt: DueAfter(now, 1)}).IsDue(now) {
+		t.Error("Flashcard due tomorrow should not be due")
+	}
+}
+
+func TestDueAfter(t *testing.T) {
+	now := time.Date(2024, 3, 10, 21, 30, 0, 0, time.UTC)
+	got := DueAfter(now, 3)
+	expected := time.Date(2024, 3, 13, 0, 0, 0, 0, time.UTC)
+	if !got.Equal(expected) {
+		t.Errorf("DueAfter() = %v, expected %v", got, expected)
+	}
+}
+
+func TestBurySiblings(t *testing.T) {
+	cards := []Flashcard{
+		{ID: 1, File: "a.md", Question: "{{c1::Go}} has {{c2::channels}}", CardType: CardCloze, ClozeIndex: 1},
+		{ID: 2, File: "a.md", Question: "What is Go?", Answer: "A language", CardType: CardBasic},
+		{ID: 3, File: "a.md", Question: "{{c1::Go}} has {{c2::channels}}", CardType: CardCloze, ClozeIndex: 2},
+		{ID: 4, File: "b.md", Question: "{{c1::Go}} has {{c2::channels}}", CardType: CardCloze, ClozeIndex: 2},
+		{ID: 5, File: "a.md", Question: "What is Go?", Answer: "A language", CardType: CardBasic},
+	}
+
+	kept := BurySiblings(cards)
+	var ids []int
+	for _, fc := range kept {
+		ids = append(ids, fc.ID)
+	}
+	expected := []int{1, 2, 4, 5}
+	if len(ids) != len(expected) {
+		t.Fatalf("Expected ids %v, got %v", expected, ids)
+	}
+	for i := range expected {
+		if ids[i] != expected[i] {
+			t.Errorf("Expected ids %v, got %v", expected, ids)
+			break
+		}
+	}
+}
@@ -0,0 +1,399 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// migration is a single versioned schema change
+// Migrations run in order of version, each inside its own transaction, and are
+// recorded in the schema_migrations table once applied
+type migration struct {
+	version int
+	name    string
+	up      func(tx *sql.Tx) error
+}
+
+// MigrationStatus describes a known migration and whether it has been applied
+type MigrationStatus struct {
+	Version   int       // Schema version reached once the migration is applied
+	Name      string    // Short description of the schema change
+	AppliedAt time.Time // When the migration was applied (zero if pending)
+}
+
+// Applied reports whether the migration has been applied to the database
+func (m MigrationStatus) Applied() bool {
+	return !m.AppliedAt.IsZero()
+}
+
+// migrations lists every schema change in the order it must be applied
+// Never edit or reorder an existing entry: append a new migration instead
+var migrations = []migration{
+	{1, "create flashcards table", migrateCreateFlashcards},
+	{2, "replace revisitin with due dates", migrateDueDates},
+	{3, "add scheduler state columns", migrateSchedulingColumns},
+	{4, "create reviews table", migrateCreateReviews},
+	{5, "track source sections", migrateSourceSections},
+	{6, "create tags tables", migrateCreateTags},
+	{7, "add card types", migrateCardTypes},
+	{8, "create decks table", migrateCreateDecks},
+	{9, "create study days table", migrateCreateStudyDays},
+	{10, "mark relearning reviews", migrateRelearningReviews},
+}
+
+// queryExecer is implemented by both *sql.DB and *sql.Tx
+type queryExecer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// LatestSchemaVersion returns the schema version this build of catv expects
+func LatestSchemaVersion() int {
+	return migrations[len(migrations)-1].version
+}
+
+// Migrate applies every pending migration and returns the ones that were applied
+func (s *Store) Migrate() ([]MigrationStatus, error) {
+	_, err := s.DB.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+			  version INTEGER PRIMARY KEY,
+			  name TEXT NOT NULL,
+			  applied_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		  );`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	statuses, err := s.MigrationStatus()
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []MigrationStatus
+	for i, m := range migrations {
+		if statuses[i].Applied() {
+			continue
+		}
+		if err := s.applyMigration(m); err != nil {
+			return applied, fmt.Errorf("migration %d (%s) failed: %w", m.version, m.name, err)
+		}
+		applied = append(applied, MigrationStatus{Version: m.version, Name: m.name, AppliedAt: s.now()})
+	}
+	return applied, nil
+}
+
+// applyMigration runs a migration and records it in a single transaction
+func (s *Store) applyMigration(m migration) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		if err := m.up(tx); err != nil {
+			return err
+		}
+		_, err := tx.Exec("INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)",
+			m.version, m.name, formatTime(s.now()))
+		return err
+	})
+}
+
+// MigrationStatus returns every known migration along with when it was applied
+// It fails if the database was migrated by a newer version of catv
+func (s *Store) MigrationStatus() ([]MigrationStatus, error) {
+	appliedAt := make(map[int]time.Time)
+
+	exists, err := hasTable(s.DB, "schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect schema: %w", err)
+	}
+	if exists {
+		rows, err := s.DB.Query("SELECT version, applied_at FROM schema_migrations")
+		if err != nil {
+			return nil, fmt.Errorf("failed to query schema_migrations: %w", err)
+		}
+		defer func() {
+			_ = rows.Close()
+		}()
+		for rows.Next() {
+			var version int
+			var at time.Time
+			if err := rows.Scan(&version, &at); err != nil {
+				return nil, fmt.Errorf("failed to scan migration: %w", err)
+			}
+			appliedAt[version] = at
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("error iterating migrations: %w", err)
+		}
+	}
+
+	latest := LatestSchemaVersion()
+	for version := range appliedAt {
+		if version > latest {
+			return nil, fmt.Errorf("database schema version %d is newer than this catv supports (%d); please upgrade catv", version, latest)
+		}
+	}
+
+	statuses := make([]MigrationStatus, len(migrations))
+	for i, m := range migrations {
+		statuses[i] = MigrationStatus{Version: m.version, Name: m.name, AppliedAt: appliedAt[m.version]}
+	}
+	return statuses, nil
+}
+
+// migrateCreateFlashcards creates the original flashcards schema
+// Databases created before migrations existed already have it, so this is a no-op for them
+func migrateCreateFlashcards(tx *sql.Tx) error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS flashcards (
+			  id INTEGER PRIMARY KEY AUTOINCREMENT,
+			  file TEXT NOT NULL,
+			  question TEXT NOT NULL,
+			  answer TEXT NOT NULL,
+			  revisitin INTEGER DEFAULT 0,
+			  created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			  updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		  );`,
+		`CREATE INDEX IF NOT EXISTS idx_flashcards_revisitin ON flashcards(revisitin)`,
+		`CREATE INDEX IF NOT EXISTS idx_flashcards_file ON flashcards(file)`,
+		`CREATE INDEX IF NOT EXISTS idx_flashcards_file_revisitin ON flashcards(file, revisitin)`,
+	}
+	return execAll(tx, statements)
+}
+
+// migrateDueDates replaces the legacy revisitin day counter with absolute due dates
+// Cards are considered due revisitin days after they were last updated
+func migrateDueDates(tx *sql.Tx) error {
+	for _, col := range []struct{ name, definition string }{
+		{"due_at", "DATETIME"},
+		{"last_reviewed_at", "DATETIME"},
+	} {
+		if err := addColumnIfMissing(tx, "flashcards", col.name, col.definition); err != nil {
+			return err
+		}
+	}
+
+	hasRevisitIn, err := hasColumn(tx, "flashcards", "revisitin")
+	if err != nil {
+		return err
+	}
+	if hasRevisitIn {
+		err := execAll(tx, []string{
+			`UPDATE flashcards SET
+				due_at = CASE WHEN revisitin > 0
+					THEN datetime(COALESCE(updated_at, created_at, CURRENT_TIMESTAMP), '+' || revisitin || ' days')
+					ELSE COALESCE(updated_at, created_at, CURRENT_TIMESTAMP) END,
+				last_reviewed_at = CASE WHEN revisitin > 0 THEN COALESCE(updated_at, created_at) END`,
+			`DROP INDEX IF EXISTS idx_flashcards_revisitin`,
+			`DROP INDEX IF EXISTS idx_flashcards_file_revisitin`,
+			`ALTER TABLE flashcards DROP COLUMN revisitin`,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return execAll(tx, []string{
+		`UPDATE flashcards SET due_at = COALESCE(updated_at, created_at, CURRENT_TIMESTAMP) WHERE due_at IS NULL`,
+		`CREATE INDEX IF NOT EXISTS idx_flashcards_due_at ON flashcards(due_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_flashcards_file_due_at ON flashcards(file, due_at)`,
+	})
+}
+
+// schedulingColumns are the per-card scheduler state columns and their definitions
+var schedulingColumns = []struct{ name, definition string }{
+	{"ease", "REAL NOT NULL DEFAULT 2.5"},
+	{"repetitions", "INTEGER NOT NULL DEFAULT 0"},
+	{"lapses", "INTEGER NOT NULL DEFAULT 0"},
+	{"stability", "REAL NOT NULL DEFAULT 0"},
+	{"difficulty", "REAL NOT NULL DEFAULT 0"},
+	{"interval_days", "INTEGER NOT NULL DEFAULT 0"},
+}
+
+// migrateSchedulingColumns adds the per-card scheduler state
+func migrateSchedulingColumns(tx *sql.Tx) error {
+	for _, col := range schedulingColumns {
+		if err := addColumnIfMissing(tx, "flashcards", col.name, col.definition); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateCreateReviews adds the review history log
+func migrateCreateReviews(tx *sql.Tx) error {
+	return execAll(tx, []string{
+		`CREATE TABLE IF NOT EXISTS reviews (
+			  id INTEGER PRIMARY KEY AUTOINCREMENT,
+			  flashcard_id INTEGER NOT NULL REFERENCES flashcards(id) ON DELETE CASCADE,
+			  reviewed_at DATETIME NOT NULL,
+			  grade INTEGER NOT NULL,
+			  response_ms INTEGER NOT NULL DEFAULT 0,
+			  interval_before INTEGER NOT NULL DEFAULT 0,
+			  interval_after INTEGER NOT NULL DEFAULT 0
+		  );`,
+		`CREATE INDEX IF NOT EXISTS idx_reviews_flashcard_id ON reviews(flashcard_id, reviewed_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_reviews_reviewed_at ON reviews(reviewed_at)`,
+	})
+}
+
+// migrateSourceSections records content hashes of source files and their
+// sections, and links flashcards to the section they were generated from
+func migrateSourceSections(tx *sql.Tx) error {
+	for _, col := range []struct{ name, definition string }{
+		{"heading", "TEXT NOT NULL DEFAULT ''"},
+		{"archived_at", "DATETIME"},
+	} {
+		if err := addColumnIfMissing(tx, "flashcards", col.name, col.definition); err != nil {
+			return err
+		}
+	}
+	return execAll(tx, []string{
+		`CREATE TABLE IF NOT EXISTS source_files (
+			  file TEXT PRIMARY KEY,
+			  hash TEXT NOT NULL,
+			  updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		  );`,
+		`CREATE TABLE IF NOT EXISTS source_sections (
+			  file TEXT NOT NULL,
+			  heading TEXT NOT NULL,
+			  hash TEXT NOT NULL,
+			  PRIMARY KEY (file, heading)
+		  );`,
+		`CREATE INDEX IF NOT EXISTS idx_flashcards_file_heading ON flashcards(file, heading)`,
+	})
+}
+
+// migrateCreateTags adds tags and the many-to-many link between tags and flashcards
+func migrateCreateTags(tx *sql.Tx) error {
+	return execAll(tx, []string{
+		`CREATE TABLE IF NOT EXISTS tags (
+			  id INTEGER PRIMARY KEY AUTOINCREMENT,
+			  name TEXT NOT NULL UNIQUE
+		  );`,
+		`CREATE TABLE IF NOT EXISTS flashcard_tags (
+			  flashcard_id INTEGER NOT NULL REFERENCES flashcards(id),
+			  tag_id INTEGER NOT NULL REFERENCES tags(id),
+			  PRIMARY KEY (flashcard_id, tag_id)
+		  );`,
+		`CREATE INDEX IF NOT EXISTS idx_flashcard_tags_tag_id ON flashcard_tags(tag_id)`,
+	})
+}
+
+// migrateCardTypes distinguishes cloze deletions from question/answer flashcards
+func migrateCardTypes(tx *sql.Tx) error {
+	for _, col := range []struct{ name, definition string }{
+		{"card_type", "TEXT NOT NULL DEFAULT '" + CardBasic + "'"},
+		{"cloze_index", "INTEGER NOT NULL DEFAULT 0"},
+	} {
+		if err := addColumnIfMissing(tx, "flashcards", col.name, col.definition); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateCreateDecks adds decks and assigns existing flashcards to the deck
+// named after their source file
+func migrateCreateDecks(tx *sql.Tx) error {
+	err := execAll(tx, []string{
+		`CREATE TABLE IF NOT EXISTS decks (
+			  id INTEGER PRIMARY KEY AUTOINCREMENT,
+			  name TEXT NOT NULL UNIQUE
+		  );`,
+	})
+	if err != nil {
+		return err
+	}
+	if err := addColumnIfMissing(tx, "flashcards", "deck_id", "INTEGER REFERENCES decks(id)"); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`CREATE INDEX IF NOT EXISTS idx_flashcards_deck_id ON flashcards(deck_id)`); err != nil {
+		return err
+	}
+
+	files, err := queryStrings(tx, `SELECT DISTINCT file FROM flashcards WHERE deck_id IS NULL`)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		id, err := ensureDeck(tx, DeckName(file))
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`UPDATE flashcards SET deck_id = ? WHERE file = ? AND deck_id IS NULL`, id, file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// execAll executes statements in order, stopping at the first error
+func execAll(db queryExecer, statements []string) error {
+	for _, stmt := range statements {
+		if _, err := db.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addColumnIfMissing adds a column to a table unless it already exists
+func addColumnIfMissing(db queryExecer, table, column, definition string) error {
+	exists, err := hasColumn(db, table, column)
+	if err != nil || exists {
+		return err
+	}
+	// #nosec G202 -- table, column and definition are internal constants, never user input
+	_, err = db.Exec("ALTER TABLE " + table + " ADD COLUMN " + column + " " + definition)
+	return err
+}
+
+// hasColumn reports whether the given table has a column with the given name
+func hasColumn(db queryExecer, table, column string) (bool, error) {
+	// #nosec G202 -- table names are internal constants, never user input
+	rows, err := db.Query("SELECT name FROM pragma_table_info('" + table + "')")
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return false, err
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
+
+// hasTable reports whether a table with the given name exists
+func hasTable(db queryExecer, table string) (bool, error) {
+	rows, err := db.Query("SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = ?", table)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	return rows.Next(), rows.Err()
+}
+
+// migrateCreateStudyDays adds the per-day counts of studied flashcards that
+// daily limits are checked against
+func migrateCreateStudyDays(tx *sql.Tx) error {
+	return execAll(tx, []string{
+		`CREATE TABLE IF NOT EXISTS study_days (
+			  day TEXT PRIMARY KEY,
+			  new_cards INTEGER NOT NULL DEFAULT 0,
+			  reviews INTEGER NOT NULL DEFAULT 0
+		  );`,
+	})
+}
+
+// migrateRelearningReviews flags the reviews logged for relearning steps, which
+// leave the schedule as it was; earlier reviews cannot be told apart
+func migrateRelearningReviews(tx *sql.Tx) error {
+	return addColumnIfMissing(tx, "reviews", "relearning", "INTEGER NOT NULL DEFAULT 0")
+}
@@ -0,0 +1,131 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestMigrate_FreshDatabase(t *testing.T) {
+	store := setupTestDB(t)
+	defer store.Close()
+
+	statuses, err := store.MigrationStatus()
+	if err != nil {
+		t.Fatalf("MigrationStatus() error = %v", err)
+	}
+	if len(statuses) != len(migrations) {
+		t.Fatalf("Expected %d migrations, got %d", len(migrations), len(statuses))
+	}
+	for _, st := range statuses {
+		if !st.Applied() {
+			t.Errorf("Expected migration %d (%s) to be applied", st.Version, st.Name)
+		}
+	}
+
+	// Running again is a no-op
+	applied, err := store.Migrate()
+	if err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	if len(applied) != 0 {
+		t.Errorf("Expected no migrations to be applied twice, got %d", len(applied))
+	}
+}
+
+func TestOpenStore_ReportsPendingMigrations(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+
+	store, err := OpenStore(dbPath)
+	if err != nil {
+		t.Fatalf("OpenStore() error = %v", err)
+	}
+	defer store.Close()
+
+	statuses, err := store.MigrationStatus()
+	if err != nil {
+		t.Fatalf("MigrationStatus() error = %v", err)
+	}
+	for _, st := range statuses {
+		if st.Applied() {
+			t.Errorf("Expected migration %d to be pending on a new database", st.Version)
+		}
+	}
+
+	applied, err := store.Migrate()
+	if err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	if len(applied) != len(migrations) {
+		t.Errorf("Expected %d migrations to be applied, got %d", len(migrations), len(applied))
+	}
+	if applied[len(applied)-1].Version != LatestSchemaVersion() {
+		t.Errorf("Expected last applied version %d, got %d", LatestSchemaVersion(), applied[len(applied)-1].Version)
+	}
+}
+
+func TestMigrate_RejectsNewerSchema(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+
+	store, err := NewStore(dbPath)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	_, err = store.DB.Exec("INSERT INTO schema_migrations (version, name) VALUES (?, 'from the future')", LatestSchemaVersion()+1)
+	store.Close()
+	if err != nil {
+		t.Fatalf("insert error = %v", err)
+	}
+
+	if _, err := NewStore(dbPath); err == nil {
+		t.Error("Expected error opening a database with a newer schema version")
+	}
+}
+
+func TestMigrate_RollsBackFailedMigration(t *testing.T) {
+	original := migrations
+	defer func() { migrations = original }()
+
+	migrations = append(append([]migration{}, original...), migration{
+		version: LatestSchemaVersion() + 1,
+		name:    "broken",
+		up: func(tx *sql.Tx) error {
+			if _, err := tx.Exec("CREATE TABLE half_done (id INTEGER)"); err != nil {
+				return err
+			}
+			return errors.New("boom")
+		},
+	})
+
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	store, err := OpenStore(dbPath)
+	if err != nil {
+		t.Fatalf("OpenStore() error = %v", err)
+	}
+	defer store.Close()
+
+	applied, err := store.Migrate()
+	if err == nil {
+		t.Fatal("Expected Migrate() to fail")
+	}
+	if len(applied) != len(original) {
+		t.Errorf("Expected the %d working migrations to be applied, got %d", len(original), len(applied))
+	}
+
+	exists, err := hasTable(store.DB, "half_done")
+	if err != nil {
+		t.Fatalf("hasTable() error = %v", err)
+	}
+	if exists {
+		t.Error("Expected the failed migration to be rolled back")
+	}
+
+	statuses, err := store.MigrationStatus()
+	if err != nil {
+		t.Fatalf("MigrationStatus() error = %v", err)
+	}
+	if statuses[len(statuses)-1].Applied() {
+		t.Error("Expected the failed migration to stay pending")
+	}
+}
@@ -0,0 +1,103 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Review is a single graded review of a flashcard
+type Review struct {
+	ID             int           // Unique identifier for the review
+	FlashcardID    int           // Flashcard that was reviewed
+	ReviewedAt     time.Time     // When the flashcard was graded
+	Grade          int           // Grade given, from 1 (Again) to 4 (Easy)
+	ResponseTime   time.Duration // Time taken to reveal the answer
+	IntervalBefore int           // Scheduled interval in days before the review
+	IntervalAfter  int           // Scheduled interval in days after the review
+	Relearning     bool          // Logged for a relearning step, leaving the schedule as it was
+}
+
+// reviewColumns lists the columns selected for every Review query
+const reviewColumns = "id, flashcard_id, reviewed_at, grade, response_ms, interval_before, interval_after, relearning"
+
+// InsertReview logs a graded review of a flashcard, returning its id
+func (s *Store) InsertReview(r Review) (int, error) {
+	return s.insertReview(s.DB, r)
+}
+
+func (s *Store) insertReview(db queryExecer, r Review) (int, error) {
+	reviewedAt := r.ReviewedAt
+	if reviewedAt.IsZero() {
+		reviewedAt = s.now()
+	}
+	res, err := db.Exec(`INSERT INTO reviews (flashcard_id, reviewed_at, grade, response_ms, interval_before, interval_after, relearning)
+			  VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		r.FlashcardID, formatTime(reviewedAt), r.Grade, r.ResponseTime.Milliseconds(), r.IntervalBefore, r.IntervalAfter, r.Relearning)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert review: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get review id: %w", err)
+	}
+	return int(id), nil
+}
+
+// DeleteReview removes a logged review
+func (s *Store) DeleteReview(id int) error {
+	if _, err := s.DB.Exec("DELETE FROM reviews WHERE id = ?", id); err != nil {
+		return fmt.Errorf("failed to delete review: %w", err)
+	}
+	return nil
+}
+
+// GetReviewsForFlashcard returns the review history of a flashcard, oldest first
+func (s *Store) GetReviewsForFlashcard(flashcardID int) ([]Review, error) {
+	rows, err := s.DB.Query(`SELECT `+reviewColumns+` FROM reviews
+			  WHERE flashcard_id = ?
+			  ORDER BY reviewed_at ASC, id ASC`, flashcardID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reviews: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	return scanReviews(rows, 16)
+}
+
+// GetReviewsSince returns every review made at or after since, oldest first
+// Pass the zero time to get the full review history
+func (s *Store) GetReviewsSince(since time.Time) ([]Review, error) {
+	rows, err := s.DB.Query(`SELECT `+reviewColumns+` FROM reviews
+			  WHERE reviewed_at >= ?
+			  ORDER BY reviewed_at ASC, id ASC`, formatTime(since))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reviews: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	return scanReviews(rows, 100)
+}
+
+// scanReviews reads every row selected with reviewColumns into a slice
+func scanReviews(rows *sql.Rows, capacity int) ([]Review, error) {
+	reviews := make([]Review, 0, capacity)
+	for rows.Next() {
+		var r Review
+		var responseMs int64
+		err := rows.Scan(&r.ID, &r.FlashcardID, &r.ReviewedAt, &r.Grade, &responseMs, &r.IntervalBefore, &r.IntervalAfter, &r.Relearning)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan review: %w", err)
+		}
+		r.ResponseTime = time.Duration(responseMs) * time.Millisecond
+		reviews = append(reviews, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reviews: %w", err)
+	}
+
+	return reviews, nil
+}
@@ -0,0 +1,173 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecordReview(t *testing.T) {
+	store := setupTestDB(t)
+	defer store.Close()
+
+	if err := store.InsertFlashcard(Flashcard{File: "/test/1.md", Question: "Q1", Answer: "A1"}); err != nil {
+		t.Fatalf("InsertFlashcard() error = %v", err)
+	}
+	cards, err := store.GetAllFlashcards()
+	if err != nil {
+		t.Fatalf("GetAllFlashcards() error = %v", err)
+	}
+
+	now := time.Now()
+	fc := cards[0]
+	fc.IntervalDays = 2
+	fc.LastReviewedAt = now
+	fc.DueAt = DueAfter(now, 2)
+	review := Review{
+		ReviewedAt:     now,
+		Grade:          3,
+		ResponseTime:   4250 * time.Millisecond,
+		IntervalBefore: 0,
+		IntervalAfter:  2,
+	}
+	if _, err := store.RecordReview(fc, review); err != nil {
+		t.Fatalf("RecordReview() error = %v", err)
+	}
+
+	reviews, err := store.GetReviewsForFlashcard(fc.ID)
+	if err != nil {
+		t.Fatalf("GetReviewsForFlashcard() error = %v", err)
+	}
+	if len(reviews) != 1 {
+		t.Fatalf("Expected 1 review, got %d", len(reviews))
+	}
+	got := reviews[0]
+	if got.FlashcardID != fc.ID || got.Grade != 3 || got.IntervalAfter != 2 {
+		t.Errorf("Unexpected review: %+v", got)
+	}
+	if got.ResponseTime != 4250*time.Millisecond {
+		t.Errorf("Expected response time 4.25s, got %v", got.ResponseTime)
+	}
+	if got.ReviewedAt.Unix() != now.Unix() {
+		t.Errorf("Expected reviewed at %v, got %v", now, got.ReviewedAt)
+	}
+
+	cards, err = store.GetAllFlashcards()
+	if err != nil {
+		t.Fatalf("GetAllFlashcards() error = %v", err)
+	}
+	if cards[0].IntervalDays != 2 {
+		t.Errorf("Expected flashcard to be updated with the review, got %+v", cards[0])
+	}
+}
+
+func TestUndoReview(t *testing.T) {
+	store := setupTestDB(t)
+	defer store.Close()
+
+	if err := store.InsertFlashcard(Flashcard{File: "/test/1.md", Question: "Q1", Answer: "A1"}); err != nil {
+		t.Fatalf("InsertFlashcard() error = %v", err)
+	}
+	cards, err := store.GetAllFlashcards()
+	if err != nil {
+		t.Fatalf("GetAllFlashcards() error = %v", err)
+	}
+	previous := cards[0]
+
+	now := time.Now()
+	fc := previous
+	fc.IntervalDays, fc.Repetitions, fc.LastReviewedAt, fc.DueAt = 2, 1, now, DueAfter(now, 2)
+	id, err := store.RecordReview(fc, Review{ReviewedAt: now, Grade: 3, IntervalAfter: 2})
+	if err != nil {
+		t.Fatalf("RecordReview() error = %v", err)
+	}
+	relearnID, err := store.InsertReview(Review{FlashcardID: fc.ID, ReviewedAt: now, Grade: 1})
+	if err != nil || relearnID == id {
+		t.Fatalf("InsertReview() = %d, %v", relearnID, err)
+	}
+
+	if err := store.DeleteReview(relearnID); err != nil {
+		t.Fatalf("DeleteReview() error = %v", err)
+	}
+	if err := store.UndoReview(previous, id); err != nil {
+		t.Fatalf("UndoReview() error = %v", err)
+	}
+
+	reviews, err := store.GetReviewsForFlashcard(fc.ID)
+	if err != nil || len(reviews) != 0 {
+		t.Errorf("Expected the reviews to be deleted, got %+v (%v)", reviews, err)
+	}
+	cards, err = store.GetAllFlashcards()
+	if err != nil {
+		t.Fatalf("GetAllFlashcards() error = %v", err)
+	}
+	if !cards[0].IsNew() || cards[0].IntervalDays != 0 || !cards[0].IsDue(now) {
+		t.Errorf("Expected the flashcard to be new and due again, got %+v", cards[0])
+	}
+	if day, err := store.GetStudyDay(now); err != nil || day.NewCards != 0 || day.Reviews != 0 {
+		t.Errorf("Expected the review to no longer count for the day, got %+v (%v)", day, err)
+	}
+
+	if err := store.UndoReview(previous, id); err == nil {
+		t.Error("Expected an error when undoing a review twice")
+	}
+}
+
+func TestGetReviewsSince(t *testing.T) {
+	store := setupTestDB(t)
+	defer store.Close()
+
+	now := time.Now()
+	for i, daysAgo := range []int{10, 3, 0} {
+		r := Review{FlashcardID: 1, ReviewedAt: now.AddDate(0, 0, -daysAgo), Grade: i + 1, Relearning: daysAgo == 0}
+		if _, err := store.InsertReview(r); err != nil {
+			t.Fatalf("InsertReview() error = %v", err)
+		}
+	}
+
+	all, err := store.GetReviewsSince(time.Time{})
+	if err != nil {
+		t.Fatalf("GetReviewsSince() error = %v", err)
+	}
+	if len(all) != 3 {
+		t.Errorf("Expected 3 reviews in total, got %d", len(all))
+	}
+
+	recent, err := store.GetReviewsSince(now.AddDate(0, 0, -7))
+	if err != nil {
+		t.Fatalf("GetReviewsSince() error = %v", err)
+	}
+	if len(recent) != 2 {
+		t.Fatalf("Expected 2 reviews in the last week, got %d", len(recent))
+	}
+	if recent[0].Grade != 2 || recent[1].Grade != 3 {
+		t.Errorf("Expected reviews oldest first, got grades %d, %d", recent[0].Grade, recent[1].Grade)
+	}
+	if recent[0].Relearning || !recent[1].Relearning {
+		t.Errorf("Expected only the last review to be a relearning step, got %+v", recent)
+	}
+}
+
+func TestDeleteFlashcard_RemovesReviews(t *testing.T) {
+	store := setupTestDB(t)
+	defer store.Close()
+
+	if err := store.InsertFlashcard(Flashcard{File: "/test/1.md", Question: "Q1", Answer: "A1"}); err != nil {
+		t.Fatalf("InsertFlashcard() error = %v", err)
+	}
+	cards, _ := store.GetAllFlashcards()
+	if _, err := store.InsertReview(Review{FlashcardID: cards[0].ID, Grade: 1}); err != nil {
+		t.Fatalf("InsertReview() error = %v", err)
+	}
+
+	if err := store.DeleteFlashcard(cards[0].ID); err != nil {
+		t.Fatalf("DeleteFlashcard() error = %v", err)
+	}
+
+	reviews, err := store.GetReviewsForFlashcard(cards[0].ID)
+	if err != nil {
+		t.Fatalf("GetReviewsForFlashcard() error = %v", err)
+	}
+	if len(reviews) != 0 {
+		t.Errorf("Expected reviews to be deleted with the flashcard, got %d", len(reviews))
+	}
+}
@@ -0,0 +1,134 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// SourceHashes are the content fingerprints of a source file recorded when
+// flashcards were last generated from it
+type SourceHashes struct {
+	File     string            // Absolute path of the source file
+	Hash     string            // Fingerprint of the whole file ("" if the last generation was incomplete)
+	Sections map[string]string // Fingerprint of each section, keyed by heading breadcrumb
+}
+
+// GetSourceHashes returns the recorded fingerprints of a source file
+// The boolean is false when the file was never recorded
+func (s *Store) GetSourceHashes(file string) (SourceHashes, bool, error) {
+	src := SourceHashes{File: file, Sections: make(map[string]string)}
+	err := s.DB.QueryRow("SELECT hash FROM source_files WHERE file = ?", file).Scan(&src.Hash)
+	if err == sql.ErrNoRows {
+		return src, false, nil
+	}
+	if err != nil {
+		return src, false, fmt.Errorf("failed to query source file: %w", err)
+	}
+
+	rows, err := s.DB.Query("SELECT heading, hash FROM source_sections WHERE file = ?", file)
+	if err != nil {
+		return src, false, fmt.Errorf("failed to query source sections: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	for rows.Next() {
+		var heading, hash string
+		if err := rows.Scan(&heading, &hash); err != nil {
+			return src, false, fmt.Errorf("failed to scan source section: %w", err)
+		}
+		src.Sections[heading] = hash
+	}
+	if err := rows.Err(); err != nil {
+		return src, false, fmt.Errorf("error iterating source sections: %w", err)
+	}
+	return src, true, nil
+}
+
+// SaveSourceHashes replaces the recorded fingerprints of a source file
+func (s *Store) SaveSourceHashes(src SourceHashes) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`INSERT INTO source_files (file, hash, updated_at) VALUES (?, ?, CURRENT_TIMESTAMP)
+				  ON CONFLICT(file) DO UPDATE SET hash = excluded.hash, updated_at = CURRENT_TIMESTAMP`,
+			src.File, src.Hash)
+		if err != nil {
+			return fmt.Errorf("failed to save source file: %w", err)
+		}
+		if _, err := tx.Exec("DELETE FROM source_sections WHERE file = ?", src.File); err != nil {
+			return fmt.Errorf("failed to clear source sections: %w", err)
+		}
+		for heading, hash := range src.Sections {
+			if _, err := tx.Exec("INSERT INTO source_sections (file, heading, hash) VALUES (?, ?, ?)",
+				src.File, heading, hash); err != nil {
+				return fmt.Errorf("failed to save source section: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteSourceHashes forgets the recorded fingerprints of a source file
+func (s *Store) DeleteSourceHashes(file string) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec("DELETE FROM source_sections WHERE file = ?", file); err != nil {
+			return err
+		}
+		_, err := tx.Exec("DELETE FROM source_files WHERE file = ?", file)
+		return err
+	})
+}
+
+// GetSourceFiles returns the paths of all recorded source files
+func (s *Store) GetSourceFiles() ([]string, error) {
+	rows, err := s.DB.Query("SELECT file FROM source_files ORDER BY file ASC")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query source files: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	files := make([]string, 0, 20)
+	for rows.Next() {
+		var file string
+		if err := rows.Scan(&file); err != nil {
+			return nil, fmt.Errorf("failed to scan source file: %w", err)
+		}
+		files = append(files, file)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating source files: %w", err)
+	}
+	return files, nil
+}
+
+// GetFlashcardsByFile returns the active flashcards generated from a file
+func (s *Store) GetFlashcardsByFile(file string) ([]Flashcard, error) {
+	rows, err := s.DB.Query("SELECT "+flashcardColumns+" FROM flashcards WHERE file = ? AND "+activeFlashcards+" ORDER BY id ASC", file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query flashcards by file: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	return scanFlashcards(rows, 20)
+}
+
+// ArchiveFlashcards retires flashcards so they are no longer reviewed, keeping
+// them and their review history in the database
+func (s *Store) ArchiveFlashcards(ids []int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	args := make([]interface{}, 0, len(ids)+1)
+	args = append(args, formatTime(s.now()))
+	for _, id := range ids {
+		args = append(args, id)
+	}
+	// #nosec G202 -- only placeholders (?) are concatenated, never user data
+	_, err := s.DB.Exec("UPDATE flashcards SET archived_at = ?, updated_at = CURRENT_TIMESTAMP WHERE id IN ("+placeholders(len(ids))+")", args...)
+	if err != nil {
+		return fmt.Errorf("failed to archive flashcards: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package store
+
+import (
+	"testing"
+)
+
+func TestSourceHashes(t *testing.T) {
+	store := setupTestDB(t)
+	defer store.Close()
+
+	_, known, err := store.GetSourceHashes("/notes/a.md")
+	if err != nil {
+		t.Fatalf("GetSourceHashes() error = %v", err)
+	}
+	if known {
+		t.Error("Expected an unknown source file")
+	}
+
+	src := SourceHashes{File: "/notes/a.md", Hash: "f1", Sections: map[string]string{"": "p1", "Go": "g1"}}
+	if err := store.SaveSourceHashes(src); err != nil {
+		t.Fatalf("SaveSourceHashes() error = %v", err)
+	}
+
+	// Saving again replaces sections that no longer exist
+	src = SourceHashes{File: "/notes/a.md", Hash: "f2", Sections: map[string]string{"Go": "g2"}}
+	if err := store.SaveSourceHashes(src); err != nil {
+		t.Fatalf("SaveSourceHashes() error = %v", err)
+	}
+
+	got, known, err := store.GetSourceHashes("/notes/a.md")
+	if err != nil {
+		t.Fatalf("GetSourceHashes() error = %v", err)
+	}
+	if !known || got.Hash != "f2" {
+		t.Errorf("Expected recorded hash f2, got %q (known %v)", got.Hash, known)
+	}
+	if len(got.Sections) != 1 || got.Sections["Go"] != "g2" {
+		t.Errorf("Unexpected sections: %v", got.Sections)
+	}
+
+	files, err := store.GetSourceFiles()
+	if err != nil {
+		t.Fatalf("GetSourceFiles() error = %v", err)
+	}
+	if len(files) != 1 || files[0] != "/notes/a.md" {
+		t.Errorf("Unexpected source files: %v", files)
+	}
+
+	if err := store.DeleteSourceHashes("/notes/a.md"); err != nil {
+		t.Fatalf("DeleteSourceHashes() error = %v", err)
+	}
+	if _, known, _ := store.GetSourceHashes("/notes/a.md"); known {
+		t.Error("Expected source file to be forgotten")
+	}
+}
+
+func TestArchiveFlashcards(t *testing.T) {
+	store := setupTestDB(t)
+	defer store.Close()
+
+	for _, fc := range []Flashcard{
+		{File: "/notes/a.md", Heading: "Go", Question: "Q1", Answer: "A1"},
+		{File: "/notes/a.md", Heading: "Rust", Question: "Q2", Answer: "A2"},
+	} {
+		if err := store.InsertFlashcard(fc); err != nil {
+			t.Fatalf("InsertFlashcard() error = %v", err)
+		}
+	}
+
+	cards, err := store.GetFlashcardsByFile("/notes/a.md")
+	if err != nil {
+		t.Fatalf("GetFlashcardsByFile() error = %v", err)
+	}
+	if len(cards) != 2 || cards[0].Heading != "Go" {
+		t.Fatalf("Expected 2 flashcards with headings, got %+v", cards)
+	}
+	if _, err := store.InsertReview(Review{FlashcardID: cards[0].ID, Grade: 3}); err != nil {
+		t.Fatalf("InsertReview() error = %v", err)
+	}
+
+	if err := store.ArchiveFlashcards([]int{cards[0].ID}); err != nil {
+		t.Fatalf("ArchiveFlashcards() error = %v", err)
+	}
+
+	for name, get := range map[string]func() ([]Flashcard, error){
+		"GetAllFlashcards":       store.GetAllFlashcards,
+		"GetFlashcardsForReview": store.GetFlashcardsForReview,
+		"GetFlashcardsByFile":    func() ([]Flashcard, error) { return store.GetFlashcardsByFile("/notes/a.md") },
+	} {
+		active, err := get()
+		if err != nil {
+			t.Fatalf("%s() error = %v", name, err)
+		}
+		if len(active) != 1 || active[0].Heading != "Rust" {
+			t.Errorf("%s() should hide archived flashcards, got %+v", name, active)
+		}
+	}
+
+	reviews, err := store.GetReviewsForFlashcard(cards[0].ID)
+	if err != nil {
+		t.Fatalf("GetReviewsForFlashcard() error = %v", err)
+	}
+	if len(reviews) != 1 {
+		t.Error("Archiving should keep the review history")
+	}
+
+	if err := store.ArchiveFlashcards(nil); err != nil {
+		t.Errorf("ArchiveFlashcards(nil) error = %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+package store
+
+import (
+	"fmt"
+	"time"
+)
+
+// StudyDay counts the flashcards studied on a calendar day, so daily limits
+// hold across sessions
+type StudyDay struct {
+	Day      string // Local date as YYYY-MM-DD
+	NewCards int    // Flashcards studied for the first time
+	Reviews  int    // Reviews of flashcards studied before
+}
+
+// dayKey returns the local calendar date of t
+func dayKey(t time.Time) string {
+	return t.Local().Format("2006-01-02")
+}
+
+// GetStudyDay returns how many flashcards were studied on the day of t
+func (s *Store) GetStudyDay(t time.Time) (StudyDay, error) {
+	day := StudyDay{Day: dayKey(t)}
+	rows, err := s.DB.Query("SELECT new_cards, reviews FROM study_days WHERE day = ?", day.Day)
+	if err != nil {
+		return day, fmt.Errorf("failed to query study day: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	if rows.Next() {
+		if err := rows.Scan(&day.NewCards, &day.Reviews); err != nil {
+			return day, fmt.Errorf("failed to scan study day: %w", err)
+		}
+	}
+	return day, rows.Err()
+}
+
+// countStudied adds a flashcard studied at t to the counts of its day
+func countStudied(db queryExecer, t time.Time, isNew bool) error {
+	newCards, reviews := studiedCounts(isNew)
+	_, err := db.Exec(`INSERT INTO study_days (day, new_cards, reviews) VALUES (?, ?, ?)
+			  ON CONFLICT(day) DO UPDATE SET new_cards = new_cards + excluded.new_cards,
+			  reviews = reviews + excluded.reviews`, dayKey(t), newCards, reviews)
+	if err != nil {
+		return fmt.Errorf("failed to count studied flashcard: %w", err)
+	}
+	return nil
+}
+
+// studiedCounts returns the new flashcard and review counts of one studied flashcard
+func studiedCounts(isNew bool) (newCards, reviews int) {
+	if isNew {
+		return 1, 0
+	}
+	return 0, 1
+}
+
+// uncountStudied removes a flashcard studied at t from the counts of its day
+func uncountStudied(db queryExecer, t time.Time, isNew bool) error {
+	newCards, reviews := studiedCounts(isNew)
+	_, err := db.Exec(`UPDATE study_days SET new_cards = MAX(new_cards - ?, 0), reviews = MAX(reviews - ?, 0)
+			  WHERE day = ?`, newCards, reviews, dayKey(t))
+	if err != nil {
+		return fmt.Errorf("failed to uncount studied flashcard: %w", err)
+	}
+	return nil
+}
+
+// wasNew reports whether the stored flashcard has never been reviewed
+func wasNew(db queryExecer, id int) (bool, error) {
+	rows, err := db.Query("SELECT "+newFlashcard+" FROM flashcards WHERE id = ?", id)
+	if err != nil {
+		return false, fmt.Errorf("failed to query flashcard: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	isNew := false
+	if rows.Next() {
+		if err := rows.Scan(&isNew); err != nil {
+			return false, fmt.Errorf("failed to scan flashcard: %w", err)
+		}
+	}
+	return isNew, rows.Err()
+}
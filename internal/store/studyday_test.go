@@ -0,0 +1,58 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetStudyDay(t *testing.T) {
+	store := setupTestDB(t)
+	defer store.Close()
+
+	for _, q := range []string{"Q1", "Q2"} {
+		if err := store.InsertFlashcard(Flashcard{File: "/test/1.md", Question: q, Answer: "A"}); err != nil {
+			t.Fatalf("InsertFlashcard() error = %v", err)
+		}
+	}
+	cards, err := store.GetAllFlashcards()
+	if err != nil {
+		t.Fatalf("GetAllFlashcards() error = %v", err)
+	}
+
+	now := time.Now()
+	yesterday := now.AddDate(0, 0, -1)
+	review := func(fc Flashcard, at time.Time) Flashcard {
+		t.Helper()
+		fc.LastReviewedAt = at
+		fc.Repetitions++
+		fc.DueAt = DueAfter(at, 1)
+		if _, err := store.RecordReview(fc, Review{ReviewedAt: at, Grade: 3}); err != nil {
+			t.Fatalf("RecordReview() error = %v", err)
+		}
+		return fc
+	}
+	// The first card is new yesterday and reviewed today, the second is new today
+	review(review(cards[0], yesterday), now)
+	review(cards[1], now)
+
+	day, err := store.GetStudyDay(now)
+	if err != nil {
+		t.Fatalf("GetStudyDay() error = %v", err)
+	}
+	if day.Day != now.Format("2006-01-02") || day.NewCards != 1 || day.Reviews != 1 {
+		t.Errorf("Unexpected study day %+v", day)
+	}
+
+	day, err = store.GetStudyDay(yesterday)
+	if err != nil {
+		t.Fatalf("GetStudyDay() error = %v", err)
+	}
+	if day.NewCards != 1 || day.Reviews != 0 {
+		t.Errorf("Unexpected study day for yesterday %+v", day)
+	}
+
+	day, err = store.GetStudyDay(now.AddDate(0, 0, 1))
+	if err != nil || day.NewCards != 0 || day.Reviews != 0 {
+		t.Errorf("Expected an empty study day, got %+v (%v)", day, err)
+	}
+}
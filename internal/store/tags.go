@@ -0,0 +1,45 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NormalizeTag returns the canonical form of a tag: lower case, without a
+// leading '#', with spaces and commas replaced by dashes
+// It returns "" for tags that are blank
+func NormalizeTag(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(tag), "#")))
+	return strings.Join(strings.FieldsFunc(tag, func(r rune) bool {
+		return r == ' ' || r == '\t' || r == ',' || r == '\n'
+	}), "-")
+}
+
+// NormalizeTags normalizes tags, dropping blanks and duplicates while keeping their order
+func NormalizeTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = NormalizeTag(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// addTags attaches tags to a flashcard, creating the tags that do not exist yet
+func addTags(db queryExecer, flashcardID int, tags []string) error {
+	for _, tag := range NormalizeTags(tags) {
+		if _, err := db.Exec("INSERT OR IGNORE INTO tags (name) VALUES (?)", tag); err != nil {
+			return fmt.Errorf("failed to save tag: %w", err)
+		}
+		if _, err := db.Exec(`INSERT OR IGNORE INTO flashcard_tags (flashcard_id, tag_id)
+				  SELECT ?, id FROM tags WHERE name = ?`, flashcardID, tag); err != nil {
+			return fmt.Errorf("failed to tag flashcard: %w", err)
+		}
+	}
+	return nil
+}
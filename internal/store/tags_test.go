@@ -0,0 +1,71 @@
+package store
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFlashcardTags(t *testing.T) {
+	store := setupTestDB(t)
+	defer store.Close()
+
+	cards := []Flashcard{
+		{File: "/notes/go.md", Question: "Q1", Answer: "A1", Tags: []string{"Go", "#concurrency", "go", " "}},
+		{File: "/notes/go.md", Question: "Q2", Answer: "A2", Tags: []string{"Error Handling", "go"}},
+		{File: "/notes/go.md", Question: "Q3", Answer: "A3"},
+	}
+	for _, fc := range cards {
+		if err := store.InsertFlashcard(fc); err != nil {
+			t.Fatalf("InsertFlashcard() error = %v", err)
+		}
+	}
+
+	got, err := store.GetAllFlashcards()
+	if err != nil {
+		t.Fatalf("GetAllFlashcards() error = %v", err)
+	}
+	expected := map[string][]string{
+		"Q1": {"concurrency", "go"},
+		"Q2": {"error-handling", "go"},
+		"Q3": nil,
+	}
+	for _, fc := range got {
+		if !reflect.DeepEqual(fc.Tags, expected[fc.Question]) {
+			t.Errorf("%s: expected tags %v, got %v", fc.Question, expected[fc.Question], fc.Tags)
+		}
+	}
+
+	var tagCount int
+	if err := store.DB.QueryRow("SELECT COUNT(*) FROM tags").Scan(&tagCount); err != nil {
+		t.Fatalf("Failed to count tags: %v", err)
+	}
+	if tagCount != 3 {
+		t.Errorf("Expected 3 distinct tags, got %d", tagCount)
+	}
+
+	if err := store.DeleteFlashcard(got[0].ID); err != nil {
+		t.Fatalf("DeleteFlashcard() error = %v", err)
+	}
+	var links int
+	if err := store.DB.QueryRow("SELECT COUNT(*) FROM flashcard_tags WHERE flashcard_id = ?", got[0].ID).Scan(&links); err != nil {
+		t.Fatalf("Failed to count tag links: %v", err)
+	}
+	if links != 0 {
+		t.Errorf("Expected tag links to be deleted with the flashcard, got %d", links)
+	}
+}
+
+func TestNormalizeTag(t *testing.T) {
+	tests := map[string]string{
+		"Go":               "go",
+		"#rust":            "rust",
+		"  Error Handling": "error-handling",
+		"a,b":              "a-b",
+		" # ":              "",
+	}
+	for input, expected := range tests {
+		if got := NormalizeTag(input); got != expected {
+			t.Errorf("NormalizeTag(%q) = %q, expected %q", input, got, expected)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"catv/internal/store"
 	"catv/internal/tui/components"
@@ -86,7 +87,7 @@ type AdminModel struct {
 	// form fields
 	questionInput textinput.Model
 	answerInput   textinput.Model
-	revisitInput  textinput.Model // days until next review
+	dueInput      textinput.Model // days until next review
 
 	status components.StatusMessage
 
@@ -99,15 +100,15 @@ func NewAdminModel(storeRef *store.Store, flashcards []store.Flashcard) *AdminMo
 	q.Focus()
 	a := textinput.New()
 	a.Placeholder = "Answer"
-	r := textinput.New()
-	r.Placeholder = "Days (e.g. 7)"
+	d := textinput.New()
+	d.Placeholder = "Days (e.g. 7)"
 
 	// Setup table columns
 	columns := []table.Column{
 		{Title: "ID", Width: 6},
 		{Title: "Question", Width: 40},
 		{Title: "Answer", Width: 30},
-		{Title: "Revisit In", Width: 12},
+		{Title: "Due", Width: 12},
 	}
 
 	// Convert flashcards to table rows
@@ -130,7 +131,7 @@ func NewAdminModel(storeRef *store.Store, flashcards []store.Flashcard) *AdminMo
 		table:         t,
 		questionInput: q,
 		answerInput:   a,
-		revisitInput:  r,
+		dueInput:      d,
 		storeRef:      storeRef,
 		help:          help.New(),
 		keys:          adminKeys,
@@ -139,18 +140,32 @@ func NewAdminModel(storeRef *store.Store, flashcards []store.Flashcard) *AdminMo
 
 // makeTableRows converts flashcards to table rows
 func makeTableRows(flashcards []store.Flashcard, columns []table.Column) []table.Row {
+	now := time.Now()
 	rows := make([]table.Row, len(flashcards))
 	for i, fc := range flashcards {
 		rows[i] = table.Row{
 			fmt.Sprintf("%d", fc.ID),
 			truncate(fc.Question, columns[1].Width),
 			truncate(fc.Answer, columns[2].Width),
-			fmt.Sprintf("%d days", fc.RevisitIn),
+			formatDue(fc, now),
 		}
 	}
 	return rows
 }
 
+// formatDue describes when a flashcard is due relative to now
+func formatDue(fc store.Flashcard, now time.Time) string {
+	days := fc.DaysUntilDue(now)
+	switch {
+	case fc.IsDue(now):
+		return "due"
+	case days <= 0:
+		return "later today"
+	default:
+		return pluralDays(days)
+	}
+}
+
 func (m *AdminModel) Init() tea.Cmd { return nil }
 
 func (m *AdminModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -163,13 +178,13 @@ func (m *AdminModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		frameWidth := layout.CalculateContentWidth(m.width)
 
 		// Calculate column widths using layout helper
-		idWidth, questionWidth, answerWidth, revisitInWidth := layout.CalculateTableColumnWidths(frameWidth)
+		idWidth, questionWidth, answerWidth, dueWidth := layout.CalculateTableColumnWidths(frameWidth)
 
 		m.table.SetColumns([]table.Column{
 			{Title: "ID", Width: idWidth},
 			{Title: "Question", Width: questionWidth},
 			{Title: "Answer", Width: answerWidth},
-			{Title: "Revisit In", Width: revisitInWidth},
+			{Title: "Due", Width: dueWidth},
 		})
 
 		// Calculate table height using layout helper
@@ -317,7 +332,7 @@ func (m *AdminModel) handleBulkResetConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd)
 		return m, nil
 	}
 	if msg.String() == keys.Y {
-		m.bulkResetRevisitIn()
+		m.bulkResetDueDates()
 		return m, nil
 	}
 	if msg.String() == keys.N {
@@ -365,7 +380,7 @@ func (m *AdminModel) View() string {
 		formContent := components.RenderFormFields(
 			components.FormField{Label: "Question:", Input: m.questionInput},
 			components.FormField{Label: "Answer:", Input: m.answerInput},
-			components.FormField{Label: "Revisit (days):", Input: m.revisitInput},
+			components.FormField{Label: "Due in (days):", Input: m.dueInput},
 		)
 		mainContent = formContent + statusBar
 		exitMsg = theme.HelpStyle.Render("tab: Next Field • Enter: Confirm • esc: Cancel")
@@ -375,7 +390,7 @@ func (m *AdminModel) View() string {
 		formContent := components.RenderFormFields(
 			components.FormField{Label: "Question:", Input: m.questionInput},
 			components.FormField{Label: "Answer:", Input: m.answerInput},
-			components.FormField{Label: "Revisit (days):", Input: m.revisitInput},
+			components.FormField{Label: "Due in (days):", Input: m.dueInput},
 		)
 		mainContent = fmt.Sprintf("%s\n\n%s%s", title, formContent, statusBar)
 		exitMsg = theme.HelpStyle.Render("tab: Next Field • Enter: Confirm • esc: Cancel")
@@ -386,7 +401,7 @@ func (m *AdminModel) View() string {
 		exitMsg = theme.HelpStyle.Render("y: Yes • n: No • esc: Cancel")
 
 	case adminConfirmBulkReset:
-		warning := theme.ErrorStyle.Render(fmt.Sprintf("Set RevisitIn to 0 for ALL %d flashcards?", len(m.flashcards)))
+		warning := theme.ErrorStyle.Render(fmt.Sprintf("Make ALL %d flashcards due now?", len(m.flashcards)))
 		info := theme.InfoStyle.Render("This will make all flashcards due for immediate review.")
 		mainContent = fmt.Sprintf("%s\n\n%s\n", warning, info)
 		exitMsg = theme.HelpStyle.Render("y: Yes • n: No • esc: Cancel")
@@ -412,21 +427,21 @@ func truncate(s string, n int) string {
 func (m *AdminModel) resetForm() {
 	m.questionInput.SetValue("")
 	m.answerInput.SetValue("")
-	m.revisitInput.SetValue("")
+	m.dueInput.SetValue("")
 	m.status.Clear()
 	m.questionInput.Focus()
 	m.answerInput.Blur()
-	m.revisitInput.Blur()
+	m.dueInput.Blur()
 }
 
 func (m *AdminModel) loadSelectedIntoForm() {
 	fc := m.flashcards[m.selected]
 	m.questionInput.SetValue(fc.Question)
 	m.answerInput.SetValue(fc.Answer)
-	m.revisitInput.SetValue(strconv.Itoa(fc.RevisitIn))
+	m.dueInput.SetValue(strconv.Itoa(max(fc.DaysUntilDue(time.Now()), 0)))
 	m.questionInput.Focus()
 	m.answerInput.Blur()
-	m.revisitInput.Blur()
+	m.dueInput.Blur()
 }
 
 func (m *AdminModel) updateInputs(msg tea.Msg) {
@@ -436,15 +451,15 @@ func (m *AdminModel) updateInputs(msg tea.Msg) {
 		m.questionInput, _ = m.questionInput.Update(msg)
 	case m.answerInput.Focused():
 		m.answerInput, _ = m.answerInput.Update(msg)
-	case m.revisitInput.Focused():
-		m.revisitInput, _ = m.revisitInput.Update(msg)
+	case m.dueInput.Focused():
+		m.dueInput, _ = m.dueInput.Update(msg)
 	}
 }
 
-func (m *AdminModel) parseRevisitDays() (int, error) {
-	val := strings.TrimSpace(m.revisitInput.Value())
+func (m *AdminModel) parseDueDays() (int, error) {
+	val := strings.TrimSpace(m.dueInput.Value())
 	if val == "" {
-		return 0, fmt.Errorf("revisit days required")
+		return 0, fmt.Errorf("days until due required")
 	}
 	d, err := strconv.Atoi(val)
 	if err != nil || d < 0 {
@@ -454,12 +469,12 @@ func (m *AdminModel) parseRevisitDays() (int, error) {
 }
 
 func (m *AdminModel) createFlashcard() {
-	days, err := m.parseRevisitDays()
+	days, err := m.parseDueDays()
 	if err != nil {
 		m.status.SetError(err.Error())
 		return
 	}
-	fc := store.Flashcard{Question: m.questionInput.Value(), Answer: m.answerInput.Value(), File: "manual", RevisitIn: days}
+	fc := store.Flashcard{Question: m.questionInput.Value(), Answer: m.answerInput.Value(), File: "manual", DueAt: store.DueAfter(time.Now(), days)}
 	if err := m.storeRef.InsertFlashcard(fc); err != nil {
 		m.status.SetError(err.Error())
 		return
@@ -470,7 +485,7 @@ func (m *AdminModel) createFlashcard() {
 }
 
 func (m *AdminModel) updateFlashcard() {
-	days, err := m.parseRevisitDays()
+	days, err := m.parseDueDays()
 	if err != nil {
 		m.status.SetError(err.Error())
 		return
@@ -478,7 +493,7 @@ func (m *AdminModel) updateFlashcard() {
 	fc := m.flashcards[m.selected]
 	fc.Question = m.questionInput.Value()
 	fc.Answer = m.answerInput.Value()
-	fc.RevisitIn = days
+	fc.DueAt = store.DueAfter(time.Now(), days)
 	if err := m.storeRef.UpdateFlashcardFull(fc); err != nil {
 		m.status.SetError(err.Error())
 		return
@@ -525,11 +540,12 @@ func (m *AdminModel) reload() {
 	}
 }
 
-// bulkResetRevisitIn resets RevisitIn to 0 for all flashcards
-func (m *AdminModel) bulkResetRevisitIn() {
+// bulkResetDueDates makes all flashcards due immediately
+func (m *AdminModel) bulkResetDueDates() {
+	now := time.Now()
 	count := 0
 	for _, fc := range m.flashcards {
-		fc.RevisitIn = 0
+		fc.DueAt = now
 		if err := m.storeRef.UpdateFlashcard(fc); err != nil {
 			m.status.SetError(fmt.Sprintf("Error updating flashcard %d: %v", fc.ID, err))
 			m.view = adminList
@@ -537,27 +553,27 @@ func (m *AdminModel) bulkResetRevisitIn() {
 		}
 		count++
 	}
-	m.status.SetSuccess(fmt.Sprintf("Reset RevisitIn to 0 for %d flashcards", count))
+	m.status.SetSuccess(fmt.Sprintf("Made %d flashcards due now", count))
 	m.reload()
 	m.view = adminList
 }
 
-// cycleFocus switches focus Question -> Answer -> Revisit -> Question
+// cycleFocus switches focus Question -> Answer -> Due -> Question
 func (m *AdminModel) cycleFocus() {
 	if m.questionInput.Focused() {
 		m.questionInput.Blur()
 		m.answerInput.Focus()
-		m.revisitInput.Blur()
+		m.dueInput.Blur()
 		return
 	}
 	if m.answerInput.Focused() {
 		m.answerInput.Blur()
-		m.revisitInput.Focus()
+		m.dueInput.Focus()
 		m.questionInput.Blur()
 		return
 	}
-	// default or revisit focused -> go to question
-	m.revisitInput.Blur()
+	// default or due focused -> go to question
+	m.dueInput.Blur()
 	m.questionInput.Focus()
 	m.answerInput.Blur()
 }
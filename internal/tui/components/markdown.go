@@ -0,0 +1,113 @@
+package components
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/glamour/styles"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// markdownMarkers are the character sequences that make text worth rendering as
+// markdown; anything else is shown as plain text
+var markdownMarkers = []string{"\n", "`", "**", "__", "# ", "- ", "* ", "1. ", "> ", "[", "|"}
+
+// Markdown renders flashcard text as markdown with syntax-highlighted code blocks.
+// Rendered output is cached, since views are redrawn on every timer tick.
+type Markdown struct {
+	renderers map[int]*glamour.TermRenderer // Renderer per wrap width
+	cache     map[markdownKey]string
+}
+
+type markdownKey struct {
+	text  string
+	width int
+}
+
+// NewMarkdown creates a markdown renderer using the dark glamour theme.
+func NewMarkdown() *Markdown {
+	return &Markdown{
+		renderers: make(map[int]*glamour.TermRenderer),
+		cache:     make(map[markdownKey]string),
+	}
+}
+
+// Render returns text rendered as markdown, wrapped to width.
+// Plain one-line text is returned unchanged, and the rendered lines are padded
+// to a common width so a centered frame keeps code blocks and lists aligned.
+// If rendering fails the text is returned as is.
+func (m *Markdown) Render(text string, width int) string {
+	if !IsMarkdown(text) {
+		return text
+	}
+	key := markdownKey{text, width}
+	if out, ok := m.cache[key]; ok {
+		return out
+	}
+
+	out := text
+	if r, err := m.renderer(width); err == nil {
+		if rendered, err := r.Render(text); err == nil {
+			out = trimBlankLines(rendered)
+			out = lipgloss.NewStyle().Width(lipgloss.Width(out)).Render(out)
+		}
+	}
+	m.cache[key] = out
+	return out
+}
+
+// renderer returns the glamour renderer for a wrap width, creating it if needed
+func (m *Markdown) renderer(width int) (*glamour.TermRenderer, error) {
+	if r, ok := m.renderers[width]; ok {
+		return r, nil
+	}
+	style := styles.DarkStyleConfig
+	// The surrounding frame already has padding
+	noMargin := uint(0)
+	style.Document.Margin = &noMargin
+	style.Document.BlockPrefix = ""
+	style.Document.BlockSuffix = ""
+	style.CodeBlock.Margin = &noMargin
+	r, err := glamour.NewTermRenderer(
+		glamour.WithStyles(style),
+		glamour.WithWordWrap(max(width, 20)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	m.renderers[width] = r
+	return r, nil
+}
+
+// IsMarkdown reports whether text contains markdown formatting or several lines.
+func IsMarkdown(text string) bool {
+	for _, marker := range markdownMarkers {
+		if strings.Contains(text, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// trailingPadding matches the styled spaces glamour pads every line with
+var trailingPadding = regexp.MustCompile(`(?:\x1b\[[0-9;]*m| )+$`)
+
+// trimBlankLines removes the padding at the end of each line and the blank
+// lines glamour adds around the document
+func trimBlankLines(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if trimmed := trailingPadding.ReplaceAllString(line, ""); trimmed != line {
+			lines[i] = trimmed + ansi.ResetStyle
+		}
+	}
+	for len(lines) > 0 && strings.TrimSpace(ansi.Strip(lines[0])) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(ansi.Strip(lines[len(lines)-1])) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,64 @@
+package components
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestMarkdownRender(t *testing.T) {
+	md := NewMarkdown()
+
+	if got := md.Render("Paris", 40); got != "Paris" {
+		t.Errorf("Expected plain text to be unchanged, got %q", got)
+	}
+
+	answer := "Use a **buffered** channel:\n\n```go\nch := make(chan int, 3)\n```\n\n- one\n- two"
+	out := md.Render(answer, 40)
+	plain := ansi.Strip(out)
+	for _, want := range []string{"buffered", "ch := make(chan int, 3)", "• one", "• two"} {
+		if !strings.Contains(plain, want) {
+			t.Errorf("Expected rendered markdown to contain %q, got %q", want, plain)
+		}
+	}
+	if strings.Contains(plain, "**") || strings.Contains(plain, "```") {
+		t.Errorf("Expected markdown syntax to be rendered, got %q", plain)
+	}
+	if strings.HasPrefix(plain, "\n") || strings.HasSuffix(plain, "\n") {
+		t.Errorf("Expected surrounding blank lines to be trimmed, got %q", plain)
+	}
+
+	// Lines share a width so a centered frame keeps the block aligned
+	width := lipgloss.Width(out)
+	for _, line := range strings.Split(out, "\n") {
+		if lipgloss.Width(line) != width {
+			t.Errorf("Expected every line to be %d wide, got %d: %q", width, lipgloss.Width(line), ansi.Strip(line))
+		}
+	}
+	if width > 40 {
+		t.Errorf("Expected output to wrap at 40 columns, got %d", width)
+	}
+
+	if md.Render(answer, 40) != out {
+		t.Error("Expected cached output for the same text and width")
+	}
+}
+
+func TestIsMarkdown(t *testing.T) {
+	tests := map[string]bool{
+		"Paris":                      false,
+		"What is 2+2?":               false,
+		"Use `make`":                 true,
+		"line one\nline two":         true,
+		"**bold**":                   true,
+		"- item":                     true,
+		"See [docs](https://go.dev)": true,
+	}
+	for text, expected := range tests {
+		if got := IsMarkdown(text); got != expected {
+			t.Errorf("IsMarkdown(%q) = %v, expected %v", text, got, expected)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"catv/internal/store"
 	"catv/internal/tui/keys"
 	"catv/internal/tui/layout"
 	"catv/internal/tui/theme"
@@ -13,30 +14,90 @@ import (
 
 const (
 	allFilesOption = "📚 All Files"
+	allDecksOption = "📚 All Decks"
+	tagPrefix      = "#"
 )
 
-// FileSelectorModel represents the file selection screen
+// FileSelectorModel represents the file selection screen, or the deck and tag
+// selection screen when created with NewDeckSelectorModel
 type FileSelectorModel struct {
-	files         []string        // List of file paths
-	selected      map[string]bool // Map of selected files
-	cursor        int             // Current cursor position
-	width         int             // Terminal width
-	height        int             // Terminal height
-	confirmed     bool            // Whether user confirmed selection
-	selectedFiles []string        // Final selected files after confirmation
+	files         []selectorItem          // List of files, or decks followed by tags
+	allOption     string                  // Option that toggles every file or deck
+	counts        map[selectorItem]string // New, due and total counts shown next to decks and tags
+	deckMode      bool                    // Whether decks and tags are listed instead of files
+	selected      map[selectorItem]bool   // Map of selected files
+	cursor        int                     // Current cursor position
+	width         int                     // Terminal width
+	height        int                     // Terminal height
+	confirmed     bool                    // Whether user confirmed selection
+	selectedFiles []selectorItem          // Final selected files after confirmation
+}
+
+// selectorItem is a file, deck or tag listed in the selector
+// A deck and a tag may have the same name, so the kind is part of the item
+type selectorItem struct {
+	name string // File path, deck name, or tag name without its "#"
+	tag  bool
+}
+
+// label is how the item is listed, with a "#" before tags
+func (i selectorItem) label() string {
+	if i.tag {
+		return tagPrefix + i.name
+	}
+	return i.name
 }
 
 // NewFileSelectorModel creates a new file selector model
 func NewFileSelectorModel(files []string) *FileSelectorModel {
-	selected := make(map[string]bool)
+	selected := make(map[selectorItem]bool)
 	// Add "All Files" option at the beginning
-	allFiles := append([]string{allFilesOption}, files...)
+	allFiles := []selectorItem{{name: allFilesOption}}
+	for _, f := range files {
+		allFiles = append(allFiles, selectorItem{name: f})
+	}
 
 	return &FileSelectorModel{
-		files:    allFiles,
-		selected: selected,
-		cursor:   0,
+		files:     allFiles,
+		allOption: allFilesOption,
+		selected:  selected,
+		cursor:    0,
+	}
+}
+
+// NewDeckSelectorModel creates a selector for decks and tags with their due counts;
+// the cards reviewed are those in the selected decks that have a selected tag
+func NewDeckSelectorModel(decks, tags []store.GroupCount) *FileSelectorModel {
+	items := []selectorItem{{name: allDecksOption}}
+	counts := make(map[selectorItem]string, len(decks)+len(tags))
+	for _, d := range decks {
+		item := selectorItem{name: d.Name}
+		items = append(items, item)
+		counts[item] = groupCountLabel(d)
 	}
+	for _, t := range tags {
+		item := selectorItem{name: t.Name, tag: true}
+		items = append(items, item)
+		counts[item] = groupCountLabel(t)
+	}
+
+	return &FileSelectorModel{
+		files:     items,
+		allOption: allDecksOption,
+		counts:    counts,
+		deckMode:  true,
+		selected:  make(map[selectorItem]bool),
+	}
+}
+
+// groupCountLabel describes how many new and review cards of a deck or tag are due
+func groupCountLabel(g store.GroupCount) string {
+	return fmt.Sprintf("%d new · %d due · %d cards", g.New, g.Due, g.Total)
+}
+
+// isAll reports whether item is the option toggling every file or deck
+func (m *FileSelectorModel) isAll(item selectorItem) bool {
+	return item == selectorItem{name: m.allOption}
 }
 
 func (m *FileSelectorModel) Init() tea.Cmd {
@@ -54,7 +115,7 @@ func (m *FileSelectorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case keys.CtrlC, keys.Q:
 			// User quit - return empty selection
 			m.confirmed = true
-			m.selectedFiles = []string{}
+			m.selectedFiles = []selectorItem{}
 			return m, tea.Quit
 
 		case keys.Up, keys.K:
@@ -69,16 +130,16 @@ func (m *FileSelectorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		case keys.Space: // Spacebar to toggle selection
 			currentFile := m.files[m.cursor]
-			if currentFile == allFilesOption {
+			if m.isAll(currentFile) {
 				// Toggle all files
 				allSelected := m.areAllFilesSelected()
 				if allSelected {
 					// Deselect all
-					m.selected = make(map[string]bool)
+					m.selected = make(map[selectorItem]bool)
 				} else {
-					// Select all
+					// Select all; tags are left as they are
 					for _, f := range m.files {
-						if f != allFilesOption {
+						if !m.isAll(f) && !f.tag {
 							m.selected[f] = true
 						}
 					}
@@ -114,7 +175,11 @@ func (m *FileSelectorModel) View() string {
 		Bold(true).
 		Foreground(lipgloss.Color(theme.ColorPrimary)).
 		Padding(1, 0)
-	title := titleWithPadding.Render("Welcome 👋 please select file(s) to get started!")
+	prompt := "Welcome 👋 please select file(s) to get started!"
+	if m.deckMode {
+		prompt = "Welcome 👋 please select deck(s) or #tag(s) to get started!"
+	}
+	title := titleWithPadding.Render(prompt)
 	centeredTitle := lipgloss.NewStyle().Width(width).Align(lipgloss.Center).Render(title)
 	s.WriteString(centeredTitle)
 	s.WriteString("\n")
@@ -145,7 +210,7 @@ func (m *FileSelectorModel) View() string {
 		checkbox := theme.UncheckedStyle.Render("☐")
 		itemStyle := theme.UnselectedStyle
 
-		if file == allFilesOption {
+		if m.isAll(file) {
 			// Check if all files are selected
 			if m.areAllFilesSelected() {
 				checkbox = theme.CheckedStyle.Render("☑")
@@ -160,32 +225,53 @@ func (m *FileSelectorModel) View() string {
 		}
 
 		// Truncate long file paths for display
-		displayFile := file
+		displayFile := file.label()
 		maxWidth := width - 10
 		if maxWidth < 20 {
 			maxWidth = 20
 		}
+		count := m.counts[file]
+		if count != "" {
+			maxWidth -= len(count) + 1
+			if maxWidth < 10 {
+				maxWidth = 10
+			}
+		}
 		if len(displayFile) > maxWidth {
 			displayFile = "..." + displayFile[len(displayFile)-maxWidth+3:]
 		}
 
-		s.WriteString(fmt.Sprintf("%s %s %s\n", cursor, checkbox, itemStyle.Render(displayFile)))
+		line := itemStyle.Render(displayFile)
+		if count != "" {
+			line += " " + theme.InfoStyle.Render(count)
+		}
+		s.WriteString(fmt.Sprintf("%s %s %s\n", cursor, checkbox, line))
 	}
 
 	// Show scroll indicator if needed
 	if len(m.files) > maxVisible {
-		s.WriteString(theme.InfoStyle.Render(fmt.Sprintf("\n(Showing %d-%d of %d files)",
+		noun := "files"
+		if m.deckMode {
+			noun = "items"
+		}
+		s.WriteString(theme.InfoStyle.Render(fmt.Sprintf("\n(Showing %d-%d of %d %s)",
 			scrollOffset+1,
 			min(scrollOffset+maxVisible, len(m.files)),
-			len(m.files))))
+			len(m.files), noun)))
 		s.WriteString("\n")
 	}
 
 	// Count selected files
-	selectedCount := len(m.getSelectedFiles())
-	if selectedCount > 0 {
+	selected := m.getSelectedFiles()
+	if m.deckMode {
+		decks, tags := splitSelection(selected)
+		if len(selected) > 0 {
+			s.WriteString("\n")
+			s.WriteString(theme.SuccessStyle.Render(fmt.Sprintf("Selected: %d deck(s), %d tag(s)", len(decks), len(tags))))
+		}
+	} else if len(selected) > 0 {
 		s.WriteString("\n")
-		s.WriteString(theme.SuccessStyle.Render(fmt.Sprintf("Selected: %d file(s)", selectedCount)))
+		s.WriteString(theme.SuccessStyle.Render(fmt.Sprintf("Selected: %d file(s)", len(selected))))
 	}
 
 	// Create frame with border using layout helper
@@ -200,24 +286,29 @@ func (m *FileSelectorModel) View() string {
 	return layout.CenterContent(m.width, m.height, frame.Render(s.String())+"\n"+exitMsg)
 }
 
-// areAllFilesSelected checks if all files (excluding "All Files" option) are selected
+// areAllFilesSelected checks if all files or decks (excluding the "All" option) are selected
 func (m *FileSelectorModel) areAllFilesSelected() bool {
 	if len(m.selected) == 0 {
 		return false
 	}
+	found := false
 	for _, f := range m.files {
-		if f != allFilesOption && !m.selected[f] {
+		if m.isAll(f) || f.tag {
+			continue
+		}
+		if !m.selected[f] {
 			return false
 		}
+		found = true
 	}
-	return true
+	return found
 }
 
-// getSelectedFiles returns the list of selected file paths
-func (m *FileSelectorModel) getSelectedFiles() []string {
-	var selected []string
+// getSelectedFiles returns the selected files, or decks and tags
+func (m *FileSelectorModel) getSelectedFiles() []selectorItem {
+	var selected []selectorItem
 	for _, f := range m.files {
-		if f != allFilesOption && m.selected[f] {
+		if !m.isAll(f) && m.selected[f] {
 			selected = append(selected, f)
 		}
 	}
@@ -226,5 +317,33 @@ func (m *FileSelectorModel) getSelectedFiles() []string {
 
 // GetSelectedFiles returns the final selected files after confirmation
 func (m *FileSelectorModel) GetSelectedFiles() []string {
-	return m.selectedFiles
+	files := make([]string, len(m.selectedFiles))
+	for i, f := range m.selectedFiles {
+		files[i] = f.name
+	}
+	return files
+}
+
+// GetSelectedDecks returns the decks selected in a deck selector after confirmation
+func (m *FileSelectorModel) GetSelectedDecks() []string {
+	decks, _ := splitSelection(m.selectedFiles)
+	return decks
+}
+
+// GetSelectedTags returns the tags, without their "#", selected in a deck selector after confirmation
+func (m *FileSelectorModel) GetSelectedTags() []string {
+	_, tags := splitSelection(m.selectedFiles)
+	return tags
+}
+
+// splitSelection separates selected decks from selected tags
+func splitSelection(items []selectorItem) (decks, tags []string) {
+	for _, item := range items {
+		if item.tag {
+			tags = append(tags, item.name)
+		} else {
+			decks = append(decks, item.name)
+		}
+	}
+	return decks, tags
 }
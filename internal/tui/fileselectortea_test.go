@@ -1,7 +1,9 @@
 package tui
 
 import (
+	"catv/internal/store"
 	"catv/internal/tui/keys"
+	"strings"
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -22,8 +24,8 @@ func TestNewFileSelectorModel(t *testing.T) {
 	}
 
 	// First item should be "All Files"
-	if model.files[0] != allFilesOption {
-		t.Errorf("NewFileSelectorModel() first file = %q, want %q", model.files[0], allFilesOption)
+	if model.files[0].name != allFilesOption {
+		t.Errorf("NewFileSelectorModel() first file = %q, want %q", model.files[0].name, allFilesOption)
 	}
 
 	// Cursor should start at 0
@@ -144,7 +146,7 @@ func TestFileSelectorModel_Update_ToggleSelection(t *testing.T) {
 	m := updatedModel.(*FileSelectorModel)
 
 	// First file should now be selected
-	if !m.selected["/file1.md"] {
+	if !m.selected[selectorItem{name: "/file1.md"}] {
 		t.Error("Update(Space) should select the file at cursor")
 	}
 
@@ -152,7 +154,7 @@ func TestFileSelectorModel_Update_ToggleSelection(t *testing.T) {
 	updatedModel2, _ := m.Update(msg)
 	m2 := updatedModel2.(*FileSelectorModel)
 
-	if m2.selected["/file1.md"] {
+	if m2.selected[selectorItem{name: "/file1.md"}] {
 		t.Error("Update(Space) should deselect the file at cursor")
 	}
 }
@@ -168,7 +170,7 @@ func TestFileSelectorModel_Update_ToggleAllFiles(t *testing.T) {
 	m := updatedModel.(*FileSelectorModel)
 
 	// Both files should be selected
-	if !m.selected["/file1.md"] || !m.selected["/file2.md"] {
+	if !m.selected[selectorItem{name: "/file1.md"}] || !m.selected[selectorItem{name: "/file2.md"}] {
 		t.Error("Update(Space on All Files) should select all files")
 	}
 
@@ -176,7 +178,7 @@ func TestFileSelectorModel_Update_ToggleAllFiles(t *testing.T) {
 	updatedModel2, _ := m.Update(msg)
 	m2 := updatedModel2.(*FileSelectorModel)
 
-	if m2.selected["/file1.md"] || m2.selected["/file2.md"] {
+	if m2.selected[selectorItem{name: "/file1.md"}] || m2.selected[selectorItem{name: "/file2.md"}] {
 		t.Error("Update(Space on All Files) should deselect all files")
 	}
 }
@@ -184,7 +186,7 @@ func TestFileSelectorModel_Update_ToggleAllFiles(t *testing.T) {
 func TestFileSelectorModel_Update_Confirm(t *testing.T) {
 	files := []string{"/file1.md", "/file2.md"}
 	model := NewFileSelectorModel(files)
-	model.selected["/file1.md"] = true
+	model.selected[selectorItem{name: "/file1.md"}] = true
 
 	msg := tea.KeyMsg{Type: tea.KeyEnter}
 	updatedModel, cmd := model.Update(msg)
@@ -280,13 +282,13 @@ func TestFileSelectorModel_areAllFilesSelected(t *testing.T) {
 	}
 
 	// Select one file
-	model.selected["/file1.md"] = true
+	model.selected[selectorItem{name: "/file1.md"}] = true
 	if model.areAllFilesSelected() {
 		t.Error("areAllFilesSelected() should return false when not all files selected")
 	}
 
 	// Select all files
-	model.selected["/file2.md"] = true
+	model.selected[selectorItem{name: "/file2.md"}] = true
 	if !model.areAllFilesSelected() {
 		t.Error("areAllFilesSelected() should return true when all files selected")
 	}
@@ -303,8 +305,8 @@ func TestFileSelectorModel_getSelectedFiles(t *testing.T) {
 	}
 
 	// Select some files
-	model.selected["/file1.md"] = true
-	model.selected["/file3.md"] = true
+	model.selected[selectorItem{name: "/file1.md"}] = true
+	model.selected[selectorItem{name: "/file3.md"}] = true
 
 	selected = model.getSelectedFiles()
 	if len(selected) != 2 {
@@ -313,7 +315,7 @@ func TestFileSelectorModel_getSelectedFiles(t *testing.T) {
 
 	// Should not include "All Files" option
 	for _, f := range selected {
-		if f == allFilesOption {
+		if f.name == allFilesOption {
 			t.Error("getSelectedFiles() should not include 'All Files' option")
 		}
 	}
@@ -322,7 +324,7 @@ func TestFileSelectorModel_getSelectedFiles(t *testing.T) {
 func TestFileSelectorModel_GetSelectedFiles(t *testing.T) {
 	files := []string{"/file1.md"}
 	model := NewFileSelectorModel(files)
-	model.selectedFiles = []string{"/file1.md"}
+	model.selectedFiles = []selectorItem{{name: "/file1.md"}}
 
 	result := model.GetSelectedFiles()
 
@@ -335,6 +337,56 @@ func TestFileSelectorModel_GetSelectedFiles(t *testing.T) {
 	}
 }
 
+func TestDeckSelectorModel(t *testing.T) {
+	model := NewDeckSelectorModel(
+		[]store.GroupCount{{Name: "Go", New: 1, Due: 2, Total: 5}, {Name: "Spanish", Due: 0, Total: 3}},
+		[]store.GroupCount{{Name: "concurrency", Due: 1, Total: 2}},
+	)
+	model.Update(tea.WindowSizeMsg{Width: 100, Height: 40})
+
+	view := model.View()
+	if !strings.Contains(view, "1 new · 2 due · 5 cards") || !strings.Contains(view, "#concurrency") {
+		t.Errorf("Expected decks and tags with their counts, got %q", view)
+	}
+
+	// "All Decks" leaves tags alone
+	model.Update(tea.KeyMsg{Type: tea.KeySpace})
+	if !model.selected[selectorItem{name: "Go"}] || !model.selected[selectorItem{name: "Spanish"}] || model.selected[selectorItem{name: "concurrency", tag: true}] {
+		t.Errorf("Expected every deck and no tag selected, got %v", model.selected)
+	}
+	model.cursor = 3
+	model.Update(tea.KeyMsg{Type: tea.KeySpace})
+	if !strings.Contains(model.View(), "Selected: 2 deck(s), 1 tag(s)") {
+		t.Errorf("Expected the selection summary, got %q", model.View())
+	}
+
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	decks, tags := model.GetSelectedDecks(), model.GetSelectedTags()
+	if len(decks) != 2 || decks[0] != "Go" || len(tags) != 1 || tags[0] != "concurrency" {
+		t.Errorf("Unexpected selection: decks %v, tags %v", decks, tags)
+	}
+}
+
+func TestDeckSelectorModel_DeckNamedLikeTag(t *testing.T) {
+	model := NewDeckSelectorModel(
+		[]store.GroupCount{{Name: "#ideas", Due: 1, Total: 4}},
+		[]store.GroupCount{{Name: "ideas", Due: 2, Total: 7}},
+	)
+	model.Update(tea.WindowSizeMsg{Width: 100, Height: 40})
+	if view := model.View(); !strings.Contains(view, "1 due · 4 cards") || !strings.Contains(view, "2 due · 7 cards") {
+		t.Errorf("Expected separate counts for the deck and the tag, got %q", view)
+	}
+
+	// Only the deck is selected, and it stays a deck
+	model.cursor = 1
+	model.Update(tea.KeyMsg{Type: tea.KeySpace})
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	decks, tags := model.GetSelectedDecks(), model.GetSelectedTags()
+	if len(decks) != 1 || decks[0] != "#ideas" || len(tags) != 0 {
+		t.Errorf("Expected only the #ideas deck, got decks %v, tags %v", decks, tags)
+	}
+}
+
 // Helper function
 func contains(s, substr string) bool {
 	return len(s) > 0 && len(substr) > 0 && (s == substr || len(s) >= len(substr) && findSubstring(s, substr))
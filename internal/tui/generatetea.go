@@ -0,0 +1,269 @@
+package tui
+
+import (
+	"catv/internal/tui/keys"
+	"catv/internal/tui/theme"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/progress"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// generateRecentFiles is how many finished files are listed below the running ones
+const generateRecentFiles = 5
+
+// GenerateFileStartedMsg reports that a worker started generating flashcards for a file
+type GenerateFileStartedMsg struct {
+	File string
+}
+
+// GenerateFileDoneMsg reports that a file was processed and its flashcards were saved
+type GenerateFileDoneMsg struct {
+	File  string
+	Cards int   // Flashcards saved for the file
+	Err   error // First error encountered, nil on success
+}
+
+// GenerateCardsMsg previews the flashcards of a file while the model writes them
+type GenerateCardsMsg struct {
+	File   string
+	Cards  int    // Flashcards generated for the file so far
+	Latest string // Question of the latest flashcard, "" to keep the previous one
+}
+
+// GenerateFinishedMsg reports that every file has been processed
+type GenerateFinishedMsg struct{}
+
+type fileState int
+
+const (
+	filePending fileState = iota
+	fileRunning
+	fileDone
+	fileFailed
+)
+
+// generateFile tracks the progress of a single source file
+type generateFile struct {
+	path   string
+	state  fileState
+	cards  int
+	err    error
+	stream int    // Flashcards previewed while the file is running
+	latest string // Question of the latest previewed flashcard
+}
+
+// GenerateModel shows the progress of a generation run across all files
+type GenerateModel struct {
+	files       []*generateFile
+	index       map[string]*generateFile
+	recent      []*generateFile // Finished files, most recent last
+	model       string
+	spinner     spinner.Model
+	progress    progress.Model
+	start       time.Time
+	now         func() time.Time
+	finished    int
+	failed      int
+	cards       int
+	done        bool
+	interrupted bool
+	width       int
+}
+
+// NewGenerateModel creates a progress view for generating flashcards from files with model
+func NewGenerateModel(files []string, model string) *GenerateModel {
+	p := progress.New(progress.WithGradient("#ff00e1ff", "#ff00e1ff"))
+	p.ShowPercentage = true
+	m := &GenerateModel{
+		files:    make([]*generateFile, len(files)),
+		index:    make(map[string]*generateFile, len(files)),
+		model:    model,
+		spinner:  spinner.New(),
+		progress: p,
+		start:    time.Now(),
+		now:      time.Now,
+	}
+	for i, f := range files {
+		m.files[i] = &generateFile{path: f}
+		m.index[f] = m.files[i]
+	}
+	return m
+}
+
+func (m *GenerateModel) Init() tea.Cmd {
+	return m.spinner.Tick
+}
+
+func (m *GenerateModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.progress.Width = max(min(msg.Width, theme.MaxContentWidth)-4, 10)
+	case tea.KeyMsg:
+		if keys.IsQuit(msg.String()) {
+			m.interrupted = true
+			return m, tea.Quit
+		}
+	case spinner.TickMsg:
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+	case progress.FrameMsg:
+		progressModel, cmd := m.progress.Update(msg)
+		m.progress = progressModel.(progress.Model)
+		return m, cmd
+	case GenerateFileStartedMsg:
+		if f, ok := m.index[msg.File]; ok {
+			f.state = fileRunning
+		}
+	case GenerateCardsMsg:
+		if f, ok := m.index[msg.File]; ok && f.state == fileRunning {
+			f.stream = msg.Cards
+			if msg.Latest != "" {
+				f.latest = msg.Latest
+			}
+		}
+	case GenerateFileDoneMsg:
+		f, ok := m.index[msg.File]
+		if !ok || f.state == fileDone || f.state == fileFailed {
+			return m, nil
+		}
+		f.cards = msg.Cards
+		f.err = msg.Err
+		f.state = fileDone
+		if msg.Err != nil {
+			f.state = fileFailed
+			m.failed++
+		}
+		m.cards += msg.Cards
+		m.finished++
+		m.recent = append(m.recent, f)
+		if len(m.recent) > generateRecentFiles {
+			m.recent = m.recent[1:]
+		}
+		return m, m.progress.SetPercent(m.Percent())
+	case GenerateFinishedMsg:
+		m.done = true
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// Percent returns the share of files that have been processed
+func (m *GenerateModel) Percent() float64 {
+	if len(m.files) == 0 {
+		return 1
+	}
+	return float64(m.finished) / float64(len(m.files))
+}
+
+// ETA estimates the remaining time from the average time per processed file
+// It returns 0 until the first file is done
+func (m *GenerateModel) ETA() time.Duration {
+	if m.finished == 0 || m.finished >= len(m.files) {
+		return 0
+	}
+	elapsed := m.now().Sub(m.start)
+	perFile := elapsed / time.Duration(m.finished)
+	return perFile * time.Duration(len(m.files)-m.finished)
+}
+
+// Interrupted reports whether the user quit before every file was processed
+func (m *GenerateModel) Interrupted() bool {
+	return m.interrupted
+}
+
+func (m *GenerateModel) View() string {
+	var s strings.Builder
+
+	if m.done || m.interrupted {
+		return m.summary()
+	}
+
+	s.WriteString(theme.TitleStyle.Render("Generating flashcards with " + m.model))
+	s.WriteString("\n\n")
+	s.WriteString(m.progress.ViewAs(m.Percent()))
+	s.WriteString("\n")
+
+	eta := "estimating…"
+	if d := m.ETA(); d > 0 {
+		eta = formatDuration(d)
+	}
+	// Cards still being written count towards the total as they arrive
+	cards := m.cards
+	for _, f := range m.files {
+		if f.state == fileRunning {
+			cards += f.stream
+		}
+	}
+	s.WriteString(theme.InfoStyle.Render(fmt.Sprintf("Files %d/%d • Cards %d • Failed %d • ETA %s",
+		m.finished, len(m.files), cards, m.failed, eta)))
+	s.WriteString("\n\n")
+
+	for _, f := range m.files {
+		if f.state == fileRunning {
+			fmt.Fprintf(&s, "%s %s%s\n", m.spinner.View(), m.displayPath(f.path), m.renderPreview(f))
+		}
+	}
+	for _, f := range m.recent {
+		s.WriteString(m.renderFinished(f) + "\n")
+	}
+
+	s.WriteString("\n" + theme.HelpStyle.Render("q: Stop"))
+	return s.String()
+}
+
+// summary is the final view left on screen once generation stops
+func (m *GenerateModel) summary() string {
+	elapsed := formatDuration(m.now().Sub(m.start))
+	line := fmt.Sprintf("Generated %d flashcards from %d/%d file(s) in %s", m.cards, m.finished-m.failed, len(m.files), elapsed)
+	if m.interrupted {
+		return theme.ErrorStyle.Render(fmt.Sprintf("Stopped after %d/%d file(s): ", m.finished, len(m.files))) + line + "\n"
+	}
+	if m.failed > 0 {
+		return theme.SuccessStyle.Render(line) + "\n" + theme.ErrorStyle.Render(fmt.Sprintf("%d file(s) failed", m.failed)) + "\n"
+	}
+	return theme.SuccessStyle.Render(line) + "\n"
+}
+
+// renderFinished shows the outcome of a processed file
+func (m *GenerateModel) renderFinished(f *generateFile) string {
+	if f.state == fileFailed {
+		return theme.ErrorStyle.Render("✗ ") + m.displayPath(f.path) + theme.InfoStyle.Render(" "+truncate(f.err.Error(), 40))
+	}
+	return theme.CheckedStyle.Render("✓ ") + m.displayPath(f.path) + theme.InfoStyle.Render(fmt.Sprintf(" %d cards", f.cards))
+}
+
+// renderPreview shows how many flashcards a running file has so far and the
+// latest one, so a model writing nonsense is noticed early
+func (m *GenerateModel) renderPreview(f *generateFile) string {
+	if f.stream == 0 && f.latest == "" {
+		return ""
+	}
+	preview := fmt.Sprintf(" %d cards", f.stream)
+	if f.latest != "" {
+		preview += " · " + truncate(strings.Join(strings.Fields(f.latest), " "), 50)
+	}
+	return theme.InfoStyle.Render(preview)
+}
+
+// displayPath shortens a path to fit on one line next to its status
+func (m *GenerateModel) displayPath(path string) string {
+	width := min(m.width, theme.MaxContentWidth)
+	if width <= 0 {
+		width = theme.MaxContentWidth
+	}
+	name := filepath.Base(path)
+	return lipgloss.NewStyle().MaxWidth(width - 16).Render(name)
+}
+
+// formatDuration renders a duration rounded to the second, e.g. "2m5s"
+func formatDuration(d time.Duration) string {
+	return d.Round(time.Second).String()
+}
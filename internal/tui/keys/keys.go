@@ -24,15 +24,16 @@ const (
 	N        = "n"
 	R        = "r"
 	B        = "b"
+	U        = "u"
 	CtrlC    = "ctrl+c"
 	PageUp   = "pgup"
 	PageDown = "pgdown"
 
 	// Number keys for shortcuts
 	One   = "1"
+	Two   = "2"
 	Three = "3"
-	Seven = "7"
-	Nine  = "9"
+	Four  = "4"
 )
 
 // IsQuit checks if the key is a quit key (q or ctrl+c).
@@ -89,23 +89,23 @@ func CalculateMaxFrameHeight(terminalHeight int) int {
 }
 
 // CalculateTableColumnWidths calculates responsive column widths for admin table.
-// Returns widths for: ID, Question, Answer, and RevisitIn columns.
-func CalculateTableColumnWidths(frameWidth int) (idWidth, questionWidth, answerWidth, revisitInWidth int) {
+// Returns widths for: ID, Question, Answer, and Due columns.
+func CalculateTableColumnWidths(frameWidth int) (idWidth, questionWidth, answerWidth, dueWidth int) {
 	// Account for: frame borders (2), frame padding left+right (4), table padding (2)
 	availableWidth := frameWidth - 8
 
 	// Default widths
 	idWidth = 6
-	revisitInWidth = 12
+	dueWidth = 12
 
 	// Adjust for very narrow screens
 	if availableWidth < 50 {
 		idWidth = 3
-		revisitInWidth = 8
+		dueWidth = 8
 	}
 
 	// Calculate widths for question and answer columns
-	fixedWidth := idWidth + revisitInWidth + 4 // +4 for column spacing
+	fixedWidth := idWidth + dueWidth + 4 // +4 for column spacing
 	remainingWidth := availableWidth - fixedWidth
 	if remainingWidth < 20 {
 		remainingWidth = 20
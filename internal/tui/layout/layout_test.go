@@ -271,12 +271,12 @@ func TestCalculateTableColumnWidths(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			idWidth, questionWidth, answerWidth, revisitInWidth := CalculateTableColumnWidths(tt.frameWidth)
+			idWidth, questionWidth, answerWidth, dueWidth := CalculateTableColumnWidths(tt.frameWidth)
 
 			// All widths should be positive
-			if idWidth <= 0 || questionWidth <= 0 || answerWidth <= 0 || revisitInWidth <= 0 {
+			if idWidth <= 0 || questionWidth <= 0 || answerWidth <= 0 || dueWidth <= 0 {
 				t.Errorf("CalculateTableColumnWidths(%d) returned non-positive width(s): id=%d, q=%d, a=%d, r=%d",
-					tt.frameWidth, idWidth, questionWidth, answerWidth, revisitInWidth)
+					tt.frameWidth, idWidth, questionWidth, answerWidth, dueWidth)
 			}
 
 			// Question width should be larger than answer width (55/45 split)
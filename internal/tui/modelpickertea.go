@@ -0,0 +1,138 @@
+package tui
+
+import (
+	"catv/internal/llm"
+	"catv/internal/tui/keys"
+	"catv/internal/tui/layout"
+	"catv/internal/tui/theme"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ModelPickerModel lets the user choose one of the models installed on the
+// model server when none is configured
+type ModelPickerModel struct {
+	models   []llm.Model
+	cursor   int
+	width    int
+	height   int
+	done     bool
+	selected string // Chosen model, "" if the picker was cancelled
+}
+
+// NewModelPickerModel creates a picker for models, with the cursor on the
+// model called current if it is installed
+func NewModelPickerModel(models []llm.Model, current string) *ModelPickerModel {
+	m := &ModelPickerModel{models: models}
+	for i, model := range models {
+		if llm.HasModel([]llm.Model{model}, current) {
+			m.cursor = i
+			break
+		}
+	}
+	return m
+}
+
+func (m *ModelPickerModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *ModelPickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+	case tea.KeyMsg:
+		switch key := msg.String(); {
+		case keys.IsQuit(key) || key == keys.Esc:
+			m.done = true
+			return m, tea.Quit
+		case keys.IsUp(key):
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case keys.IsDown(key):
+			if m.cursor < len(m.models)-1 {
+				m.cursor++
+			}
+		case key == keys.Enter:
+			m.done = true
+			if len(m.models) > 0 {
+				m.selected = m.models[m.cursor].Name
+			}
+			return m, tea.Quit
+		}
+	}
+	return m, nil
+}
+
+func (m *ModelPickerModel) View() string {
+	if m.done {
+		return ""
+	}
+	width := layout.CalculateContentWidth(m.width)
+
+	var s strings.Builder
+	title := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color(theme.ColorPrimary)).
+		Padding(1, 0).
+		Render("No model is configured 🤖 please pick one to generate with")
+	s.WriteString(lipgloss.NewStyle().Width(width).Align(lipgloss.Center).Render(title))
+	s.WriteString("\n")
+
+	maxVisible := max(m.height-12, 5)
+	scrollOffset := 0
+	if m.cursor >= maxVisible {
+		scrollOffset = m.cursor - maxVisible + 1
+	}
+	for i := scrollOffset; i < len(m.models) && i < scrollOffset+maxVisible; i++ {
+		model := m.models[i]
+		cursor := " "
+		style := theme.UnselectedStyle
+		if i == m.cursor {
+			cursor = theme.CursorStyle.Render("❯")
+			style = theme.SelectedStyle
+		}
+		line := style.Render(model.Name)
+		if details := modelDetails(model); details != "" {
+			line += " " + theme.InfoStyle.Render(details)
+		}
+		fmt.Fprintf(&s, "%s %s\n", cursor, line)
+	}
+	if len(m.models) > maxVisible {
+		s.WriteString(theme.InfoStyle.Render(fmt.Sprintf("\n(Showing %d-%d of %d models)",
+			scrollOffset+1, min(scrollOffset+maxVisible, len(m.models)), len(m.models))))
+		s.WriteString("\n")
+	}
+
+	frame := layout.CreateFrame(width,
+		layout.WithAlignment(lipgloss.Left, lipgloss.Top),
+		layout.WithPadding(1, 2))
+	help := theme.InfoStyle.Render("↑/↓: Navigate • Enter: Use model • q: Quit")
+	return layout.CenterContent(m.width, m.height, frame.Render(s.String())+"\n"+help)
+}
+
+// Selected returns the chosen model, or "" if the picker was cancelled
+func (m *ModelPickerModel) Selected() string {
+	return m.selected
+}
+
+// modelDetails describes the size of a model, e.g. "8.0B · Q4_0 · 4.9 GB"
+func modelDetails(model llm.Model) string {
+	var parts []string
+	if model.ParameterSize != "" {
+		parts = append(parts, model.ParameterSize)
+	}
+	if model.Quantization != "" {
+		parts = append(parts, model.Quantization)
+	}
+	if model.Size > 0 {
+		parts = append(parts, FormatBytes(model.Size))
+	}
+	return strings.Join(parts, " · ")
+}
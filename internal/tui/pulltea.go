@@ -0,0 +1,124 @@
+package tui
+
+import (
+	"catv/internal/llm"
+	"catv/internal/tui/keys"
+	"catv/internal/tui/theme"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/progress"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// PullProgressMsg reports a status update streamed while a model is downloaded
+type PullProgressMsg llm.PullProgress
+
+// PullDoneMsg reports that the download finished, or failed with Err
+type PullDoneMsg struct {
+	Err error
+}
+
+// PullModel shows the download of a model, with a progress bar for the layer
+// being downloaded
+type PullModel struct {
+	name        string
+	status      llm.PullProgress
+	spinner     spinner.Model
+	progress    progress.Model
+	err         error
+	done        bool
+	interrupted bool
+}
+
+// NewPullModel creates a progress view for pulling the model called name
+func NewPullModel(name string) *PullModel {
+	p := progress.New(progress.WithGradient("#ff00e1ff", "#ff00e1ff"))
+	p.ShowPercentage = true
+	return &PullModel{name: name, spinner: spinner.New(), progress: p}
+}
+
+func (m *PullModel) Init() tea.Cmd {
+	return m.spinner.Tick
+}
+
+func (m *PullModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.progress.Width = max(min(msg.Width, theme.MaxContentWidth)-4, 10)
+	case tea.KeyMsg:
+		if keys.IsQuit(msg.String()) {
+			m.interrupted = true
+			return m, tea.Quit
+		}
+	case spinner.TickMsg:
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+	case progress.FrameMsg:
+		progressModel, cmd := m.progress.Update(msg)
+		m.progress = progressModel.(progress.Model)
+		return m, cmd
+	case PullProgressMsg:
+		m.status = llm.PullProgress(msg)
+		return m, m.progress.SetPercent(m.status.Percent())
+	case PullDoneMsg:
+		m.done = true
+		m.err = msg.Err
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// Err returns the error the download failed with, nil on success
+func (m *PullModel) Err() error {
+	return m.err
+}
+
+// Interrupted reports whether the user stopped the download
+func (m *PullModel) Interrupted() bool {
+	return m.interrupted
+}
+
+func (m *PullModel) View() string {
+	switch {
+	case m.interrupted:
+		return theme.ErrorStyle.Render("Stopped pulling "+m.name) + "\n"
+	case m.done && m.err != nil:
+		return ""
+	case m.done:
+		return theme.SuccessStyle.Render("Pulled "+m.name) + "\n"
+	}
+
+	var s strings.Builder
+	s.WriteString(theme.TitleStyle.Render("Pulling " + m.name))
+	s.WriteString("\n\n")
+	status := m.status.Status
+	if status == "" {
+		status = "connecting"
+	}
+	fmt.Fprintf(&s, "%s %s\n", m.spinner.View(), status)
+	if m.status.Total > 0 {
+		s.WriteString(m.progress.ViewAs(m.status.Percent()))
+		s.WriteString("\n")
+		s.WriteString(theme.InfoStyle.Render(FormatBytes(m.status.Completed) + " / " + FormatBytes(m.status.Total)))
+		s.WriteString("\n")
+	}
+	s.WriteString("\n" + theme.HelpStyle.Render("q: Stop"))
+	return s.String()
+}
+
+// FormatBytes renders a size in bytes with a decimal unit, e.g. "4.9 GB"
+func FormatBytes(n int64) string {
+	const unit = 1000
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for q := n / unit; q >= unit; q /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "kMGTPE"[exp])
+}
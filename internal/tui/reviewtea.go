@@ -1,34 +1,48 @@
 package tui
 
 import (
+	"catv/internal/cloze"
+	"catv/internal/grading"
+	"catv/internal/scheduler"
 	"catv/internal/store"
+	"catv/internal/tui/components"
 	"catv/internal/tui/keys"
 	"catv/internal/tui/layout"
 	"catv/internal/tui/theme"
+	"context"
 	"crypto/rand"
 	"encoding/binary"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/progress"
+	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/timer"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
 // ReviewModel manages the state for the review session
-// Views: question, answer, correct/incorrect, revisitIn, done
+// Views: question, answer with grade buttons, done
 
 type viewState int
 
 const (
 	viewQuestion viewState = iota
 	viewAnswer
-	viewRevisitIn
 	viewDone
 	viewTimeout
 )
 
+// gradeKeys maps the number keys to the grade buttons shown with the answer
+var gradeKeys = map[string]scheduler.Grade{
+	keys.One:   scheduler.Again,
+	keys.Two:   scheduler.Hard,
+	keys.Three: scheduler.Good,
+	keys.Four:  scheduler.Easy,
+}
+
 var completionMessages = []string{
 	"The Void retreats… for now 🕳️🐾",
 	"Knowledge absorbed. The Void purrs in approval 😼",
@@ -41,12 +55,15 @@ var completionMessages = []string{
 
 type ReviewModel struct {
 	flashcards    []store.Flashcard
-	current       int
+	scheduler     scheduler.Scheduler
+	current       int // Flashcard being shown
+	upcoming      int // Next flashcard of the session not shown yet
 	view          viewState
-	resultMsg     string
 	quitting      bool
 	correct       []bool
-	revisitIn     []int
+	reviews       []store.Review   // Review logged for each graded flashcard (zero Grade if ungraded)
+	relearned     [][]store.Review // Reviews logged for each flashcard while relearning it
+	responseTime  time.Duration    // Time taken to reveal the current answer
 	width         int
 	height        int
 	progress      progress.Model
@@ -55,29 +72,162 @@ type ReviewModel struct {
 	duration      time.Duration
 	interval      time.Duration // add interval for timer ticks
 	completionMsg string
+	markdown      *components.Markdown // Renders questions and answers with rich content
+
+	// Saving grades as they are given, and undoing them
+	saver   ReviewStore
+	history []gradeUndo // Grades that can be undone, latest last
+	saveErr error       // Last failure to save or undo a grade
+
+	// Relearning of flashcards graded Again
+	steps    []time.Duration // Delays before a failed flashcard is shown again
+	learning []learningCard  // Failed flashcards waiting to be shown again, soonest first
+
+	// Typed answer mode
+	typeAnswer bool
+	input      textinput.Model
+	typed      string            // Answer typed for the current flashcard
+	suggested  scheduler.Grade   // Grade suggested from the typed answer
+	similarity float64           // How closely the typed answer matched, from 0 to 1
+	diff       []grading.Segment // Typed answer against the expected one, nil when blank
+
+	// AI grading of typed answers
+	grader      AnswerGrader
+	judging     bool               // Waiting for the grader
+	explanation string             // Grader's explanation of the suggested grade
+	judgeErr    error              // Grader failure; the fuzzy suggestion is kept
+	judgeCancel context.CancelFunc // Stops the pending grader request
+	judgeID     int                // Latest grader request; verdicts for earlier ones are stale
+}
+
+// ReviewStore saves grades as soon as they are given, so an interrupted
+// session keeps them; *store.Store implements it
+type ReviewStore interface {
+	// RecordReview saves a graded flashcard with its review, returning the review id
+	RecordReview(fc store.Flashcard, r store.Review) (int, error)
+	// InsertReview logs a review made while relearning, returning its id
+	InsertReview(r store.Review) (int, error)
+	// UndoReview restores the flashcard and deletes a review saved by RecordReview
+	UndoReview(previous store.Flashcard, reviewID int) error
+	// DeleteReview deletes a review logged by InsertReview
+	DeleteReview(id int) error
+}
+
+// gradeUndo is the session state from before a grade, to restore on undo
+type gradeUndo struct {
+	index      int             // Flashcard that was graded
+	previous   store.Flashcard // Flashcard before the grade
+	review     store.Review    // Review logged for the flashcard before the grade
+	correct    bool            // Whether the flashcard counted as correct before the grade
+	relearning bool            // Whether the grade was a relearning step
+	reviewID   int             // Saved review to revert (0 if not saved)
+	learning   []learningCard  // Learning queue before the grade
+	upcoming   int             // Next flashcard not shown yet before the grade
+}
+
+// learningCard is a failed flashcard requeued in the session
+type learningCard struct {
+	index int       // Position of the flashcard in the session
+	step  int       // Learning step reached
+	due   time.Time // When the flashcard is shown again
+}
+
+// AnswerGrader judges a typed answer against the expected one, returning a
+// suggested grade with a one-line explanation
+type AnswerGrader func(ctx context.Context, question, expected, typed string) (scheduler.Grade, string, error)
+
+// answerJudgedMsg carries the grader's verdict for the request numbered id
+type answerJudgedMsg struct {
+	id          int
+	grade       scheduler.Grade
+	explanation string
+	err         error
+}
+
+// ReviewOption customizes a review session
+type ReviewOption func(*ReviewModel)
+
+// WithTypedAnswers asks for the answer to be typed before it is revealed, then
+// shows a diff against the stored answer and suggests a grade
+func WithTypedAnswers() ReviewOption {
+	return func(m *ReviewModel) {
+		m.typeAnswer = true
+		m.input = textinput.New()
+		m.input.Placeholder = "Type your answer"
+		m.input.Prompt = "> "
+		m.input.Focus()
+	}
+}
+
+// WithAIGrader types answers as WithTypedAnswers does, then asks grader for
+// the suggested grade once the answer is revealed
+func WithAIGrader(grader AnswerGrader) ReviewOption {
+	return func(m *ReviewModel) {
+		WithTypedAnswers()(m)
+		m.grader = grader
+	}
+}
+
+// WithLearningSteps shows flashcards graded Again again later in the session,
+// after each of the given delays in turn, until they are graded Good or Easy
+// on the last step; Again restarts the steps and Hard repeats the current one
+func WithLearningSteps(steps []time.Duration) ReviewOption {
+	return func(m *ReviewModel) {
+		m.steps = steps
+	}
+}
+
+// WithTimer gives each question d to be answered before the answer is revealed
+func WithTimer(d time.Duration) ReviewOption {
+	return func(m *ReviewModel) {
+		m.duration = d
+		m.timer = timer.NewWithInterval(d, m.interval)
+	}
+}
+
+// WithStore saves each grade to s as soon as it is given, and reverts the
+// saved grade when it is undone
+func WithStore(s ReviewStore) ReviewOption {
+	return func(m *ReviewModel) {
+		m.saver = s
+	}
 }
 
-func NewReviewModel(flashcards []store.Flashcard) *ReviewModel {
+// NewReviewModel creates a review session for the given flashcards, grading
+// them with the provided scheduler
+func NewReviewModel(flashcards []store.Flashcard, sched scheduler.Scheduler, opts ...ReviewOption) *ReviewModel {
 	// Use a custom gradient for the progress bar
 	d := 30 * time.Second
 	interval := 100 * time.Millisecond // smoother animation
 	p := progress.New(progress.WithGradient("#ff00e1ff", "#ff00e1ff"))
 	p.ShowPercentage = false
-	return &ReviewModel{
-		flashcards: flashcards,
+	m := &ReviewModel{
+		// Copy so grading never mutates the caller's slice
+		flashcards: append([]store.Flashcard(nil), flashcards...),
+		scheduler:  sched,
 		current:    0,
+		upcoming:   1,
 		view:       viewQuestion,
 		correct:    make([]bool, len(flashcards)),
-		revisitIn:  make([]int, len(flashcards)),
+		reviews:    make([]store.Review, len(flashcards)),
+		relearned:  make([][]store.Review, len(flashcards)),
 		progress:   p,
 		timer:      timer.NewWithInterval(d, interval),
 		startTime:  time.Now(),
 		duration:   d,
 		interval:   interval,
+		markdown:   components.NewMarkdown(),
+	}
+	for _, opt := range opts {
+		opt(m)
 	}
+	return m
 }
 
 func (m *ReviewModel) Init() tea.Cmd {
+	if m.typeAnswer {
+		return tea.Batch(m.timer.Init(), textinput.Blink)
+	}
 	return m.timer.Init()
 }
 
@@ -89,60 +239,67 @@ func (m *ReviewModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
+		// Leave room for the frame and the input prompt
+		m.input.Width = layout.CalculateContentWidth(msg.Width) - 2 - 2*theme.DefaultPadding - 4
 	case timer.TimeoutMsg:
-		m.view = viewAnswer
+		if m.view == viewQuestion {
+			cmds = append(cmds, m.revealAnswer())
+		}
 		return m, tea.Batch(cmds...)
+	case answerJudgedMsg:
+		// Ignore verdicts for flashcards that were already graded or shown again
+		if msg.id != m.judgeID || m.view != viewAnswer {
+			return m, nil
+		}
+		m.stopJudging()
+		if msg.err != nil {
+			m.judgeErr = msg.err
+			return m, nil
+		}
+		m.suggested, m.explanation = msg.grade, msg.explanation
 	case tea.KeyMsg:
+		if m.typing() {
+			// Every key but Enter and ctrl+c is part of the answer
+			switch msg.String() {
+			case keys.CtrlC:
+				return m, m.quit()
+			case keys.Esc:
+				cmds = append(cmds, m.undo())
+			case keys.Enter:
+				cmds = append(cmds, m.revealAnswer())
+			default:
+				m.input, cmd = m.input.Update(msg)
+				cmds = append(cmds, cmd)
+			}
+			return m, tea.Batch(cmds...)
+		}
 		if msg.String() == keys.Q {
-			m.quitting = true
-			return m, tea.Quit
+			return m, m.quit()
+		}
+		if msg.String() == keys.U {
+			return m, m.undo()
 		}
 		switch m.view {
 		case viewQuestion:
 			if msg.String() == keys.Enter {
-				m.view = viewAnswer
+				cmds = append(cmds, m.revealAnswer())
 			}
 		case viewAnswer:
-			if msg.String() == keys.C {
-				m.correct[m.current] = true
-				m.view = viewRevisitIn
-			} else if msg.String() == keys.I {
-				m.correct[m.current] = false
-				m.resultMsg = "Marked incorrect. Card will not be scheduled for repetition."
-				cmd = m.nextCard()
-				cmds = append(cmds, cmd)
-			}
-		case viewRevisitIn:
-			switch msg.String() {
-			case keys.One:
-				m.revisitIn[m.current] = 1
-				m.resultMsg = "Revisit in 1 day"
-				cmd = m.nextCard()
+			if grade, ok := gradeKeys[msg.String()]; ok {
+				cmd = m.gradeCard(grade)
 				cmds = append(cmds, cmd)
-			case keys.Three:
-				m.revisitIn[m.current] = 3
-				m.resultMsg = "Revisit in 3 days"
-				cmd = m.nextCard()
-				cmds = append(cmds, cmd)
-			case keys.Seven:
-				m.revisitIn[m.current] = 7
-				m.resultMsg = "Revisit in 7 days"
-				cmd = m.nextCard()
-				cmds = append(cmds, cmd)
-			case keys.Nine:
-				m.revisitIn[m.current] = 9
-				m.resultMsg = "Revisit in 9 days"
-				cmd = m.nextCard()
+			} else if m.typeAnswer && !m.judging && msg.String() == keys.Enter {
+				cmd = m.gradeCard(m.suggested)
 				cmds = append(cmds, cmd)
 			}
 		case viewDone:
 			if msg.String() == keys.Q {
-				m.quitting = true
-				return m, tea.Quit
+				return m, m.quit()
 			}
 		}
 	case tea.QuitMsg:
 		m.quitting = true
+		m.stopJudging()
 	case progress.FrameMsg:
 		progressModel, cmd := m.progress.Update(msg)
 		m.progress = progressModel.(progress.Model)
@@ -169,9 +326,244 @@ func (m *ReviewModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, tea.Batch(cmds...)
 }
 
+// quit ends the session, stopping the grader if it is still judging
+func (m *ReviewModel) quit() tea.Cmd {
+	m.quitting = true
+	m.stopJudging()
+	return tea.Quit
+}
+
+// stopJudging cancels the pending grader request, if any
+func (m *ReviewModel) stopJudging() {
+	if m.judgeCancel != nil {
+		m.judgeCancel()
+		m.judgeCancel = nil
+	}
+	m.judging = false
+}
+
+// typing reports whether keys go to the answer input
+func (m *ReviewModel) typing() bool {
+	return m.typeAnswer && m.view == viewQuestion
+}
+
+// revealAnswer shows the answer and records how long it took, capped at the timer duration
+// In typed answer mode it also grades what was typed so far, returning the
+// command that asks the AI grader, if any, for its verdict
+func (m *ReviewModel) revealAnswer() tea.Cmd {
+	m.responseTime = time.Since(m.startTime)
+	if m.responseTime > m.duration {
+		m.responseTime = m.duration
+	}
+	if m.typeAnswer {
+		m.typed = m.input.Value()
+		expected := expectedAnswer(m.flashcards[m.current])
+		m.suggested, m.similarity = grading.Suggest(m.typed, expected)
+		// Diffed once here rather than on every redraw
+		if strings.TrimSpace(m.typed) != "" {
+			m.diff = grading.Diff(m.typed, expected)
+		}
+		m.input.Blur()
+	}
+	m.view = viewAnswer
+	// A blank answer needs no judging
+	if m.grader == nil || strings.TrimSpace(m.typed) == "" {
+		return nil
+	}
+	m.stopJudging()
+	ctx, cancel := context.WithCancel(context.Background())
+	m.judging, m.judgeCancel = true, cancel
+	m.judgeID++
+	id, fc, typed, grader := m.judgeID, m.flashcards[m.current], m.typed, m.grader
+	return func() tea.Msg {
+		grade, explanation, err := grader(ctx, questionText(fc), expectedAnswer(fc), typed)
+		return answerJudgedMsg{id: id, grade: grade, explanation: explanation, err: err}
+	}
+}
+
+// SuggestedGrade returns the grade suggested for the typed answer of the
+// current flashcard, or 0 when answers are not typed or the AI grader has not
+// answered yet
+func (m *ReviewModel) SuggestedGrade() scheduler.Grade {
+	if !m.typeAnswer || m.view != viewAnswer || m.judging {
+		return 0
+	}
+	return m.suggested
+}
+
+// gradeCard schedules the current flashcard with the given grade, logs the
+// review and moves on
+// Flashcards graded Again are requeued when there are learning steps; while
+// relearning, grades only move them through the steps
+func (m *ReviewModel) gradeCard(grade scheduler.Grade) tea.Cmd {
+	now := time.Now()
+	fc := &m.flashcards[m.current]
+	undo := gradeUndo{
+		index:    m.current,
+		previous: *fc,
+		review:   m.reviews[m.current],
+		correct:  m.correct[m.current],
+		learning: append([]learningCard(nil), m.learning...),
+		upcoming: m.upcoming,
+	}
+	if step, ok := m.learningStep(); ok {
+		r := store.Review{
+			FlashcardID:    fc.ID,
+			ReviewedAt:     now,
+			Grade:          int(grade),
+			ResponseTime:   m.responseTime,
+			IntervalBefore: fc.IntervalDays,
+			IntervalAfter:  fc.IntervalDays,
+			Relearning:     true,
+		}
+		undo.relearning = true
+		if m.saver != nil {
+			var err error
+			if r.ID, err = m.saver.InsertReview(r); err != nil {
+				m.saveErr = err
+			}
+		}
+		undo.reviewID = r.ID
+		m.relearned[m.current] = append(m.relearned[m.current], r)
+		m.history = append(m.history, undo)
+		if next, again := m.nextStep(step, grade); again {
+			m.requeue(next, now)
+		} else {
+			m.dropLearning(m.current)
+		}
+		return m.nextCard()
+	}
+
+	intervalBefore := fc.IntervalDays
+	scheduler.Apply(fc, m.scheduler.Next(scheduler.StateOf(*fc), grade, now), now)
+	m.reviews[m.current] = store.Review{
+		FlashcardID:    fc.ID,
+		ReviewedAt:     now,
+		Grade:          int(grade),
+		ResponseTime:   m.responseTime,
+		IntervalBefore: intervalBefore,
+		IntervalAfter:  fc.IntervalDays,
+	}
+	m.correct[m.current] = grade != scheduler.Again
+	if m.saver != nil {
+		var err error
+		if undo.reviewID, err = m.saver.RecordReview(*fc, m.reviews[m.current]); err != nil {
+			m.saveErr = err
+		}
+		m.reviews[m.current].ID = undo.reviewID
+	}
+	m.history = append(m.history, undo)
+	if grade == scheduler.Again && len(m.steps) > 0 {
+		m.requeue(0, now)
+	}
+	return m.nextCard()
+}
+
+// learningStep returns the learning step of the current flashcard, if it is
+// being relearned
+func (m *ReviewModel) learningStep() (int, bool) {
+	for _, lc := range m.learning {
+		if lc.index == m.current {
+			return lc.step, true
+		}
+	}
+	return 0, false
+}
+
+// nextStep returns the learning step a relearned flashcard moves to after
+// grade, or false once it graduates
+func (m *ReviewModel) nextStep(step int, grade scheduler.Grade) (int, bool) {
+	switch grade {
+	case scheduler.Again:
+		return 0, true
+	case scheduler.Hard:
+		return step, true
+	case scheduler.Good:
+		return step + 1, step+1 < len(m.steps)
+	default:
+		return 0, false
+	}
+}
+
+// requeue shows the current flashcard again once the delay of step has passed
+func (m *ReviewModel) requeue(step int, now time.Time) {
+	m.dropLearning(m.current)
+	lc := learningCard{index: m.current, step: step, due: now.Add(m.steps[step])}
+	i := len(m.learning)
+	for i > 0 && m.learning[i-1].due.After(lc.due) {
+		i--
+	}
+	m.learning = append(m.learning[:i], append([]learningCard{lc}, m.learning[i:]...)...)
+}
+
+// dropLearning removes a flashcard from the learning queue
+func (m *ReviewModel) dropLearning(index int) {
+	for i, lc := range m.learning {
+		if lc.index == index {
+			m.learning = append(m.learning[:i], m.learning[i+1:]...)
+			return
+		}
+	}
+}
+
+// nextIndex picks the flashcard to show next: a relearned flashcard whose step
+// has passed, then the next new one, then the relearned flashcard due soonest
+// so the session does not wait for it
+func (m *ReviewModel) nextIndex() (int, bool) {
+	if len(m.learning) > 0 && !m.learning[0].due.After(time.Now()) {
+		return m.learning[0].index, true
+	}
+	if m.upcoming < len(m.flashcards) {
+		m.upcoming++
+		return m.upcoming - 1, true
+	}
+	if len(m.learning) > 0 {
+		return m.learning[0].index, true
+	}
+	return len(m.flashcards), false
+}
+
+// undo reverts the last grade, saved or not, and shows its flashcard again
+func (m *ReviewModel) undo() tea.Cmd {
+	if len(m.history) == 0 {
+		return nil
+	}
+	u := m.history[len(m.history)-1]
+	if m.saver != nil && u.reviewID != 0 {
+		var err error
+		if u.relearning {
+			err = m.saver.DeleteReview(u.reviewID)
+		} else {
+			err = m.saver.UndoReview(u.previous, u.reviewID)
+		}
+		// Keep the grade so the session matches what is saved
+		if err != nil {
+			m.saveErr = err
+			return nil
+		}
+	}
+	m.history = m.history[:len(m.history)-1]
+
+	m.flashcards[u.index] = u.previous
+	if u.relearning {
+		m.relearned[u.index] = m.relearned[u.index][:len(m.relearned[u.index])-1]
+	} else {
+		m.reviews[u.index], m.correct[u.index] = u.review, u.correct
+	}
+	m.learning, m.upcoming = u.learning, u.upcoming
+	return m.showCard(u.index)
+}
+
+// SaveError returns the last error met saving or undoing a grade, if any
+func (m *ReviewModel) SaveError() error {
+	return m.saveErr
+}
+
 func (m *ReviewModel) nextCard() tea.Cmd {
-	m.current++
-	if m.current >= len(m.flashcards) {
+	index, more := m.nextIndex()
+	if !more {
+		m.stopJudging()
+		m.current = index
 		m.view = viewDone
 		b := make([]byte, 4)
 		if _, err := rand.Read(b); err != nil {
@@ -182,9 +574,23 @@ func (m *ReviewModel) nextCard() tea.Cmd {
 		}
 		return nil
 	}
+	return m.showCard(index)
+}
+
+// showCard asks the question of the flashcard at index with a fresh timer
+func (m *ReviewModel) showCard(index int) tea.Cmd {
+	m.current = index
 	m.view = viewQuestion
-	m.resultMsg = ""
-	m.duration = 30 * time.Second
+	m.responseTime = 0
+	if m.typeAnswer {
+		m.stopJudging()
+		m.input.Reset()
+		m.input.Focus()
+		m.typed = ""
+		m.diff = nil
+		m.explanation = ""
+		m.judgeErr = nil
+	}
 	m.startTime = time.Now()
 	m.timer = timer.NewWithInterval(m.duration, m.interval)
 	m.progress = progress.New(progress.WithGradient("#ff00e1ff", "#ff00e1ff"))
@@ -200,11 +606,72 @@ func (m *ReviewModel) FlashcardWasCorrect(idx int) bool {
 	return m.correct[idx]
 }
 
-func (m *ReviewModel) FlashcardRevisitIn(idx int) int {
-	if idx < 0 || idx >= len(m.revisitIn) {
+// FlashcardGrade returns the grade given to a flashcard, or 0 if it was not graded
+func (m *ReviewModel) FlashcardGrade(idx int) scheduler.Grade {
+	if idx < 0 || idx >= len(m.reviews) {
 		return 0
 	}
-	return m.revisitIn[idx]
+	return scheduler.Grade(m.reviews[idx].Grade)
+}
+
+// FlashcardReview returns the review logged when a flashcard was graded
+func (m *ReviewModel) FlashcardReview(idx int) store.Review {
+	if idx < 0 || idx >= len(m.reviews) {
+		return store.Review{}
+	}
+	return m.reviews[idx]
+}
+
+// FlashcardRelearningReviews returns the reviews logged while relearning a
+// flashcard after it was graded Again
+func (m *ReviewModel) FlashcardRelearningReviews(idx int) []store.Review {
+	if idx < 0 || idx >= len(m.relearned) {
+		return nil
+	}
+	return m.relearned[idx]
+}
+
+// Flashcard returns a flashcard with the scheduling applied by its grade
+func (m *ReviewModel) Flashcard(idx int) store.Flashcard {
+	if idx < 0 || idx >= len(m.flashcards) {
+		return store.Flashcard{}
+	}
+	return m.flashcards[idx]
+}
+
+// renderGradeButtons shows each grade with the interval it would schedule, or
+// the delay before a flashcard graded Again or being relearned is shown again
+func (m *ReviewModel) renderGradeButtons() string {
+	fc := m.flashcards[m.current]
+	step, relearning := m.learningStep()
+	intervals := scheduler.Preview(m.scheduler, scheduler.StateOf(fc), time.Now())
+	buttons := make([]string, 0, len(scheduler.Grades))
+	for i, g := range scheduler.Grades {
+		label := scheduler.FormatInterval(intervals[g])
+		switch {
+		case relearning:
+			label = scheduler.FormatInterval(fc.IntervalDays)
+			if next, again := m.nextStep(step, g); again {
+				label = formatStep(m.steps[next])
+			}
+		case g == scheduler.Again && len(m.steps) > 0:
+			label = formatStep(m.steps[0])
+		}
+		buttons = append(buttons, fmt.Sprintf("[%d] %s %s", i+1, g, label))
+	}
+	return strings.Join(buttons, "  ")
+}
+
+// formatStep formats a learning step delay such as "1m", "10m" or "1h"
+func formatStep(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	default:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
 }
 
 func (m *ReviewModel) View() string {
@@ -212,8 +679,9 @@ func (m *ReviewModel) View() string {
 		return "Goodbye!"
 	}
 	total := len(m.flashcards)
-	current := m.current + 1
-	// If review is done, show total instead of current+1
+	// Flashcards shown so far; relearned ones are not counted again
+	current := min(m.upcoming, total)
+	// If review is done, show total
 	if m.view == viewDone {
 		current = total
 	}
@@ -230,7 +698,7 @@ func (m *ReviewModel) View() string {
 	incorrectCount := 0
 	for i := range m.flashcards {
 		// Only count cards that have been answered (i.e., where correct/incorrect has been set)
-		if m.view == viewDone || i < m.current {
+		if m.FlashcardGrade(i) != 0 {
 			if m.FlashcardWasCorrect(i) {
 				correctCount++
 			} else {
@@ -239,26 +707,131 @@ func (m *ReviewModel) View() string {
 		}
 	}
 
-	// Bottom bar: ✅ left, current/total center, ❌ right
+	// Bottom bar: ✅ left, current/total and cards left to relearn center, ❌ right
+	position := fmt.Sprintf("%d/%d", current, total)
+	if len(m.learning) > 0 {
+		position += fmt.Sprintf(" • 🔁 %d", len(m.learning))
+	}
 	bottomBar := layout.CreateBottomBar(width,
 		fmt.Sprintf("✅ %d", correctCount),
-		fmt.Sprintf("%d/%d", current, total),
+		position,
 		fmt.Sprintf("❌ %d", incorrectCount))
 
-	exitMsg := theme.InfoStyle.Render("Enter: Confirm • q: Quit")
+	// Enter reveals the answer, and only accepts a grade for typed answers
+	var hints []string
+	switch {
+	case m.typing():
+		hints = append(hints, "Enter: Submit")
+	case m.view == viewQuestion || m.view == viewAnswer && m.typeAnswer:
+		hints = append(hints, "Enter: Confirm")
+	}
+	switch {
+	case len(m.history) > 0 && m.typing():
+		hints = append(hints, "esc: Undo")
+	case len(m.history) > 0:
+		hints = append(hints, "u: Undo")
+	}
+	if m.typing() {
+		hints = append(hints, "ctrl+c: Quit")
+	} else {
+		hints = append(hints, "q: Quit")
+	}
+	exitMsg := theme.InfoStyle.Render(strings.Join(hints, " • "))
+	if m.saveErr != nil {
+		exitMsg = theme.ErrorStyle.Render("Saving failed: "+truncate(m.saveErr.Error(), 50)) + "\n" + exitMsg
+	}
+	// Text wraps inside the frame's border and padding
+	textWidth := width - 2 - 2*theme.DefaultPadding
 
 	var content string
 	switch m.view {
 	case viewQuestion:
 		// Animated progress bar for countdown
 		progressBar := m.progress.View()
-		content = fmt.Sprintf("%s\n\n%s\n\n%s\n\n%s", theme.QuestionStyle.Render("Question:"), m.flashcards[m.current].Question, progressBar, bottomBar)
+		question := m.markdown.Render(questionText(m.flashcards[m.current]), textWidth)
+		if m.typeAnswer {
+			question += "\n\n" + m.input.View()
+		}
+		content = fmt.Sprintf("%s\n\n%s\n\n%s\n\n%s", theme.QuestionStyle.Render("Question:"), question, progressBar, bottomBar)
 	case viewAnswer:
-		content = fmt.Sprintf("%s\n\n%s\n\n%s\n%s", theme.AnswerStyle.Render("Answer:"), m.flashcards[m.current].Answer, theme.InfoStyle.Render("Was your answer correct? [c]orrect / [i]ncorrect\n"), bottomBar)
-	case viewRevisitIn:
-		content = fmt.Sprintf("%s\n%s\n%s", theme.InfoStyle.Render("\nRevisit in (days): [1]  [3]  [7]  [9]"), m.resultMsg, bottomBar)
+		answer := m.markdown.Render(answerText(m.flashcards[m.current]), textWidth)
+		prompt := theme.InfoStyle.Render("How well did you remember it?")
+		if m.typeAnswer {
+			answer += "\n\n" + theme.LabelStyle.Render("Your answer:") + "\n" + renderDiff(m.diff)
+			prompt = m.renderSuggestion()
+		}
+		content = fmt.Sprintf("%s\n\n%s\n\n%s\n%s\n%s", theme.AnswerStyle.Render("Answer:"), answer, prompt, m.renderGradeButtons(), bottomBar)
 	case viewDone:
 		content = fmt.Sprintf("\n%s\n%s", theme.SuccessStyle.Render(m.completionMsg+"\n"), bottomBar)
 	}
 	return layout.CenterContent(m.width, m.height, frame.Render(content)+"\n"+exitMsg)
 }
+
+// renderSuggestion shows the grade suggested for a typed answer and, with an
+// AI grader, its explanation or why it failed
+func (m *ReviewModel) renderSuggestion() string {
+	fuzzy := fmt.Sprintf("%.0f%% match • Suggested: %s • Enter: Accept", m.similarity*100, m.suggested)
+	switch {
+	case m.grader == nil:
+		return theme.InfoStyle.Render(fuzzy)
+	case m.judging:
+		return theme.InfoStyle.Render("AI grading… • 1-4: Grade now")
+	case m.judgeErr != nil:
+		return theme.ErrorStyle.Render("AI grading failed: "+truncate(m.judgeErr.Error(), 40)) + "\n" + theme.InfoStyle.Render(fuzzy)
+	case m.explanation != "":
+		return theme.LabelStyle.Render("AI: ") + m.explanation + "\n" +
+			theme.InfoStyle.Render(fmt.Sprintf("AI grade: %s • Enter: Accept", m.suggested))
+	}
+	return theme.InfoStyle.Render(fuzzy)
+}
+
+// questionText is what is shown before the answer is revealed: the question,
+// or the cloze text with the tested deletion blanked out
+func questionText(fc store.Flashcard) string {
+	if fc.IsCloze() {
+		return cloze.Question(fc.Question, fc.ClozeIndex)
+	}
+	return fc.Question
+}
+
+// expectedAnswer is what a typed answer is graded against: the answer, or the
+// hidden text of the tested cloze deletion
+func expectedAnswer(fc store.Flashcard) string {
+	if fc.IsCloze() {
+		return cloze.Deleted(fc.Question, fc.ClozeIndex)
+	}
+	return fc.Answer
+}
+
+// renderDiff shows a typed answer with the characters to remove struck through
+// and the missing characters underlined
+func renderDiff(diff []grading.Segment) string {
+	if len(diff) == 0 {
+		return theme.InfoStyle.Render("(no answer)")
+	}
+	var s strings.Builder
+	for _, seg := range diff {
+		switch seg.Op {
+		case grading.Equal:
+			s.WriteString(seg.Text)
+		case grading.Insert:
+			s.WriteString(theme.DiffMissingStyle.Render(seg.Text))
+		case grading.Delete:
+			s.WriteString(theme.DiffExtraStyle.Render(seg.Text))
+		}
+	}
+	return s.String()
+}
+
+// answerText is what is shown once the answer is revealed: the answer, or the
+// cloze text with the tested deletion filled in followed by any extra notes
+func answerText(fc store.Flashcard) string {
+	if !fc.IsCloze() {
+		return fc.Answer
+	}
+	text := cloze.Answer(fc.Question, fc.ClozeIndex)
+	if fc.Answer != "" {
+		text += "\n\n" + fc.Answer
+	}
+	return text
+}
@@ -0,0 +1,218 @@
+package tui
+
+import (
+	"catv/internal/stats"
+	"catv/internal/tui/keys"
+	"catv/internal/tui/layout"
+	"catv/internal/tui/theme"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	statsMaxFiles   = 8  // Source files listed in the cards per file chart
+	statsLabelWidth = 20 // Width of the labels in front of bar chart rows
+	heatmapCell     = "■"
+	barCell         = "█"
+)
+
+// StatsModel displays a read-only statistics dashboard
+type StatsModel struct {
+	summary stats.Summary
+	width   int
+	height  int
+}
+
+// NewStatsModel creates the statistics screen for the given summary
+func NewStatsModel(summary stats.Summary) *StatsModel {
+	return &StatsModel{summary: summary}
+}
+
+func (m *StatsModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *StatsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+	case tea.KeyMsg:
+		if keys.IsQuit(msg.String()) || msg.String() == keys.Esc || msg.String() == keys.Enter {
+			return m, tea.Quit
+		}
+	}
+	return m, nil
+}
+
+func (m *StatsModel) View() string {
+	width := layout.CalculateContentWidth(m.width)
+	// Frame border and padding take 4 columns
+	inner := max(width-4, 30)
+	s := m.summary
+
+	sections := []string{
+		theme.TitleStyle.Render("📊 Statistics"),
+		m.renderOverview(),
+		theme.LabelStyle.Render(fmt.Sprintf("Reviews (last %d days)", len(s.ReviewsPerDay))),
+		renderHeatmap(s.ReviewsPerDay),
+		theme.LabelStyle.Render("Due forecast"),
+		renderBars(forecastRows(s.Forecast), inner, theme.ColorPrimary),
+		theme.LabelStyle.Render("Cards per file"),
+		renderBars(fileRows(s.Files), inner, theme.ColorSuccessAlt),
+	}
+
+	frame := layout.CreateFrame(width)
+	exitMsg := theme.InfoStyle.Render("q: Quit")
+	return layout.CenterContent(m.width, m.height, frame.Render(strings.Join(sections, "\n\n"))+"\n"+exitMsg)
+}
+
+// renderOverview shows the headline numbers in two columns
+func (m *StatsModel) renderOverview() string {
+	s := m.summary
+	left := []string{
+		fmt.Sprintf("Total cards:   %d", s.TotalCards),
+		fmt.Sprintf("New cards:     %d", s.NewCards),
+		fmt.Sprintf("Due today:     %d", s.DueToday),
+		fmt.Sprintf("Due this week: %d", s.DueThisWeek),
+	}
+	right := []string{
+		fmt.Sprintf("Reviews:        %d", s.TotalReviews),
+		fmt.Sprintf("Accuracy:       %.0f%%", s.Accuracy*100),
+		fmt.Sprintf("Current streak: %s", pluralDays(s.CurrentStreak)),
+		fmt.Sprintf("Longest streak: %s", pluralDays(s.LongestStreak)),
+	}
+	column := lipgloss.NewStyle().Width(28)
+	return lipgloss.JoinHorizontal(lipgloss.Top,
+		column.Render(strings.Join(left, "\n")),
+		column.Render(strings.Join(right, "\n")))
+}
+
+// barRow is a labelled value in a bar chart
+type barRow struct {
+	label string
+	value int
+}
+
+// renderBars draws a horizontal bar chart scaled to the largest value
+func renderBars(rows []barRow, width int, color string) string {
+	if len(rows) == 0 {
+		return theme.InfoStyle.Render("Nothing to show yet")
+	}
+	maxValue := 0
+	for _, r := range rows {
+		maxValue = max(maxValue, r.value)
+	}
+	// Leave room for the label and the value after the bar
+	barWidth := max(width-statsLabelWidth-6, 1)
+	labelStyle := lipgloss.NewStyle().Width(statsLabelWidth)
+	barStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(color))
+
+	lines := make([]string, 0, len(rows))
+	for _, r := range rows {
+		n := 0
+		if maxValue > 0 {
+			n = r.value * barWidth / maxValue
+		}
+		if r.value > 0 && n == 0 {
+			n = 1
+		}
+		lines = append(lines, labelStyle.Render(truncate(r.label, statsLabelWidth-1))+
+			barStyle.Render(strings.Repeat(barCell, n))+fmt.Sprintf(" %d", r.value))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderHeatmap draws one column per week and one row per weekday, shading
+// each day by how many reviews were done relative to the busiest day
+func renderHeatmap(days []stats.DayCount) string {
+	if len(days) == 0 {
+		return ""
+	}
+	first, err := time.Parse("2006-01-02", days[0].Date)
+	if err != nil {
+		return ""
+	}
+	offset := int(first.Weekday())
+	weeks := (len(days) + offset + 6) / 7
+
+	maxCount := 0
+	for _, d := range days {
+		maxCount = max(maxCount, d.Count)
+	}
+
+	grid := make([][]string, 7)
+	for w := range grid {
+		grid[w] = make([]string, weeks)
+		for c := range grid[w] {
+			grid[w][c] = " "
+		}
+	}
+	for i, d := range days {
+		pos := i + offset
+		grid[pos%7][pos/7] = heatmapStyle(d.Count, maxCount).Render(heatmapCell)
+	}
+
+	weekdays := []string{"Sun", "", "Tue", "", "Thu", "", "Sat"}
+	lines := make([]string, 7)
+	for w := range grid {
+		lines[w] = fmt.Sprintf("%-4s", weekdays[w]) + strings.Join(grid[w], " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// heatmapStyle picks the heatmap shade for a day's count
+func heatmapStyle(count, maxCount int) lipgloss.Style {
+	level := 0
+	if count > 0 && maxCount > 0 {
+		steps := len(theme.HeatmapColors) - 1
+		level = 1 + (count-1)*steps/maxCount
+		level = min(level, steps)
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(theme.HeatmapColors[level]))
+}
+
+// forecastRows labels each forecast day relative to today
+func forecastRows(forecast []stats.DayCount) []barRow {
+	rows := make([]barRow, 0, len(forecast))
+	for i, d := range forecast {
+		label := d.Date
+		switch i {
+		case 0:
+			label = "Today"
+		case 1:
+			label = "Tomorrow"
+		default:
+			if t, err := time.Parse("2006-01-02", d.Date); err == nil {
+				label = t.Format("Mon Jan 2")
+			}
+		}
+		rows = append(rows, barRow{label: label, value: d.Count})
+	}
+	return rows
+}
+
+// fileRows lists the files with the most cards by base name
+func fileRows(files []stats.FileCount) []barRow {
+	rows := make([]barRow, 0, statsMaxFiles)
+	for i, f := range files {
+		if i == statsMaxFiles {
+			break
+		}
+		rows = append(rows, barRow{label: filepath.Base(f.File), value: f.Cards})
+	}
+	return rows
+}
+
+// pluralDays formats a number of days, e.g. "1 day" or "3 days"
+func pluralDays(n int) string {
+	if n == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", n)
+}
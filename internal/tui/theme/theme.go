@@ -22,6 +22,9 @@ const (
 	ColorCursor      = "212" // Pink - cursor indicator
 )
 
+// HeatmapColors shade activity from none to most active, e.g. reviews per day
+var HeatmapColors = []string{ColorMuted, ColorHighlightBg, ColorPrimary, ColorSuccessAlt}
+
 // Text styles - common text formatting
 var (
 	// TitleStyle is used for screen titles and headings
@@ -95,6 +98,19 @@ var (
 				Padding(0, 1)
 )
 
+// Diff styles - for comparing a typed answer with the stored one
+var (
+	// DiffMissingStyle is used for characters missing from a typed answer
+	DiffMissingStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color(ColorSuccessAlt)).
+				Underline(true)
+
+	// DiffExtraStyle is used for characters typed but not in the answer
+	DiffExtraStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color(ColorError)).
+			Strikethrough(true)
+)
+
 // Checkbox styles - for checkbox elements
 var (
 	// CheckedStyle is used for checked checkboxes
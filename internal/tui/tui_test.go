@@ -1,14 +1,23 @@
 package tui
 
 import (
+	"context"
+	"errors"
 	"strings"
 	"testing"
+	"time"
 
+	"catv/internal/grading"
+	"catv/internal/llm"
+	"catv/internal/scheduler"
+	"catv/internal/stats"
 	"catv/internal/store"
+	"catv/internal/tui/theme"
 
 	"github.com/charmbracelet/bubbles/progress"
 	"github.com/charmbracelet/bubbles/timer"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
 )
 
 func TestTruncate(t *testing.T) {
@@ -48,6 +57,28 @@ func TestTruncate(t *testing.T) {
 	}
 }
 
+func TestFormatDue(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		fc       store.Flashcard
+		expected string
+	}{
+		{name: "never scheduled", fc: store.Flashcard{}, expected: "due"},
+		{name: "overdue", fc: store.Flashcard{DueAt: now.AddDate(0, 0, -3)}, expected: "due"},
+		{name: "tomorrow", fc: store.Flashcard{DueAt: store.DueAfter(now, 1)}, expected: "1 day"},
+		{name: "next week", fc: store.Flashcard{DueAt: store.DueAfter(now, 7)}, expected: "7 days"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatDue(tt.fc, now); got != tt.expected {
+				t.Errorf("formatDue() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
 func TestPrintFunctions(t *testing.T) {
 	// Since Print functions write to stdout, we can't easily test output
 	// But we can ensure they don't panic
@@ -146,8 +177,8 @@ func TestAdminModelUpdate(t *testing.T) {
 	defer s.Close()
 
 	flashcards := []store.Flashcard{
-		{ID: 1, Question: "Q1", Answer: "A1", RevisitIn: 0},
-		{ID: 2, Question: "Q2", Answer: "A2", RevisitIn: 5},
+		{ID: 1, Question: "Q1", Answer: "A1"},
+		{ID: 2, Question: "Q2", Answer: "A2", DueAt: store.DueAfter(time.Now(), 5)},
 	}
 
 	model := NewAdminModel(s, flashcards)
@@ -320,7 +351,7 @@ func TestAdminModelHandleEditView(t *testing.T) {
 	defer s.Close()
 
 	flashcards := []store.Flashcard{
-		{ID: 1, Question: "Q1", Answer: "A1", RevisitIn: 0},
+		{ID: 1, Question: "Q1", Answer: "A1"},
 	}
 	model := NewAdminModel(s, flashcards)
 	model.view = adminEdit
@@ -351,7 +382,7 @@ func TestAdminModelHandleEditView(t *testing.T) {
 	model.loadSelectedIntoForm()
 	model.questionInput.SetValue("Updated Q1")
 	model.answerInput.SetValue("Updated A1")
-	model.revisitInput.SetValue("5")
+	model.dueInput.SetValue("5")
 	enterMsg := tea.KeyMsg{Type: tea.KeyEnter}
 	newModel, _ = model.handleEditView(enterMsg)
 	updatedModel = newModel.(*AdminModel)
@@ -379,7 +410,7 @@ func TestAdminModelHandleDeleteConfirm(t *testing.T) {
 	defer s.Close()
 
 	flashcards := []store.Flashcard{
-		{ID: 1, Question: "Q1", Answer: "A1", RevisitIn: 0},
+		{ID: 1, Question: "Q1", Answer: "A1"},
 	}
 	model := NewAdminModel(s, flashcards)
 	model.view = adminConfirmDelete
@@ -413,7 +444,7 @@ func TestAdminModelHandleDeleteConfirm(t *testing.T) {
 	}
 
 	// Test delete when selected is at end of list
-	fc := store.Flashcard{Question: "Q2", Answer: "A2", RevisitIn: 0, File: "test2.md"}
+	fc := store.Flashcard{Question: "Q2", Answer: "A2", File: "test2.md"}
 	err = s.InsertFlashcard(fc)
 	if err != nil {
 		t.Fatalf("Failed to insert flashcard: %v", err)
@@ -443,7 +474,7 @@ func TestAdminModelHandleBulkResetConfirm(t *testing.T) {
 	defer s.Close()
 
 	flashcards := []store.Flashcard{
-		{ID: 1, Question: "Q1", Answer: "A1", RevisitIn: 5},
+		{ID: 1, Question: "Q1", Answer: "A1", DueAt: store.DueAfter(time.Now(), 5)},
 	}
 	model := NewAdminModel(s, flashcards)
 	model.view = adminConfirmBulkReset
@@ -475,7 +506,7 @@ func TestAdminModelView(t *testing.T) {
 	defer s.Close()
 
 	flashcards := []store.Flashcard{
-		{ID: 1, Question: "Q1", Answer: "A1", RevisitIn: 0},
+		{ID: 1, Question: "Q1", Answer: "A1"},
 	}
 	model := NewAdminModel(s, flashcards)
 	model.width = 80
@@ -514,8 +545,8 @@ func TestAdminModelView(t *testing.T) {
 	// Test bulk reset confirm view
 	model.view = adminConfirmBulkReset
 	view = model.View()
-	if !strings.Contains(view, "RevisitIn") {
-		t.Error("Bulk reset confirm view should contain 'RevisitIn'")
+	if !strings.Contains(view, "due now") {
+		t.Error("Bulk reset confirm view should contain 'due now'")
 	}
 
 	// Test view with error message
@@ -562,7 +593,7 @@ func TestAdminModelHelpers(t *testing.T) {
 	defer s.Close()
 
 	flashcards := []store.Flashcard{
-		{ID: 1, Question: "Q1", Answer: "A1", RevisitIn: 0},
+		{ID: 1, Question: "Q1", Answer: "A1"},
 	}
 	model := NewAdminModel(s, flashcards)
 
@@ -582,61 +613,61 @@ func TestAdminModelHelpers(t *testing.T) {
 		t.Errorf("Question should be 'Q1', got '%s'", model.questionInput.Value())
 	}
 
-	// Test parseRevisitDays
-	model.revisitInput.SetValue("7")
-	days, err := model.parseRevisitDays()
+	// Test parseDueDays
+	model.dueInput.SetValue("7")
+	days, err := model.parseDueDays()
 	if err != nil {
-		t.Errorf("parseRevisitDays() error = %v", err)
+		t.Errorf("parseDueDays() error = %v", err)
 	}
 	if days != 7 {
 		t.Errorf("Expected 7 days, got %d", days)
 	}
 
-	// Test parseRevisitDays with invalid input
-	model.revisitInput.SetValue("invalid")
-	_, err = model.parseRevisitDays()
+	// Test parseDueDays with invalid input
+	model.dueInput.SetValue("invalid")
+	_, err = model.parseDueDays()
 	if err == nil {
-		t.Error("parseRevisitDays() should return error for invalid input")
+		t.Error("parseDueDays() should return error for invalid input")
 	}
 
-	// Test parseRevisitDays with empty input
-	model.revisitInput.SetValue("")
-	_, err = model.parseRevisitDays()
+	// Test parseDueDays with empty input
+	model.dueInput.SetValue("")
+	_, err = model.parseDueDays()
 	if err == nil {
-		t.Error("parseRevisitDays() should return error for empty input")
+		t.Error("parseDueDays() should return error for empty input")
 	}
 
 	// Test cycleFocus - start with question focused
 	model.questionInput.Focus()
 	model.answerInput.Blur()
-	model.revisitInput.Blur()
+	model.dueInput.Blur()
 	model.cycleFocus()
 	if !model.answerInput.Focused() {
 		t.Error("Focus should cycle from question to answer")
 	}
 
-	// Test cycleFocus - from answer to revisit
+	// Test cycleFocus - from answer to due
 	model.answerInput.Focus()
 	model.questionInput.Blur()
-	model.revisitInput.Blur()
+	model.dueInput.Blur()
 	model.cycleFocus()
-	if !model.revisitInput.Focused() {
-		t.Error("Focus should cycle from answer to revisit")
+	if !model.dueInput.Focused() {
+		t.Error("Focus should cycle from answer to due")
 	}
 
-	// Test cycleFocus - from revisit to question
-	model.revisitInput.Focus()
+	// Test cycleFocus - from due to question
+	model.dueInput.Focus()
 	model.questionInput.Blur()
 	model.answerInput.Blur()
 	model.cycleFocus()
 	if !model.questionInput.Focused() {
-		t.Error("Focus should cycle from revisit to question")
+		t.Error("Focus should cycle from due to question")
 	}
 
 	// Test cycleFocus - default case (none focused)
 	model.questionInput.Blur()
 	model.answerInput.Blur()
-	model.revisitInput.Blur()
+	model.dueInput.Blur()
 	model.cycleFocus()
 	if !model.questionInput.Focused() {
 		t.Error("Focus should default to question when none focused")
@@ -645,20 +676,20 @@ func TestAdminModelHelpers(t *testing.T) {
 	// Test updateInputs with question input focused
 	model.questionInput.Focus()
 	model.answerInput.Blur()
-	model.revisitInput.Blur()
+	model.dueInput.Blur()
 	keyMsg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}}
 	model.updateInputs(keyMsg)
 
 	// Test updateInputs with answer input focused
 	model.questionInput.Blur()
 	model.answerInput.Focus()
-	model.revisitInput.Blur()
+	model.dueInput.Blur()
 	model.updateInputs(keyMsg)
 
-	// Test updateInputs with revisit input focused
+	// Test updateInputs with due input focused
 	model.questionInput.Blur()
 	model.answerInput.Blur()
-	model.revisitInput.Focus()
+	model.dueInput.Focus()
 	model.updateInputs(keyMsg)
 }
 
@@ -673,7 +704,7 @@ func TestAdminModelCreateFlashcard(t *testing.T) {
 	model := NewAdminModel(s, []store.Flashcard{})
 	model.questionInput.SetValue("Test Question")
 	model.answerInput.SetValue("Test Answer")
-	model.revisitInput.SetValue("7")
+	model.dueInput.SetValue("7")
 
 	model.createFlashcard()
 
@@ -693,7 +724,7 @@ func TestAdminModelUpdateFlashcard(t *testing.T) {
 	defer s.Close()
 
 	// Insert a flashcard first
-	fc := store.Flashcard{Question: "Q1", Answer: "A1", RevisitIn: 0, File: "test.md"}
+	fc := store.Flashcard{Question: "Q1", Answer: "A1", File: "test.md"}
 	err = s.InsertFlashcard(fc)
 	if err != nil {
 		t.Fatalf("Failed to insert flashcard: %v", err)
@@ -708,7 +739,7 @@ func TestAdminModelUpdateFlashcard(t *testing.T) {
 	model.selected = 0
 	model.questionInput.SetValue("Updated Question")
 	model.answerInput.SetValue("Updated Answer")
-	model.revisitInput.SetValue("10")
+	model.dueInput.SetValue("10")
 
 	model.updateFlashcard()
 
@@ -728,7 +759,7 @@ func TestAdminModelDeleteFlashcard(t *testing.T) {
 	defer s.Close()
 
 	// Insert a flashcard first
-	fc := store.Flashcard{Question: "Q1", Answer: "A1", RevisitIn: 0, File: "test.md"}
+	fc := store.Flashcard{Question: "Q1", Answer: "A1", File: "test.md"}
 	err = s.InsertFlashcard(fc)
 	if err != nil {
 		t.Fatalf("Failed to insert flashcard: %v", err)
@@ -767,7 +798,7 @@ func TestAdminModelReload(t *testing.T) {
 	}
 
 	// Test reload with selected out of bounds (greater than length)
-	fc := store.Flashcard{Question: "Q1", Answer: "A1", RevisitIn: 0, File: "test.md"}
+	fc := store.Flashcard{Question: "Q1", Answer: "A1", File: "test.md"}
 	err = s.InsertFlashcard(fc)
 	if err != nil {
 		t.Fatalf("Failed to insert flashcard: %v", err)
@@ -787,7 +818,7 @@ func TestAdminModelReload(t *testing.T) {
 	}
 }
 
-func TestAdminModelBulkResetRevisitIn(t *testing.T) {
+func TestAdminModelBulkResetDueDates(t *testing.T) {
 	tempDB := t.TempDir() + "/test.db"
 	s, err := store.NewStore(tempDB)
 	if err != nil {
@@ -796,8 +827,8 @@ func TestAdminModelBulkResetRevisitIn(t *testing.T) {
 	defer s.Close()
 
 	// Insert flashcards
-	fc1 := store.Flashcard{Question: "Q1", Answer: "A1", RevisitIn: 5, File: "test1.md"}
-	fc2 := store.Flashcard{Question: "Q2", Answer: "A2", RevisitIn: 10, File: "test2.md"}
+	fc1 := store.Flashcard{Question: "Q1", Answer: "A1", DueAt: store.DueAfter(time.Now(), 5), File: "test1.md"}
+	fc2 := store.Flashcard{Question: "Q2", Answer: "A2", DueAt: store.DueAfter(time.Now(), 10), File: "test2.md"}
 	err = s.InsertFlashcard(fc1)
 	if err != nil {
 		t.Fatalf("Failed to insert flashcard: %v", err)
@@ -813,12 +844,17 @@ func TestAdminModelBulkResetRevisitIn(t *testing.T) {
 	}
 
 	model := NewAdminModel(s, flashcards)
-	model.bulkResetRevisitIn()
+	model.bulkResetDueDates()
 
-	if model.status.Error != "" && model.status.Success == "" {
-		t.Logf("Error message: %s", model.status.Error)
-	} else if model.status.Success != "" {
-		t.Logf("Success message: %s", model.status.Success)
+	if model.status.Error != "" {
+		t.Errorf("Unexpected error: %s", model.status.Error)
+	}
+	due, err := s.GetFlashcardsForReview()
+	if err != nil {
+		t.Fatalf("Failed to get due flashcards: %v", err)
+	}
+	if len(due) != 2 {
+		t.Errorf("Expected 2 due flashcards after bulk reset, got %d", len(due))
 	}
 }
 
@@ -827,7 +863,7 @@ func TestNewReviewModel(t *testing.T) {
 		{ID: 1, Question: "Q1", Answer: "A1"},
 	}
 
-	model := NewReviewModel(flashcards)
+	model := NewReviewModel(flashcards, scheduler.NewSM2())
 
 	if len(model.flashcards) != 1 {
 		t.Errorf("Expected 1 flashcard, got %d", len(model.flashcards))
@@ -843,7 +879,7 @@ func TestReviewModelNextCard(t *testing.T) {
 		{ID: 2, Question: "Q2", Answer: "A2"},
 	}
 
-	model := NewReviewModel(flashcards)
+	model := NewReviewModel(flashcards, scheduler.NewSM2())
 	model.current = 0
 
 	cmd := model.nextCard()
@@ -873,7 +909,7 @@ func TestReviewModelFlashcardWasCorrect(t *testing.T) {
 		{ID: 1, Question: "Q1", Answer: "A1"},
 	}
 
-	model := NewReviewModel(flashcards)
+	model := NewReviewModel(flashcards, scheduler.NewSM2())
 	model.correct[0] = true
 
 	if !model.FlashcardWasCorrect(0) {
@@ -884,20 +920,244 @@ func TestReviewModelFlashcardWasCorrect(t *testing.T) {
 	}
 }
 
-func TestReviewModelFlashcardRevisitIn(t *testing.T) {
+func TestReviewModelFlashcardGrade(t *testing.T) {
 	flashcards := []store.Flashcard{
 		{ID: 1, Question: "Q1", Answer: "A1"},
 	}
 
-	model := NewReviewModel(flashcards)
-	model.revisitIn[0] = 7
+	model := NewReviewModel(flashcards, scheduler.NewSM2())
+	model.reviews[0].Grade = int(scheduler.Good)
 
-	if model.FlashcardRevisitIn(0) != 7 {
-		t.Errorf("Expected 7, got %d", model.FlashcardRevisitIn(0))
+	if model.FlashcardGrade(0) != scheduler.Good {
+		t.Errorf("Expected Good, got %v", model.FlashcardGrade(0))
 	}
-	if model.FlashcardRevisitIn(1) != 0 {
+	if model.FlashcardGrade(1) != 0 {
 		t.Error("Expected 0 for invalid index")
 	}
+	if model.Flashcard(1).ID != 0 {
+		t.Error("Expected empty flashcard for invalid index")
+	}
+}
+
+func TestReviewModelGradeCard(t *testing.T) {
+	flashcards := []store.Flashcard{
+		{ID: 1, Question: "Q1", Answer: "A1"},
+		{ID: 2, Question: "Q2", Answer: "A2"},
+	}
+
+	model := NewReviewModel(flashcards, scheduler.NewSM2())
+	model.gradeCard(scheduler.Good)
+	model.gradeCard(scheduler.Again)
+
+	good := model.Flashcard(0)
+	if good.Repetitions != 1 || good.IntervalDays != 2 {
+		t.Errorf("Expected 1 repetition with a 2 day interval, got %d/%d", good.Repetitions, good.IntervalDays)
+	}
+	if good.IsDue(time.Now()) {
+		t.Error("Flashcard graded Good should not be due anymore")
+	}
+	if !model.FlashcardWasCorrect(0) || model.FlashcardWasCorrect(1) {
+		t.Error("Only the flashcard graded Good should count as correct")
+	}
+	if flashcards[0].Repetitions != 0 {
+		t.Error("Grading should not mutate the caller's flashcards")
+	}
+	if model.view != viewDone {
+		t.Error("View should be viewDone after grading the last flashcard")
+	}
+
+	review := model.FlashcardReview(1)
+	if review.FlashcardID != 2 || review.Grade != int(scheduler.Again) {
+		t.Errorf("Expected Again review for flashcard 2, got %+v", review)
+	}
+	if review.IntervalBefore != 0 || review.IntervalAfter != 1 {
+		t.Errorf("Expected interval 0 -> 1, got %d -> %d", review.IntervalBefore, review.IntervalAfter)
+	}
+}
+
+func TestReviewModelRelearning(t *testing.T) {
+	flashcards := []store.Flashcard{
+		{ID: 1, Question: "Q1", Answer: "A1"},
+		{ID: 2, Question: "Q2", Answer: "A2"},
+		{ID: 3, Question: "Q3", Answer: "A3"},
+	}
+	model := NewReviewModel(flashcards, scheduler.NewSM2(), WithLearningSteps([]time.Duration{time.Minute, 10 * time.Minute}))
+	model.width, model.height = 100, 30
+
+	model.view = viewAnswer
+	if view := model.View(); !strings.Contains(view, "[1] Again 1m") {
+		t.Errorf("Expected Again to show the first learning step, got %q", view)
+	}
+	model.gradeCard(scheduler.Again)
+	if model.current != 1 || len(model.learning) != 1 {
+		t.Fatalf("Expected the failed flashcard to wait in the learning queue, showing %d", model.current)
+	}
+	if view := model.View(); !strings.Contains(view, "2/3 • 🔁 1") {
+		t.Errorf("Expected the bottom bar to show one flashcard to relearn, got %q", view)
+	}
+
+	// Once its step has passed the failed flashcard comes before the next one
+	model.learning[0].due = time.Now().Add(-time.Second)
+	model.gradeCard(scheduler.Good)
+	if model.current != 0 {
+		t.Fatalf("Expected the failed flashcard to be shown again, got %d", model.current)
+	}
+	model.view = viewAnswer
+	if view := model.View(); !strings.Contains(view, "[1] Again 1m") || !strings.Contains(view, "[3] Good 10m") || !strings.Contains(view, "[4] Easy 1d") {
+		t.Errorf("Expected the learning steps on the grade buttons, got %q", view)
+	}
+	model.gradeCard(scheduler.Good)
+
+	// With nothing else left, the relearned flashcard is shown before its step passes
+	if model.current != 2 {
+		t.Fatalf("Expected the last new flashcard, got %d", model.current)
+	}
+	model.gradeCard(scheduler.Good)
+	if model.current != 0 || model.view != viewQuestion {
+		t.Fatalf("Expected the relearned flashcard to be shown early, got %d", model.current)
+	}
+	model.gradeCard(scheduler.Good)
+	if model.view != viewDone || len(model.learning) != 0 {
+		t.Errorf("Expected the session to end once the flashcard graduated, %d left", len(model.learning))
+	}
+
+	// Relearning logs reviews without rescheduling the flashcard again
+	if model.FlashcardGrade(0) != scheduler.Again || model.FlashcardWasCorrect(0) {
+		t.Errorf("Expected the first grade to be kept, got %v", model.FlashcardGrade(0))
+	}
+	relearned := model.FlashcardRelearningReviews(0)
+	if len(relearned) != 2 || relearned[0].FlashcardID != 1 || !relearned[0].Relearning || relearned[1].Grade != int(scheduler.Good) {
+		t.Errorf("Expected two relearning reviews, got %+v", relearned)
+	}
+	if fc := model.Flashcard(0); fc.IntervalDays != 1 || relearned[1].IntervalAfter != 1 {
+		t.Errorf("Expected the Again interval to stay, got %d", fc.IntervalDays)
+	}
+	if model.FlashcardRelearningReviews(1) != nil || model.FlashcardRelearningReviews(5) != nil {
+		t.Error("Expected no relearning reviews for the other flashcards")
+	}
+}
+
+func TestReviewModelSavesAndUndoes(t *testing.T) {
+	s, err := store.NewStore(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer s.Close()
+	for _, q := range []string{"Q1", "Q2"} {
+		if err := s.InsertFlashcard(store.Flashcard{File: "/test.md", Question: q, Answer: "A"}); err != nil {
+			t.Fatalf("InsertFlashcard() error = %v", err)
+		}
+	}
+	flashcards, err := s.GetAllFlashcards()
+	if err != nil {
+		t.Fatalf("GetAllFlashcards() error = %v", err)
+	}
+	reviewsOf := func(id int) []store.Review {
+		t.Helper()
+		reviews, err := s.GetReviewsForFlashcard(id)
+		if err != nil {
+			t.Fatalf("GetReviewsForFlashcard() error = %v", err)
+		}
+		return reviews
+	}
+
+	model := NewReviewModel(flashcards, scheduler.NewSM2(), WithStore(s), WithLearningSteps([]time.Duration{time.Minute}))
+	model.width, model.height = 100, 30
+
+	// Each grade is saved straight away
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'1'}})
+	if reviews := reviewsOf(flashcards[0].ID); len(reviews) != 1 || reviews[0].Grade != int(scheduler.Again) {
+		t.Fatalf("Expected the Again grade to be saved, got %+v", reviews)
+	}
+	if !strings.Contains(model.View(), "u: Undo") {
+		t.Error("Expected the undo key in the help")
+	}
+	model.gradeCard(scheduler.Good)
+	model.gradeCard(scheduler.Good) // Relearning step of the first flashcard
+	if reviews := reviewsOf(flashcards[0].ID); len(reviews) != 2 {
+		t.Fatalf("Expected the relearning review to be saved, got %+v", reviews)
+	}
+
+	// Undo reverts the relearning step, then the grade of the second flashcard
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'u'}})
+	if model.current != 0 || model.view != viewQuestion || len(reviewsOf(flashcards[0].ID)) != 1 || len(model.FlashcardRelearningReviews(0)) != 0 {
+		t.Fatalf("Expected the relearning step to be undone, showing %d", model.current)
+	}
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'u'}})
+	if model.current != 1 || model.FlashcardGrade(1) != 0 || len(reviewsOf(flashcards[1].ID)) != 0 {
+		t.Fatalf("Expected the second grade to be undone, showing %d", model.current)
+	}
+	cards, err := s.GetAllFlashcards()
+	if err != nil {
+		t.Fatalf("GetAllFlashcards() error = %v", err)
+	}
+	for _, fc := range cards {
+		if fc.IsNew() != (fc.ID == flashcards[1].ID) {
+			t.Errorf("Expected only the first flashcard to stay graded, got %+v", fc)
+		}
+	}
+
+	// Regrading after an undo continues the session as before
+	model.gradeCard(scheduler.Easy)
+	if model.current != 0 || model.FlashcardGrade(1) != scheduler.Easy {
+		t.Errorf("Expected the relearned flashcard next, showing %d", model.current)
+	}
+	if model.SaveError() != nil {
+		t.Errorf("Unexpected save error %v", model.SaveError())
+	}
+}
+
+func TestReviewModelUndoFailure(t *testing.T) {
+	model := NewReviewModel([]store.Flashcard{{ID: 7, Question: "Q1", Answer: "A1"}}, scheduler.NewSM2(), WithStore(failingStore{}))
+	model.gradeCard(scheduler.Good)
+	if model.SaveError() == nil || model.FlashcardGrade(0) != scheduler.Good {
+		t.Fatalf("Expected the grade to be kept with a save error, got %v", model.SaveError())
+	}
+
+	// Unsaved grades are undone in the session only
+	model.undo()
+	if model.FlashcardGrade(0) != 0 || model.view != viewQuestion {
+		t.Error("Expected the unsaved grade to be undone")
+	}
+	if model.undo() != nil {
+		t.Error("Expected nothing left to undo")
+	}
+}
+
+// failingStore fails to save any grade
+type failingStore struct{}
+
+func (failingStore) RecordReview(store.Flashcard, store.Review) (int, error) {
+	return 0, errors.New("disk full")
+}
+func (failingStore) InsertReview(store.Review) (int, error) { return 0, errors.New("disk full") }
+func (failingStore) UndoReview(store.Flashcard, int) error  { return errors.New("disk full") }
+func (failingStore) DeleteReview(int) error                 { return errors.New("disk full") }
+
+func TestReviewModelResponseTime(t *testing.T) {
+	flashcards := []store.Flashcard{
+		{ID: 1, Question: "Q1", Answer: "A1"},
+	}
+
+	model := NewReviewModel(flashcards, scheduler.NewSM2())
+	model.startTime = time.Now().Add(-5 * time.Second)
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'3'}})
+
+	if rt := model.FlashcardReview(0).ResponseTime; rt < 5*time.Second || rt > 6*time.Second {
+		t.Errorf("Expected a response time of about 5s, got %v", rt)
+	}
+
+	// A timed out question is logged with the full timer duration
+	model = NewReviewModel(flashcards, scheduler.NewSM2())
+	model.startTime = time.Now().Add(-time.Minute)
+	model.Update(timer.TimeoutMsg{})
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'1'}})
+
+	if rt := model.FlashcardReview(0).ResponseTime; rt != model.duration {
+		t.Errorf("Expected timed out response time %v, got %v", model.duration, rt)
+	}
 }
 
 func TestReviewModelView(t *testing.T) {
@@ -905,7 +1165,7 @@ func TestReviewModelView(t *testing.T) {
 		{ID: 1, Question: "What is Go?", Answer: "A language"},
 	}
 
-	model := NewReviewModel(flashcards)
+	model := NewReviewModel(flashcards, scheduler.NewSM2())
 	model.width = 80
 	model.height = 24
 
@@ -917,19 +1177,16 @@ func TestReviewModelView(t *testing.T) {
 		t.Error("View should contain the question")
 	}
 
-	// Test viewAnswer
+	// Test viewAnswer with grade buttons and projected intervals
 	model.view = viewAnswer
 	view = model.View()
 	if !strings.Contains(view, "Answer:") {
 		t.Error("View should contain 'Answer:'")
 	}
-
-	// Test viewRevisitIn
-	model.view = viewRevisitIn
-	model.resultMsg = "Revisit in 7 days"
-	view = model.View()
-	if !strings.Contains(view, "Revisit in") {
-		t.Error("View should contain 'Revisit in'")
+	for _, button := range []string{"[1] Again 1d", "[2] Hard 1d", "[3] Good 2d", "[4] Easy 4d"} {
+		if !strings.Contains(view, button) {
+			t.Errorf("View should contain grade button %q", button)
+		}
 	}
 
 	// Test viewDone
@@ -948,12 +1205,39 @@ func TestReviewModelView(t *testing.T) {
 	}
 }
 
+func TestReviewModelHelp(t *testing.T) {
+	flashcards := []store.Flashcard{{ID: 1, Question: "What is Go?", Answer: "A language"}}
+	tests := []struct {
+		name string
+		opts []ReviewOption
+		view viewState
+		want string
+	}{
+		{"question", nil, viewQuestion, "Enter: Confirm • q: Quit"},
+		{"answer", nil, viewAnswer, "q: Quit"},
+		{"typing", []ReviewOption{WithTypedAnswers()}, viewQuestion, "Enter: Submit • ctrl+c: Quit"},
+		{"typed answer", []ReviewOption{WithTypedAnswers()}, viewAnswer, "Enter: Confirm • q: Quit"},
+		{"done", nil, viewDone, "q: Quit"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := NewReviewModel(flashcards, scheduler.NewSM2(), tt.opts...)
+			model.width, model.height = 80, 30
+			model.view = tt.view
+			lines := strings.Split(strings.TrimRight(ansi.Strip(model.View()), "\n "), "\n")
+			if help := strings.TrimSpace(lines[len(lines)-1]); help != tt.want {
+				t.Errorf("Help = %q, expected %q", help, tt.want)
+			}
+		})
+	}
+}
+
 func TestReviewModelInit(t *testing.T) {
 	flashcards := []store.Flashcard{
 		{ID: 1, Question: "Q1", Answer: "A1"},
 	}
 
-	model := NewReviewModel(flashcards)
+	model := NewReviewModel(flashcards, scheduler.NewSM2())
 	cmd := model.Init()
 	if cmd == nil {
 		t.Error("Init() should return a command")
@@ -966,7 +1250,7 @@ func TestReviewModelUpdate(t *testing.T) {
 		{ID: 2, Question: "Q2", Answer: "A2"},
 	}
 
-	model := NewReviewModel(flashcards)
+	model := NewReviewModel(flashcards, scheduler.NewSM2())
 	model.width = 80
 	model.height = 24
 
@@ -1002,36 +1286,51 @@ func TestReviewModelUpdate(t *testing.T) {
 		t.Error("View should change to viewAnswer on enter")
 	}
 
-	// Test viewAnswer -> correct
+	// Test viewAnswer -> Good
 	model.view = viewAnswer
-	correctMsg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}}
-	newModel, _ = model.Update(correctMsg)
+	model.current = 0
+	goodMsg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'3'}}
+	newModel, _ = model.Update(goodMsg)
 	updatedModel = newModel.(*ReviewModel)
 	if !updatedModel.correct[0] {
 		t.Error("Flashcard should be marked correct")
 	}
-	if updatedModel.view != viewRevisitIn {
-		t.Error("View should change to viewRevisitIn after correct")
+	if updatedModel.FlashcardGrade(0) != scheduler.Good {
+		t.Errorf("Grade should be Good, got %v", updatedModel.FlashcardGrade(0))
+	}
+	if updatedModel.current != 1 || updatedModel.view != viewQuestion {
+		t.Error("Grading should move to the next question")
 	}
 
-	// Test viewAnswer -> incorrect
+	// Test viewAnswer -> Again
 	model.view = viewAnswer
 	model.current = 0
-	incorrectMsg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'i'}}
-	newModel, _ = model.Update(incorrectMsg)
+	againMsg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'1'}}
+	newModel, _ = model.Update(againMsg)
 	updatedModel = newModel.(*ReviewModel)
 	if updatedModel.correct[0] {
 		t.Error("Flashcard should be marked incorrect")
 	}
 
-	// Test viewRevisitIn -> select days
-	model.view = viewRevisitIn
+	// Test viewAnswer ignores keys that are not grades
+	model.view = viewAnswer
 	model.current = 0
-	day7Msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'7'}}
-	newModel, _ = model.Update(day7Msg)
+	otherMsg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'7'}}
+	newModel, _ = model.Update(otherMsg)
 	updatedModel = newModel.(*ReviewModel)
-	if updatedModel.revisitIn[0] != 7 {
-		t.Errorf("RevisitIn should be 7, got %d", updatedModel.revisitIn[0])
+	if updatedModel.view != viewAnswer {
+		t.Error("View should stay in viewAnswer for non-grade keys")
+	}
+
+	// Test Hard and Easy grades
+	for key, grade := range map[rune]scheduler.Grade{'2': scheduler.Hard, '4': scheduler.Easy} {
+		model.view = viewAnswer
+		model.current = 0
+		newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{key}})
+		updatedModel = newModel.(*ReviewModel)
+		if updatedModel.FlashcardGrade(0) != grade {
+			t.Errorf("Grade should be %v, got %v", grade, updatedModel.FlashcardGrade(0))
+		}
 	}
 
 	// Test viewDone -> quit
@@ -1043,34 +1342,6 @@ func TestReviewModelUpdate(t *testing.T) {
 		t.Error("Model should be quitting from viewDone")
 	}
 
-	// Test viewRevisitIn with different day keys
-	model.view = viewRevisitIn
-	model.current = 0
-	day1Msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'1'}}
-	newModel, _ = model.Update(day1Msg)
-	updatedModel = newModel.(*ReviewModel)
-	if updatedModel.revisitIn[0] != 1 {
-		t.Errorf("RevisitIn should be 1, got %d", updatedModel.revisitIn[0])
-	}
-
-	model.view = viewRevisitIn
-	model.current = 0
-	day3Msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'3'}}
-	newModel, _ = model.Update(day3Msg)
-	updatedModel = newModel.(*ReviewModel)
-	if updatedModel.revisitIn[0] != 3 {
-		t.Errorf("RevisitIn should be 3, got %d", updatedModel.revisitIn[0])
-	}
-
-	model.view = viewRevisitIn
-	model.current = 0
-	day9Msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'9'}}
-	newModel, _ = model.Update(day9Msg)
-	updatedModel = newModel.(*ReviewModel)
-	if updatedModel.revisitIn[0] != 9 {
-		t.Errorf("RevisitIn should be 9, got %d", updatedModel.revisitIn[0])
-	}
-
 	// Test timer timeout
 	model.view = viewQuestion
 	timeoutMsg := timer.TimeoutMsg{}
@@ -1140,3 +1411,425 @@ func min(a, b int) int {
 	}
 	return b
 }
+
+func TestStatsModel(t *testing.T) {
+	now := time.Now()
+	cards := []store.Flashcard{
+		{ID: 1, File: "/notes/golang.md"},
+		{ID: 2, File: "/notes/golang.md", DueAt: store.DueAfter(now, 2), LastReviewedAt: now},
+		{ID: 3, File: "/notes/sql.md", DueAt: store.DueAfter(now, 1), LastReviewedAt: now},
+	}
+	reviews := []store.Review{
+		{FlashcardID: 2, ReviewedAt: now, Grade: 3},
+		{FlashcardID: 3, ReviewedAt: now, Grade: 1},
+	}
+	model := NewStatsModel(stats.Compute(cards, reviews, now, 28))
+	model.Update(tea.WindowSizeMsg{Width: 100, Height: 60})
+
+	view := model.View()
+	for _, want := range []string{"Statistics", "Total cards:   3", "Accuracy:       50%", "Current streak: 1 day", "Tomorrow", "golang.md", "Sat"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("View should contain %q", want)
+		}
+	}
+
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}})
+	if cmd == nil {
+		t.Error("q should quit the stats screen")
+	}
+}
+
+func TestRenderBars(t *testing.T) {
+	out := renderBars([]barRow{{"a", 10}, {"b", 5}, {"c", 0}}, 60, theme.ColorPrimary)
+	lines := strings.Split(out, "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 rows, got %d", len(lines))
+	}
+	full := strings.Count(lines[0], barCell)
+	half := strings.Count(lines[1], barCell)
+	if full != 2*half {
+		t.Errorf("Expected bars scaled to the largest value, got %d and %d cells", full, half)
+	}
+	if strings.Contains(lines[2], barCell) {
+		t.Error("Zero values should have no bar")
+	}
+
+	if !strings.Contains(renderBars(nil, 60, theme.ColorPrimary), "Nothing to show") {
+		t.Error("Empty charts should show a placeholder")
+	}
+}
+
+func TestGenerateModel(t *testing.T) {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	now := start
+	m := NewGenerateModel([]string{"/notes/a.md", "/notes/b.md", "/notes/c.md", "/notes/d.md"}, "llama3")
+	m.start = start
+	m.now = func() time.Time { return now }
+
+	if m.Percent() != 0 || m.ETA() != 0 {
+		t.Errorf("Expected no progress before any file is done, got %v and %v", m.Percent(), m.ETA())
+	}
+
+	m.Update(GenerateFileStartedMsg{File: "/notes/a.md"})
+	m.Update(GenerateFileStartedMsg{File: "/notes/b.md"})
+	view := m.View()
+	for _, want := range []string{"llama3", "a.md", "b.md", "Files 0/4", "estimating"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected view to contain %q, got %q", want, view)
+		}
+	}
+
+	now = start.Add(30 * time.Second)
+	m.Update(GenerateFileDoneMsg{File: "/notes/a.md", Cards: 3})
+	m.Update(GenerateFileDoneMsg{File: "/notes/b.md", Err: errors.New("connection refused")})
+	// Duplicate notifications are ignored
+	m.Update(GenerateFileDoneMsg{File: "/notes/a.md", Cards: 3})
+
+	if m.Percent() != 0.5 {
+		t.Errorf("Expected 50%% progress, got %v", m.Percent())
+	}
+	if m.ETA() != 30*time.Second {
+		t.Errorf("Expected ETA of 30s, got %v", m.ETA())
+	}
+	view = m.View()
+	for _, want := range []string{"Files 2/4", "Cards 3", "Failed 1", "ETA 30s", "connection refused"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected view to contain %q, got %q", want, view)
+		}
+	}
+
+	_, cmd := m.Update(GenerateFinishedMsg{})
+	if cmd == nil {
+		t.Error("Expected quit command when generation finishes")
+	}
+	summary := m.View()
+	if !strings.Contains(summary, "Generated 3 flashcards from 1/4 file(s) in 30s") || !strings.Contains(summary, "1 file(s) failed") {
+		t.Errorf("Unexpected summary: %q", summary)
+	}
+}
+
+func TestGenerateModelPreview(t *testing.T) {
+	m := NewGenerateModel([]string{"/notes/a.md", "/notes/b.md"}, "llama3")
+	m.Update(GenerateFileStartedMsg{File: "/notes/a.md"})
+	m.Update(GenerateFileDoneMsg{File: "/notes/b.md", Cards: 2})
+	m.Update(GenerateCardsMsg{File: "/notes/a.md", Cards: 3, Latest: "What does\ndefer do?"})
+	// A reset keeps the latest question on screen
+	m.Update(GenerateCardsMsg{File: "/notes/a.md", Cards: 3})
+	// Previews of files that are not running are ignored
+	m.Update(GenerateCardsMsg{File: "/notes/b.md", Cards: 9})
+
+	view := m.View()
+	for _, want := range []string{"Cards 5", "a.md 3 cards · What does defer do?"} {
+		if !strings.Contains(ansi.Strip(view), want) {
+			t.Errorf("Expected view to contain %q, got %q", want, view)
+		}
+	}
+
+	m.Update(GenerateFileDoneMsg{File: "/notes/a.md", Cards: 3})
+	if view := m.View(); !strings.Contains(view, "Cards 5") {
+		t.Errorf("Expected the saved cards to replace the preview, got %q", view)
+	}
+}
+
+func TestGenerateModelInterrupt(t *testing.T) {
+	m := NewGenerateModel([]string{"a.md"}, "llama3")
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	if cmd == nil || !m.Interrupted() {
+		t.Error("Expected ctrl+c to stop generation")
+	}
+	if !strings.Contains(m.View(), "Stopped after 0/1 file(s)") {
+		t.Errorf("Unexpected view after interrupt: %q", m.View())
+	}
+}
+
+func TestReviewModelClozeView(t *testing.T) {
+	fc := store.Flashcard{ID: 1, Question: "Go was created at {{c1::Google}} in {{c2::2009}}", Answer: "Announced in November",
+		CardType: store.CardCloze, ClozeIndex: 2}
+	m := NewReviewModel([]store.Flashcard{fc}, scheduler.NewSM2())
+	m.width, m.height = 80, 40
+
+	question := m.View()
+	if !strings.Contains(question, "Google") || strings.Contains(question, "2009") || strings.Contains(question, "{{") {
+		t.Errorf("Expected the second deletion to be blanked, got %q", question)
+	}
+
+	m.revealAnswer()
+	answer := ansi.Strip(m.View())
+	for _, want := range []string{"Google", "2009", "Announced in November"} {
+		if !strings.Contains(answer, want) {
+			t.Errorf("Expected answer view to contain %q, got %q", want, answer)
+		}
+	}
+}
+
+func TestReviewModelTypedAnswer(t *testing.T) {
+	cards := []store.Flashcard{
+		{ID: 1, Question: "Force push safely?", Answer: "git push --force-with-lease"},
+		{ID: 2, Question: "Go was released in {{c1::2009}}", CardType: store.CardCloze, ClozeIndex: 1},
+	}
+	m := NewReviewModel(cards, scheduler.NewSM2(), WithTypedAnswers())
+	m.Update(tea.WindowSizeMsg{Width: 80, Height: 40})
+
+	// q is part of the answer rather than quitting
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	if m.quitting || m.input.Value() != "q" {
+		t.Fatal("Typing q should not quit the session")
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	for _, r := range "git push --force-with-leese" {
+		m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	if !strings.Contains(ansi.Strip(m.View()), "force-with-leese") {
+		t.Error("Expected the typed answer in the question view")
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.view != viewAnswer || m.SuggestedGrade() != scheduler.Hard {
+		t.Fatalf("Expected the answer view with a Hard suggestion, got view %v and %s", m.view, m.SuggestedGrade())
+	}
+	if view := ansi.Strip(m.View()); !strings.Contains(view, "Suggested: Hard") || !strings.Contains(view, "Your answer:") {
+		t.Errorf("Expected the suggestion and diff in the answer view, got %q", view)
+	}
+	if len(m.diff) == 0 {
+		t.Error("Expected the diff to be computed once the answer is revealed")
+	}
+
+	// Enter accepts the suggestion
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.FlashcardGrade(0) != scheduler.Hard {
+		t.Errorf("Expected the suggested grade to be applied, got %s", m.FlashcardGrade(0))
+	}
+	if m.diff != nil {
+		t.Error("Expected the diff to be cleared for the next flashcard")
+	}
+
+	// Cloze cards are graded against the hidden text, and a number overrides the suggestion
+	if m.input.Value() != "" {
+		t.Error("Expected the input to be cleared for the next flashcard")
+	}
+	for _, r := range "2009" {
+		m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.SuggestedGrade() != scheduler.Good {
+		t.Errorf("Expected a Good suggestion for the cloze, got %s", m.SuggestedGrade())
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("4")})
+	if m.FlashcardGrade(1) != scheduler.Easy {
+		t.Errorf("Expected the override to be applied, got %s", m.FlashcardGrade(1))
+	}
+}
+
+func TestReviewModelAIGrader(t *testing.T) {
+	cards := []store.Flashcard{
+		{ID: 1, Question: "Force push safely?", Answer: "git push --force-with-lease"},
+		{ID: 2, Question: "List hidden files?", Answer: "ls -a"},
+	}
+	grader := func(_ context.Context, question, expected, typed string) (scheduler.Grade, string, error) {
+		if question == "List hidden files?" {
+			return 0, "", errors.New("connection refused")
+		}
+		if expected != "git push --force-with-lease" || typed != "push with lease" {
+			t.Errorf("Unexpected grader input %q, %q", expected, typed)
+		}
+		return scheduler.Good, "Same command, described in words.", nil
+	}
+	m := NewReviewModel(cards, scheduler.NewSM2(), WithAIGrader(grader))
+	m.Update(tea.WindowSizeMsg{Width: 80, Height: 40})
+
+	for _, r := range "push with lease" {
+		m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil || !strings.Contains(ansi.Strip(m.View()), "AI grading") {
+		t.Fatal("Expected the grader to be asked once the answer is revealed")
+	}
+	// Enter waits for the grader
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.FlashcardGrade(0) != 0 {
+		t.Fatal("Enter should not grade before the grader answers")
+	}
+	m.Update(findMsg[answerJudgedMsg](t, cmd))
+	if m.SuggestedGrade() != scheduler.Good {
+		t.Errorf("Expected the AI grade to be suggested, got %s", m.SuggestedGrade())
+	}
+	if view := ansi.Strip(m.View()); !strings.Contains(view, "Same command, described in words.") || !strings.Contains(view, "AI grade: Good") {
+		t.Errorf("Expected the explanation in the answer view, got %q", view)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.FlashcardGrade(0) != scheduler.Good {
+		t.Errorf("Expected the AI grade to be applied, got %s", m.FlashcardGrade(0))
+	}
+
+	// A failing grader falls back to the fuzzy suggestion
+	for _, r := range "ls -a" {
+		m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m.Update(findMsg[answerJudgedMsg](t, cmd))
+	if view := ansi.Strip(m.View()); !strings.Contains(view, "AI grading failed") || !strings.Contains(view, "Suggested: Good") {
+		t.Errorf("Expected the failure and fuzzy suggestion, got %q", view)
+	}
+
+	// Verdicts arriving after the flashcard was graded are ignored
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("1")})
+	m.Update(answerJudgedMsg{id: m.judgeID, grade: scheduler.Easy})
+	if m.FlashcardGrade(1) != scheduler.Again || m.view != viewDone {
+		t.Errorf("Expected the override to stand, got %s", m.FlashcardGrade(1))
+	}
+}
+
+func TestReviewModelAIGraderCancelled(t *testing.T) {
+	cards := []store.Flashcard{
+		{ID: 1, Question: "Force push safely?", Answer: "git push --force-with-lease"},
+		{ID: 2, Question: "List hidden files?", Answer: "ls -a"},
+	}
+	// The grader answers only once its request is cancelled
+	grader := func(ctx context.Context, question, expected, typed string) (scheduler.Grade, string, error) {
+		<-ctx.Done()
+		return scheduler.Easy, "", ctx.Err()
+	}
+	judge := func(m *ReviewModel) <-chan answerJudgedMsg {
+		t.Helper()
+		m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("push")})
+		_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		if cmd == nil {
+			t.Fatal("Expected the grader to be asked")
+		}
+		verdict := make(chan answerJudgedMsg, 1)
+		go func() { verdict <- cmd().(answerJudgedMsg) }()
+		return verdict
+	}
+	wait := func(verdict <-chan answerJudgedMsg) answerJudgedMsg {
+		t.Helper()
+		select {
+		case msg := <-verdict:
+			return msg
+		case <-time.After(time.Second):
+			t.Fatal("The grader request was not cancelled")
+		}
+		return answerJudgedMsg{}
+	}
+
+	// Grading moves on to the next flashcard and cancels the request
+	m := NewReviewModel(cards, scheduler.NewSM2(), WithAIGrader(grader))
+	verdict := judge(m)
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("3")})
+	msg := wait(verdict)
+	if !errors.Is(msg.err, context.Canceled) {
+		t.Errorf("Expected the request to be cancelled, got %v", msg.err)
+	}
+
+	// A stale verdict is dropped even when its flashcard is shown again
+	verdict = judge(m)
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+	msg = wait(verdict)
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("3")})
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("ls -a")})
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.current != 1 {
+		t.Fatalf("Expected the second flashcard again, got %d", m.current)
+	}
+	m.Update(msg)
+	if !m.judging || m.judgeErr != nil {
+		t.Errorf("Expected the stale verdict to be ignored, got judging=%v err=%v", m.judging, m.judgeErr)
+	}
+
+	// Quitting cancels the pending request
+	m = NewReviewModel(cards, scheduler.NewSM2(), WithAIGrader(grader))
+	verdict = judge(m)
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	if msg := wait(verdict); !errors.Is(msg.err, context.Canceled) {
+		t.Errorf("Expected the request to be cancelled on quit, got %v", msg.err)
+	}
+}
+
+// findMsg runs cmd, unpacking batches, and returns the first message of type T
+func findMsg[T tea.Msg](t *testing.T, cmd tea.Cmd) T {
+	t.Helper()
+	var queue []tea.Cmd
+	if cmd != nil {
+		queue = append(queue, cmd)
+	}
+	for len(queue) > 0 {
+		c := queue[0]
+		queue = queue[1:]
+		switch msg := c().(type) {
+		case T:
+			return msg
+		case tea.BatchMsg:
+			for _, sub := range msg {
+				if sub != nil {
+					queue = append(queue, sub)
+				}
+			}
+		}
+	}
+	var zero T
+	t.Fatalf("No %T produced", zero)
+	return zero
+}
+
+func TestRenderDiff(t *testing.T) {
+	if got := ansi.Strip(renderDiff(grading.Diff("ls -la", "ls -al"))); !strings.Contains(got, "ls -") {
+		t.Errorf("Unexpected diff %q", got)
+	}
+	if got := ansi.Strip(renderDiff(nil)); got != "(no answer)" {
+		t.Errorf("Expected a placeholder for empty answers, got %q", got)
+	}
+}
+
+func TestModelPickerModel(t *testing.T) {
+	models := []llm.Model{{Name: "llama3.1:latest", ParameterSize: "8.0B", Size: 4920753328}, {Name: "qwen2.5:7b"}}
+	m := NewModelPickerModel(models, "qwen2.5:7b")
+	if m.cursor != 1 {
+		t.Errorf("Expected the cursor on the current model, got %d", m.cursor)
+	}
+	if view := m.View(); !strings.Contains(view, "llama3.1:latest") || !strings.Contains(view, "8.0B · 4.9 GB") {
+		t.Errorf("Unexpected view: %q", view)
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyUp})
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil || m.Selected() != "llama3.1:latest" {
+		t.Errorf("Expected llama3.1:latest to be chosen, got %q", m.Selected())
+	}
+
+	m = NewModelPickerModel(models, "")
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.Selected() != "" {
+		t.Errorf("Expected no model after cancelling, got %q", m.Selected())
+	}
+}
+
+func TestPullModel(t *testing.T) {
+	m := NewPullModel("qwen2.5:7b")
+	m.Update(PullProgressMsg{Status: "pulling abc", Total: 2_000_000_000, Completed: 500_000_000})
+	view := m.View()
+	for _, want := range []string{"Pulling qwen2.5:7b", "pulling abc", "500.0 MB / 2.0 GB"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected view to contain %q, got %q", want, view)
+		}
+	}
+
+	_, cmd := m.Update(PullDoneMsg{})
+	if cmd == nil || m.Err() != nil || !strings.Contains(m.View(), "Pulled qwen2.5:7b") {
+		t.Errorf("Unexpected state after the pull finished: %q", m.View())
+	}
+
+	m = NewPullModel("missing")
+	m.Update(PullDoneMsg{Err: errors.New("file does not exist")})
+	if m.Err() == nil {
+		t.Error("Expected the pull error to be kept")
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := map[int64]string{0: "0 B", 999: "999 B", 1500: "1.5 kB", 4920753328: "4.9 GB"}
+	for n, want := range tests {
+		if got := FormatBytes(n); got != want {
+			t.Errorf("FormatBytes(%d) = %q, expected %q", n, got, want)
+		}
+	}
+}